@@ -1,34 +1,40 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
 )
 
-// BootstrapMigration represents a single migration rule
-type BootstrapMigration struct {
-	Name        string
-	Pattern     *regexp.Regexp
-	Replacement string
-	Description string
-}
-
 var (
-	dryRun bool
+	dryRun            bool
+	twigInterpolation bool
+	processScripts    bool
+	patchOutFile      string
+	interactiveReview bool
+	reportFile        string
 )
 
 var bs4to5Cmd = &cobra.Command{
 	Use:   "bs-4-to-5 [PATH]",
-	Short: "Migrate Bootstrap 4 to Bootstrap 5 in HTML and Twig files",
-	Long: `Migrate Bootstrap 4 to Bootstrap 5 classes and components in HTML and Twig files.
+	Short: "Migrate Bootstrap 4 to Bootstrap 5 in templates, SCSS and JavaScript",
+	Long: `Migrate Bootstrap 4 to Bootstrap 5 classes and components in HTML and Twig
+templates, SCSS stylesheets and JavaScript and TypeScript sources.
 
-This command scans HTML (.html) and Twig (.html.twig, .twig) files in the specified
-directory and updates Bootstrap 4 classes to their Bootstrap 5 equivalents.
+This command scans HTML (.html), Twig (.html.twig, .twig), SCSS (.scss), JavaScript
+(.js) and TypeScript (.ts) files in the specified directory and updates Bootstrap 4
+classes, variables, mixins and data attributes to their Bootstrap 5 equivalents.
+Each file type has its own rule set and TODO comment syntax, run
+"wswcli bs-4-to-5 file-types" to list them.
 
 The migration includes:
 - Form component updates (custom-control to form-check, etc.)
@@ -37,20 +43,66 @@ The migration includes:
 - Color and badge class updates
 - Component-specific migrations
 
+Class rules only rewrite whole tokens of class lists: class="..." and class='...'
+attributes, strings of Twig {% set ...class... = '...' %} tags and class: hash keys,
+and arguments of addClass/removeClass/toggleClass and classList calls. Text,
+inline styles, scripts and Twig expressions are left alone. Use --twig-interpolation
+to also rewrite string literals inside {{ }} in class attributes, e.g.
+class="{{ active ? 'text-left' : 'text-right' }}".
+
+This command runs the bootstrap-4-to-5 pack of "wswcli migrate".
+
+Rules can be added, overridden and disabled with YAML or JSON rule files (--rules)
+and selected with --only <category> and --skip <name>. The same settings can be
+made in the [bs-4-to-5] section of .wswcli. Run "wswcli bs-4-to-5 rules" to list
+the effective rule set.
+
+--check changes no files. It lists every occurrence the migration would change,
+plus Bootstrap 4 classes that were removed without a replacement, as
+file:line:column and fails with exit code 1 when there are more than
+--max-findings (default 0). Use it in CI to keep new Bootstrap 4 markup out
+while the migration is in progress.
+
+Every run without --dry-run writes an undo journal to .wswcli/migrations/,
+"wswcli bs-4-to-5 undo" restores the files of the latest run.
+
+Inside a git work tree, files with uncommitted changes are not migrated unless
+--allow-dirty is given, so manual edits are never mixed with the rewrites.
+--tracked-only skips files git does not track. After the migration a summary
+like "git diff --stat" is printed.
+
 Examples:
   wswcli bs-4-to-5 .                    # Migrate current directory (recursive)
   wswcli bs-4-to-5 /path/to/templates   # Migrate specific directory (recursive)
-  wswcli bs-4-to-5 . --dry-run          # Preview changes without applying`,
-	Args: cobra.MaximumNArgs(1),
+  wswcli bs-4-to-5 . --dry-run          # Preview changes as a unified diff
+  wswcli bs-4-to-5 . --dry-run --patch-out bs5.patch  # Save the changes as a patch
+  wswcli bs-4-to-5 . --interactive      # Review each change before applying it
+  wswcli bs-4-to-5 . --report bs5-report.html  # Write per-file and per-rule statistics
+  wswcli bs-4-to-5 . --check            # List remaining Bootstrap 4 usage, fail if there is any
+  wswcli bs-4-to-5 . --check --max-findings 120 --report test-reports/bs5.xml
+  wswcli bs-4-to-5 . --tracked-only     # Only migrate files tracked by git
+  wswcli bs-4-to-5 . --twig-interpolation  # Also migrate classes inside {{ }}
+  wswcli bs-4-to-5 . --only forms --skip "Custom File"
+  wswcli bs-4-to-5 . --rules team-rules.yaml`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
 	RunE: runBS4to5Migration,
 }
 
 func init() {
 	rootCmd.AddCommand(bs4to5Cmd)
-	bs4to5Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without applying them")
+	addMigrationFlags(bs4to5Cmd)
 }
 
 func runBS4to5Migration(cmd *cobra.Command, args []string) error {
+	pack, err := migrate.FindPack(migrate.BootstrapPackName)
+	if err != nil {
+		return err
+	}
+	return runMigration(cmd, args, pack)
+}
+
+// runMigration runs the rules of pack on the files below the path in args
+func runMigration(cmd *cobra.Command, args []string, pack migrate.Pack) error {
 	// Determine project path
 	projectPath := "."
 	if len(args) > 0 {
@@ -59,485 +111,254 @@ func runBS4to5Migration(cmd *cobra.Command, args []string) error {
 
 	// Validate project path
 	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-		return fmt.Errorf("path does not exist: %s", projectPath)
+		return usageError("path does not exist: %s", projectPath)
+	}
+	if reportFile != "" {
+		if err := checkReportFormat(reportFile); err != nil {
+			return err
+		}
+	}
+	if checkOnly && (interactiveReview || patchOutFile != "") {
+		return usageError("--check cannot be combined with --interactive or --patch-out")
+	}
+	if maxFindings < 0 {
+		return usageError("--max-findings must not be negative")
 	}
 
-	if dryRun {
-		fmt.Printf("DRY RUN: Previewing Bootstrap 4 to 5 migration in: %s\n", projectPath)
-	} else {
-		fmt.Printf("Migrating Bootstrap 4 to 5 in: %s\n", projectPath)
+	switch {
+	case checkOnly:
+		logInfo("Checking %s migration in: %s", pack.Title, projectPath)
+	case dryRun:
+		logInfo("DRY RUN: Previewing %s migration in: %s", pack.Title, projectPath)
+	default:
+		logInfo("Migrating %s in: %s", pack.Title, projectPath)
 	}
 
 	// Find all relevant files (always recursive)
-	files, err := findTemplateFiles(projectPath, true)
+	files, err := migrate.FindFiles(commandContext(cmd), projectPath, true, logger)
 	if err != nil {
-		return fmt.Errorf("error finding template files: %w", err)
+		return fmt.Errorf("error finding files: %w", err)
+	}
+	files, err = applyGitSafety(projectPath, files, !dryRun && !checkOnly)
+	if err != nil {
+		return err
 	}
 
 	if len(files) == 0 {
-		fmt.Println("No HTML or Twig files found in the specified directory.")
+		logInfo("No template, SCSS or JavaScript files found in the specified directory.")
 		return nil
 	}
 
-	fmt.Printf("Found %d template files\n", len(files))
+	logInfo("Found %d files", len(files), slog.Int("files", len(files)))
 
 	// Initialize migration rules
-	migrations := getBootstrapMigrations()
+	selection, err := resolveRuleSelection(cmd, pack)
+	if err != nil {
+		return err
+	}
+	ruleSet, err := migrate.LoadRules(selection)
+	if err != nil {
+		return err
+	}
+	migrations := ruleSet.Rules
+	if checkOnly {
+		return runMigrationCheck(commandContext(cmd), projectPath, pack, files, migrations)
+	}
+
+	report := migrate.NewReport(projectPath, pack.Name, dryRun, migrations)
+	journal := migrate.NewJournal(projectPath, pack.Name)
 
 	// Process each file
 	totalChanges := 0
+	var stats []unidiff.Stat
+	color := dryRun && patchOutFile == "" && colorEnabled(os.Stdout)
+	var patch strings.Builder
+	var reviewer *interactiveReviewer
+	if interactiveReview {
+		reviewer, err = newInteractiveReviewer(projectPath, pack.Name, migrations, bufio.NewReader(os.Stdin), os.Stdout)
+		if err != nil {
+			return err
+		}
+	}
+
 	for _, file := range files {
-		changes, err := processFile(file, migrations)
+		logDebug("Processing %s", file)
+		var decide migrate.Decider
+		if reviewer != nil {
+			if !reviewer.StartFile(file) {
+				continue
+			}
+			decide = reviewer.Decide
+		}
+
+		result, err := migrateFile(commandContext(cmd), file, migrations, decide)
 		if err != nil {
-			fmt.Printf("Error processing %s: %v\n", file, err)
+			logError("Error processing %s: %v", file, err, slog.String("file", file), slog.String("error", err.Error()))
+			report.AddError(file, err)
+			continue
+		}
+		if reviewer != nil && reviewer.Quit() {
+			break
+		}
+		if len(result.Matches) > 0 && !dryRun {
+			if err := journal.Record(result, patchPath(projectPath, result.File)); err != nil {
+				return err
+			}
+		}
+		if err := writeMigratedFile(result); err != nil {
+			logError("Error processing %s: %v", file, err, slog.String("file", file), slog.String("error", err.Error()))
+			report.AddError(file, err)
+			if err := journal.Forget(); err != nil {
+				return err
+			}
 			continue
 		}
+		report.AddFile(result)
+		if reviewer != nil {
+			if err := reviewer.FinishFile(result); err != nil {
+				return err
+			}
+		}
+
+		changes := len(result.Matches)
 		totalChanges += changes
-		if changes > 0 {
-			fmt.Printf("%s: %d changes\n", file, changes)
+		if changes == 0 {
+			continue
+		}
+		logInfo("%s: %d changes", file, changes, slog.String("file", file), slog.Int("changes", changes))
+
+		path := patchPath(projectPath, file)
+		stats = append(stats, unidiff.NewStat(path, result.Original, result.Migrated))
+		diff := unidiff.Unified("a/"+path, "b/"+path, result.Original, result.Migrated, 3)
+		switch {
+		case patchOutFile != "":
+			patch.WriteString(diff)
+		case reportToStdout():
+			// The --format output is the data on stdout
+		case dryRun && color:
+			fmt.Print(colorizeDiff(diff))
+		case dryRun:
+			fmt.Print(diff)
 		}
 	}
 
-	if dryRun {
-		fmt.Printf("\nDRY RUN COMPLETE: Would make %d changes across %d files\n", totalChanges, len(files))
-		fmt.Println("Run without --dry-run to apply changes")
-	} else {
-		fmt.Printf("\nMIGRATION COMPLETE: Made %d changes across %d files\n", totalChanges, len(files))
+	if reviewer != nil {
+		if reviewer.Quit() {
+			logInfo("Review interrupted. Decisions saved to %s, run the same command again to resume.", reviewer.SessionPath())
+		} else if err := reviewer.Close(); err != nil {
+			return err
+		}
 	}
 
-	return nil
-}
-
-// findTemplateFiles recursively finds all HTML and Twig files
-func findTemplateFiles(rootPath string, recursive bool) ([]string, error) {
-	var files []string
+	if patchOutFile != "" {
+		if err := os.WriteFile(patchOutFile, []byte(patch.String()), 0644); err != nil {
+			return fmt.Errorf("error writing patch file: %w", err)
+		}
+		if dryRun {
+			logInfo("Patch saved to: %s (apply with: git apply %s)", patchOutFile, patchOutFile, slog.String("path", patchOutFile))
+		} else {
+			logInfo("Change log saved to: %s", patchOutFile, slog.String("path", patchOutFile))
+		}
+	}
 
-	walkFunc := func(path string, info os.FileInfo, err error) error {
-		if err != nil {
+	if reportFile != "" {
+		if err := saveMigrationReport(report, reportFile); err != nil {
 			return err
 		}
-
-		// Skip directories we don't want to process
-		if info.IsDir() {
-			name := info.Name()
-			// Skip if not recursive and it's not the root path
-			if !recursive && path != rootPath {
-				return filepath.SkipDir
-			}
-			// Skip common directories
-			if strings.HasPrefix(name, ".") ||
-				name == "node_modules" ||
-				name == "vendor" ||
-				name == "var" ||
-				name == "cache" ||
-				name == "build" {
-				return filepath.SkipDir
-			}
-			return nil
+		logInfo("Report saved to: %s", reportFile, slog.String("path", reportFile))
+	}
+	if outputFormat != "" {
+		if err := writeReport(outputFormat, migrationResult(report)); err != nil {
+			return err
 		}
+	}
 
-		// Check for HTML and Twig files
-		lowerPath := strings.ToLower(path)
-		if strings.HasSuffix(lowerPath, ".html") ||
-			strings.HasSuffix(lowerPath, ".html.twig") ||
-			strings.HasSuffix(lowerPath, ".twig") {
-			files = append(files, path)
+	if journal.Dir() != "" {
+		undo := undoCommand(cmd).CommandPath() + " " + journal.RunID
+		if workDir, err := config.ProjectRoot("."); err == nil && !strings.HasPrefix(journal.Dir(), config.StatePath(workDir)+string(filepath.Separator)) {
+			// The journal is not found from the current directory
+			undo += " --path " + projectPath
 		}
+		logInfo("Undo journal saved to: %s (restore with: %s)", journal.Dir(), undo, slog.String("run_id", journal.RunID), slog.String("path", journal.Dir()))
+	}
 
-		return nil
+	if len(stats) > 0 {
+		var stat strings.Builder
+		unidiff.WriteStat(&stat, stats)
+		logInfo("%s", strings.TrimSuffix(stat.String(), "\n"))
+	}
+
+	if dryRun {
+		logInfo("DRY RUN COMPLETE: Would make %d changes across %d files", totalChanges, len(files), slog.Int("changes", totalChanges), slog.Int("files", len(files)))
+		logInfo("Run without --dry-run to apply changes")
+	} else {
+		logInfo("MIGRATION COMPLETE: Made %d changes across %d files", totalChanges, len(files), slog.Int("changes", totalChanges), slog.Int("files", len(files)))
+	}
+	if report.Summary.Todos > 0 {
+		logInfo("%d TODO comments need manual migration", report.Summary.Todos, slog.Int("todos", report.Summary.Todos))
 	}
 
-	err := filepath.Walk(rootPath, walkFunc)
-	return files, err
+	if failed := report.Summary.FailedFiles; failed > 0 {
+		return &ExitError{Code: ExitIO, Err: fmt.Errorf("%d of %d files could not be migrated", failed, len(files))}
+	}
+	return nil
 }
 
-// processFile applies Bootstrap migrations to a single file
-func processFile(filename string, migrations []BootstrapMigration) (int, error) {
-	// Read file content
-	content, err := os.ReadFile(filename)
+// migrateFile migrates the content of a single file with the options of the
+// command line and lists the matches per rule in dry-run mode. decide
+// optionally reviews each match.
+func migrateFile(ctx context.Context, filename string, migrations []migrate.Rule, decide migrate.Decider) (migrate.Result, error) {
+	result, err := migrate.File(ctx, filename, migrations, migrationOptions(decide))
 	if err != nil {
-		return 0, err
+		return result, err
 	}
 
-	originalContent := string(content)
-	modifiedContent := originalContent
-	changeCount := 0
-
-	// Apply all migrations
-	for _, migration := range migrations {
-		matches := migration.Pattern.FindAllString(modifiedContent, -1)
-		replacement := migration.Replacement
-		if strings.Contains(replacement, "{#") && strings.Contains(replacement, "#}") && strings.HasSuffix(strings.ToLower(filename), ".html") {
-			// Change twig comments {# #} to html comments <!-- --> for HTML files
-			replacement = strings.ReplaceAll(replacement, "{#", "<!-- ")
-			replacement = strings.ReplaceAll(replacement, "#}", " -->")
-		}
-		if len(matches) > 0 {
-			newContent := migration.Pattern.ReplaceAllString(modifiedContent, replacement)
-			if newContent != modifiedContent {
-				changeCount += len(matches)
-				modifiedContent = newContent
-				if dryRun {
-					fmt.Printf("  %s: %s (%d matches)\n", migration.Name, migration.Description, len(matches))
+	if dryRun {
+		for _, migration := range migrations {
+			count := 0
+			for _, match := range result.Matches {
+				if match.Rule == migration.Name {
+					count++
 				}
 			}
+			if count > 0 {
+				logInfo("  %s: %s (%d matches)", migration.Name, migration.Description, count)
+			}
 		}
 	}
 
-	// Write back to file if changes were made and not in dry-run mode
-	if changeCount > 0 && !dryRun {
-		err = os.WriteFile(filename, []byte(modifiedContent), 0644)
-		if err != nil {
-			return 0, fmt.Errorf("error writing file: %w", err)
-		}
+	return result, nil
+}
+
+// migrationOptions returns the options of the command line for migrate.File
+// and migrate.CheckFile
+func migrationOptions(decide migrate.Decider) migrate.Options {
+	return migrate.Options{
+		TwigInterpolation: twigInterpolation,
+		ProcessScripts:    processScripts,
+		Decide:            decide,
 	}
+}
 
-	return changeCount, nil
+// writeMigratedFile writes the migrated content back if changes were made and
+// not in dry-run mode, keeping the permissions of the file
+func writeMigratedFile(result migrate.Result) error {
+	if dryRun {
+		return nil
+	}
+	return migrate.WriteFile(result)
 }
 
-// getBootstrapMigrations returns all Bootstrap 4 to 5 migration rules
-func getBootstrapMigrations() []BootstrapMigration {
-	return []BootstrapMigration{
-		// Form Controls
-		{
-			Name:        "Custom Checkbox",
-			Pattern:     regexp.MustCompile(`custom-control\s+custom-checkbox`),
-			Replacement: "form-check",
-			Description: "custom-control custom-checkbox → form-check",
-		},
-		{
-			Name:        "Custom Radio",
-			Pattern:     regexp.MustCompile(`custom-control\s+custom-radio`),
-			Replacement: "form-check",
-			Description: "custom-control custom-radio → form-check",
-		},
-		{
-			Name:        "Custom Switch",
-			Pattern:     regexp.MustCompile(`custom-control\s+custom-switch`),
-			Replacement: "form-check form-switch",
-			Description: "custom-control custom-switch → form-check form-switch",
-		},
-		{
-			Name:        "Custom Control Input",
-			Pattern:     regexp.MustCompile(`custom-control-input`),
-			Replacement: "form-check-input",
-			Description: "custom-control-input → form-check-input",
-		},
-		{
-			Name:        "Custom Control Label",
-			Pattern:     regexp.MustCompile(`custom-control-label`),
-			Replacement: "form-check-label",
-			Description: "custom-control-label → form-check-label",
-		},
-		{
-			Name:        "Custom Select",
-			Pattern:     regexp.MustCompile(`custom-select`),
-			Replacement: "form-select",
-			Description: "custom-select → form-select",
-		},
-		{
-			Name:        "Custom Range",
-			Pattern:     regexp.MustCompile(`custom-range`),
-			Replacement: "form-range",
-			Description: "custom-range → form-range",
-		},
-		{
-			Name:        "Custom File",
-			Pattern:     regexp.MustCompile(`custom-file`),
-			Replacement: "form-control",
-			Description: "custom-file → form-control (requires additional styling)",
-		},
-
-		// Grid System
-		{
-			Name:        "No Gutters",
-			Pattern:     regexp.MustCompile(`no-gutters`),
-			Replacement: "g-0",
-			Description: "no-gutters → g-0",
-		},
-
-		// Buttons
-		{
-			Name:        "Button Block",
-			Pattern:     regexp.MustCompile(`btn-block`),
-			Replacement: "d-grid",
-			Description: "btn-block → d-grid (may need gap-* utilities)",
-		},
-
-		// Badges
-		{
-			Name:        "Badge Pill",
-			Pattern:     regexp.MustCompile(`badge-pill`),
-			Replacement: "rounded-pill",
-			Description: "badge-pill → rounded-pill",
-		},
-		{
-			Name:        "Badge Primary",
-			Pattern:     regexp.MustCompile(`badge-primary`),
-			Replacement: "bg-primary",
-			Description: "badge-primary → bg-primary",
-		},
-		{
-			Name:        "Badge Secondary",
-			Pattern:     regexp.MustCompile(`badge-secondary`),
-			Replacement: "bg-secondary",
-			Description: "badge-secondary → bg-secondary",
-		},
-		{
-			Name:        "Badge Success",
-			Pattern:     regexp.MustCompile(`badge-success`),
-			Replacement: "bg-success",
-			Description: "badge-success → bg-success",
-		},
-		{
-			Name:        "Badge Danger",
-			Pattern:     regexp.MustCompile(`badge-danger`),
-			Replacement: "bg-danger",
-			Description: "badge-danger → bg-danger",
-		},
-		{
-			Name:        "Badge Warning",
-			Pattern:     regexp.MustCompile(`badge-warning`),
-			Replacement: "bg-warning",
-			Description: "badge-warning → bg-warning",
-		},
-		{
-			Name:        "Badge Info",
-			Pattern:     regexp.MustCompile(`badge-info`),
-			Replacement: "bg-info",
-			Description: "badge-info → bg-info",
-		},
-		{
-			Name:        "Badge Light",
-			Pattern:     regexp.MustCompile(`badge-light`),
-			Replacement: "bg-light",
-			Description: "badge-light → bg-light",
-		},
-		{
-			Name:        "Badge Dark",
-			Pattern:     regexp.MustCompile(`badge-dark`),
-			Replacement: "bg-dark",
-			Description: "badge-dark → bg-dark",
-		},
-
-		// Close Button
-		{
-			Name:        "Close Button",
-			Pattern:     regexp.MustCompile(`class="close"`),
-			Replacement: `class="btn-close"`,
-			Description: "close → btn-close",
-		},
-
-		// Tables
-		{
-			Name:        "Table Header Light",
-			Pattern:     regexp.MustCompile(`thead-light`),
-			Replacement: "table-light",
-			Description: "thead-light → table-light",
-		},
-		{
-			Name:        "Table Header Dark",
-			Pattern:     regexp.MustCompile(`thead-dark`),
-			Replacement: "table-dark",
-			Description: "thead-dark → table-dark",
-		},
-		{
-			Name:        "Card Deck",
-			Pattern:     regexp.MustCompile(`card-deck`),
-			Replacement: "row row-cols-1 row-cols-md-3 g-4", // Common replacement
-			Description: "card-deck → row row-cols-* g-* (card-deck removed, use grid)",
-		},
-		{
-			Name:        "Card Columns",
-			Pattern:     regexp.MustCompile(`card-columns`),
-			Replacement: "row row-cols-1 row-cols-md-2 row-cols-xl-3",
-			Description: "card-columns → row row-cols-* (card-columns removed, use grid)",
-		},
-		{
-			Name:        "Form Group with Custom Control",
-			Pattern:     regexp.MustCompile(`form-group`),
-			Replacement: "mb-3",
-			Description: "form-group → mb-3 (or other margin utility)",
-		},
-		{
-			Name:        "Left alignment",
-			Pattern:     regexp.MustCompile(`text-left`),
-			Replacement: "text-start",
-			Description: "text-left → text-start",
-		},
-		{
-			Name:        "Right alignment",
-			Pattern:     regexp.MustCompile(`text-right`),
-			Replacement: "text-end",
-			Description: "text-right → text-end",
-		},
-		{
-			Name:        "Float Left",
-			Pattern:     regexp.MustCompile(`float-left`),
-			Replacement: "float-start",
-			Description: "float-left → float-start",
-		},
-		{
-			Name:        "Float Right",
-			Pattern:     regexp.MustCompile(`float-right`),
-			Replacement: "float-end",
-			Description: "float-right → float-end",
-		},
-		{
-			Name:        "Border Left",
-			Pattern:     regexp.MustCompile(`border-left`),
-			Replacement: "border-start",
-			Description: "border-left → border-start",
-		},
-		{
-			Name:        "Border Right",
-			Pattern:     regexp.MustCompile(`border-right`),
-			Replacement: "border-end",
-			Description: "border-right → border-end",
-		},
-		{
-			Name:        "Margin Left",
-			Pattern:     regexp.MustCompile(`\bml-(\d+)\b`),
-			Replacement: "ms-$1",
-			Description: "ml-* → ms-*",
-		},
-		{
-			Name:        "Margin Right",
-			Pattern:     regexp.MustCompile(`\bmr-(\d+)\b`),
-			Replacement: "me-$1",
-			Description: "mr-* → me-*",
-		},
-		{
-			Name:        "Padding Left",
-			Pattern:     regexp.MustCompile(`\bpl-(\d+)\b`),
-			Replacement: "ps-$1",
-			Description: "pl-* → ps-*",
-		},
-		{
-			Name:        "Padding Right",
-			Pattern:     regexp.MustCompile(`\bpr-(\d+)\b`),
-			Replacement: "pe-$1",
-			Description: "pr-* → pe-*",
-		},
-		{
-			Name:        "Data Attributes",
-			Pattern:     regexp.MustCompile(`data-(toggle|target|dismiss|slide|dropdown|toggle|bs-tooltip|bs-popover|bs-modal)`),
-			Replacement: "data-bs-$1",
-			Description: "data-* → data-bs-* (essential for JS components)",
-		},
-		{
-			Name:        "Form Row",
-			Pattern:     regexp.MustCompile(`form-row`),
-			Replacement: "row g-2",
-			Description: "form-row → row g-2 (gap utilities)",
-		},
-		{
-			Name:        "Dropdown Menu Right",
-			Pattern:     regexp.MustCompile(`dropdown-menu-right`),
-			Replacement: "dropdown-menu-end",
-			Description: "dropdown-menu-right → dropdown-menu-end",
-		},
-		{
-			Name:        "Rounded Left",
-			Pattern:     regexp.MustCompile(`rounded-left`),
-			Replacement: "rounded-start",
-			Description: "rounded-left → rounded-start",
-		},
-		{
-			Name:        "Rounded Right",
-			Pattern:     regexp.MustCompile(`rounded-right`),
-			Replacement: "rounded-end",
-			Description: "rounded-right → rounded-end",
-		},
-		{
-			Name:        "Input Group Structure",
-			Pattern:     regexp.MustCompile(`input-group-(append|prepend)`),
-			Replacement: "input-group-$1 {# TODO: input-group-append/prepend removed - Restructure completely: https://getbootstrap.com/docs/5.2/forms/input-group/ #}",
-			Description: "input-group-append/prepend removed - Requires manual restructuring",
-		},
-		{
-			Name:        "Custom File Input",
-			Pattern:     regexp.MustCompile(`custom-file-input`),
-			Replacement: "custom-file-input {# TODO: custom-file-input removed - Use new file input: https://getbootstrap.com/docs/5.2/forms/form-control/#file-input #}",
-			Description: "Custom file input removed - Requires complete redesign",
-		},
-		{
-			Name:        "Media Body",
-			Pattern:     regexp.MustCompile(`media-body`),
-			Replacement: "flex-grow-1",
-			Description: "media-body → flex-grow-1",
-		},
-		{
-			Name:        "JavaScript Initialization",
-			Pattern:     regexp.MustCompile(`\$\('\.(dropdown|tooltip|popover|modal|carousel|collapse)'\).*?\(\);?`),
-			Replacement: "$1 {# TODO: jQuery initialization removed - Use new API: https://getbootstrap.com/docs/5.2/getting-started/javascript/#usage #}",
-			Description: "jQuery initialization → Vanilla JS initialization",
-		},
-		{
-			Name:        "Breadcrumb Separator",
-			Pattern:     regexp.MustCompile(`breadcrumb-item`),
-			Replacement: "breadcrumb-item {# TODO: Remove manual separators - Now via CSS: https://getbootstrap.com/docs/5.2/components/breadcrumb/#changing-the-separator #}",
-			Description: "Breadcrumb separators now via CSS (remove manual separators)",
-		},
-		{
-			Name:        "Close Button Content",
-			Pattern:     regexp.MustCompile(`&times;`),
-			Replacement: "&times; {# TODO: Replace &times; with SVG icon: https://getbootstrap.com/docs/5.2/components/close-button/ #}",
-			Description: "&times; must be replaced with SVG icon",
-		},
-		{
-			Name:        "Navbar Toggle Icon",
-			Pattern:     regexp.MustCompile(`navbar-toggler-icon`),
-			Replacement: "navbar-toggler-icon {# TODO: Use SVG icon instead: https://getbootstrap.com/docs/5.2/components/navbar/#toggler #}",
-			Description: "Navbar toggle requires SVG instead of icon font",
-		},
-		{
-			Name:        "Form Validation",
-			Pattern:     regexp.MustCompile(`(was|is)-(valid|invalid)`),
-			Replacement: "has-$2",
-			Description: "Validation classes updated to has-* prefix",
-		},
-		{
-			Name:        "Text Monospace",
-			Pattern:     regexp.MustCompile(`text-monospace`),
-			Replacement: "font-monospace",
-			Description: "text-monospace → font-monospace",
-		},
-		{
-			Name:        "Font Weight",
-			Pattern:     regexp.MustCompile(`font-weight-(\w+)`),
-			Replacement: "fw-$1",
-			Description: "font-weight-* → fw-*",
-		},
-		{
-			Name:        "Small Rounded",
-			Pattern:     regexp.MustCompile(`rounded-sm`),
-			Replacement: "rounded-1",
-			Description: "rounded-sm → rounded-1",
-		},
-		{
-			Name:        "Large Rounded",
-			Pattern:     regexp.MustCompile(`rounded-lg`),
-			Replacement: "rounded-3",
-			Description: "rounded-lg → rounded-3",
-		},
-		{
-			Name:        "Responsive Tables",
-			Pattern:     regexp.MustCompile(`table-responsive(-\w+)?`),
-			Replacement: "table-responsive$1 {# TODO: Use wrapper div instead: https://getbootstrap.com/docs/5.2/content/tables/#responsive-tables #}",
-			Description: "Responsive tables now require wrapper div",
-		},
-		{
-			Name:        "Tooltip/Popover Positioning",
-			Pattern:     regexp.MustCompile(`bs-tooltip-[top|bottom|left|right]`),
-			Replacement: "bs-tooltip-$1 {# TODO: Update placement attributes (e.g., 'left' → 'start'): https://getbootstrap.com/docs/5.2/components/tooltips/#position #}",
-			Description: "Positioning attributes changed (e.g., 'left' → 'start')",
-		},
-		{
-			Name:        "Input Group Text",
-			Pattern:     regexp.MustCompile(`input-group-text`),
-			Replacement: "input-group-text {# TODO: Restructure without wrapper div: https://getbootstrap.com/docs/5.2/forms/input-group/ #}",
-			Description: "input-group-text no longer needs wrapper div",
-		},
+// patchPath returns the path of a file as it appears in a patch: relative to the
+// current directory with forward slashes, so the patch applies with git apply.
+// Files outside the current directory are relative to the migrated root instead.
+func patchPath(root, file string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if absFile, err := filepath.Abs(file); err == nil {
+			if relPath, err := filepath.Rel(cwd, absFile); err == nil && !strings.HasPrefix(relPath, "..") {
+				return filepath.ToSlash(relPath)
+			}
+		}
 	}
+	return relativeSlashPath(root, file)
 }
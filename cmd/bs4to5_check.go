@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+var (
+	checkOnly   bool
+	maxFindings int
+)
+
+// runMigrationCheck reports every occurrence the migrations would change as
+// file:line:column without writing files. It fails when there are more than
+// --max-findings occurrences.
+func runMigrationCheck(ctx context.Context, projectPath string, pack migrate.Pack, files []string, migrations []migrate.Rule) error {
+	report := migrate.NewReport(projectPath, pack.Name, true, migrations)
+	report.Check = true
+
+	filesWithFindings := 0
+	for _, file := range files {
+		result, findings, err := migrate.CheckFile(ctx, file, migrations, migrationOptions(nil))
+		if err != nil {
+			logError("Error processing %s: %v", file, err, slog.String("file", file), slog.String("error", err.Error()))
+			report.AddError(file, err)
+			continue
+		}
+		report.AddCheckedFile(result, findings)
+		if len(findings) > 0 {
+			filesWithFindings++
+		}
+
+		if outputFormat != "" {
+			continue
+		}
+		path := patchPath(projectPath, file)
+		for _, finding := range findings {
+			fmt.Printf("%s:%d:%d: %s: %s [%s]\n", path, finding.Line, finding.Column, finding.Rule, finding.Message, finding.Match)
+		}
+	}
+
+	if reportFile != "" {
+		if err := saveMigrationReport(report, reportFile); err != nil {
+			return err
+		}
+		logInfo("Report saved to: %s", reportFile, slog.String("path", reportFile))
+	}
+	if outputFormat != "" {
+		if err := writeReport(outputFormat, migrationResult(report)); err != nil {
+			return err
+		}
+	}
+
+	total := report.Summary.Findings
+	logInfo("CHECK COMPLETE: Found %d occurrences to migrate in %d of %d files", total, filesWithFindings, len(files),
+		slog.Int("occurrences", total), slog.Int("files_with_findings", filesWithFindings), slog.Int("files", len(files)))
+
+	if failed := report.Summary.FailedFiles; failed > 0 {
+		return &ExitError{Code: ExitIO, Err: fmt.Errorf("%d of %d files could not be checked", failed, len(files))}
+	}
+	if total > maxFindings {
+		return &ExitError{Code: ExitFindings, Err: fmt.Errorf("found %d occurrences to migrate, more than the maximum of %d", total, maxFindings)}
+	}
+	return nil
+}
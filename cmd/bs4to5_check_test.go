@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestRunBS4to5MigrationCheck(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"templates/a.html.twig": "<div class=\"ml-2\">\n  <p class=\"text-left sr-only\">x</p>\n</div>\n",
+		"templates/b.html":      "<p class=\"ms-2\">migrated</p>\n",
+	}
+	writeProjectFiles(t, ".", files)
+
+	originalCheck, originalMax, originalReport := checkOnly, maxFindings, reportFile
+	defer func() { checkOnly, maxFindings, reportFile = originalCheck, originalMax, originalReport }()
+
+	checkOnly, maxFindings, reportFile = true, 0, "check.json"
+	err := runBS4to5Migration(bs4to5Cmd, []string{"templates"})
+	if exitCodeForError(err) != ExitFindings {
+		t.Fatalf("Expected findings exit code, got %v", err)
+	}
+
+	// Nothing is written, not even an undo journal
+	for file, expected := range files {
+		if content, _ := os.ReadFile(file); string(content) != expected {
+			t.Errorf("Check changed %s:\n%s", file, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(".wswcli", migrate.JournalsDir)); !os.IsNotExist(err) {
+		t.Error("Check should not write an undo journal")
+	}
+
+	data, err := os.ReadFile("check.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report migrate.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Check || report.Summary.Findings != 3 {
+		t.Errorf("Expected a check report with 3 findings, got %+v", report.Summary)
+	}
+	for _, file := range report.Files {
+		if file.File == "a.html.twig" && len(file.Findings) != 3 {
+			t.Errorf("Expected 3 findings in a.html.twig, got %+v", file.Findings)
+		}
+	}
+
+	// At the threshold the check passes
+	maxFindings = 3
+	if err := runBS4to5Migration(bs4to5Cmd, []string{"templates"}); err != nil {
+		t.Errorf("Expected check to pass with --max-findings 3, got %v", err)
+	}
+
+	originalInteractive := interactiveReview
+	defer func() { interactiveReview = originalInteractive }()
+	interactiveReview = true
+	if err := runBS4to5Migration(bs4to5Cmd, []string{"templates"}); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected usage error for --check with --interactive, got %v", err)
+	}
+}
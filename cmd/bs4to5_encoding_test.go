@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBS4to5MigrationEncoding(t *testing.T) {
+	fixtureDir, err := filepath.Abs(filepath.Join("testdata", "bs4to5-encoding"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	fixtures := map[string]os.FileMode{
+		"bom.html.twig":    0644,
+		"crlf.html.twig":   0600,
+		"no-newline.html":  0755,
+		"latin1.html.twig": 0644,
+	}
+	for name, mode := range fixtures {
+		data, err := os.ReadFile(filepath.Join(fixtureDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeProjectFiles(t, "templates", map[string]string{name: string(data)})
+		if err := os.Chmod(filepath.Join("templates", name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	originalDryRun, originalReport := dryRun, reportFile
+	defer func() { dryRun, reportFile = originalDryRun, originalReport }()
+	dryRun, reportFile = false, "report.json"
+
+	// The Latin-1 template is refused and fails the run
+	err = runBS4to5Migration(bs4to5Cmd, []string{"templates"})
+	if exitCodeForError(err) != ExitIO {
+		t.Fatalf("Expected I/O error for the Latin-1 template, got %v", err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join("templates", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if content := read("latin1.html.twig"); content != "<p class=\"ml-2\">Gr\xfc\xdfe aus M\xfcnchen</p>\n" {
+		t.Errorf("Latin-1 template was changed: %q", content)
+	}
+	if content := read("bom.html.twig"); content != "\xef\xbb\xbf<div class=\"ms-2\">\n  <p class=\"text-start\">Grüße</p>\n</div>\n" {
+		t.Errorf("Unexpected content with BOM: %q", content)
+	}
+	expected := "<div class=\"input-group\">\r\n  <span class=\"input-group-text\">@</span>\r\n  <input class=\"form-control me-1\">\r\n</div>\r\n"
+	if content := read("crlf.html.twig"); content != expected {
+		t.Errorf("Unexpected content with CRLF:\n%q\nExpected:\n%q", content, expected)
+	}
+	if content := read("no-newline.html"); content != `<p class="ps-3">Kein Zeilenumbruch am Ende</p>` {
+		t.Errorf("Unexpected content without final newline: %q", content)
+	}
+
+	for name, mode := range fixtures {
+		info, err := os.Stat(filepath.Join("templates", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("Expected mode %v for %s, got %v", mode, name, info.Mode().Perm())
+		}
+	}
+
+	data, err := os.ReadFile("report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"latin1.html.twig"`) || !strings.Contains(string(data), "not valid UTF-8") {
+		t.Errorf("Expected the Latin-1 template as failed file in the report:\n%s", data)
+	}
+
+	// The undo journal restores the exact original bytes
+	originalUndoPath := undoPath
+	defer func() { undoPath = originalUndoPath }()
+	undoPath = "templates"
+	if err := runMigrationUndo(bs4to5UndoCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	original, _ := os.ReadFile(filepath.Join(fixtureDir, "crlf.html.twig"))
+	if content := read("crlf.html.twig"); content != string(original) {
+		t.Errorf("Undo did not restore the CRLF file: %q", content)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+var bs4to5FileTypesCmd = &cobra.Command{
+	Use:   "file-types",
+	Short: "List the file types bs-4-to-5 migrates and their rule sets",
+	Long: `List the file extensions bs-4-to-5 migrates, the rule set applied to each and
+the comment syntax used for TODO notes.
+
+Examples:
+  wswcli bs-4-to-5 file-types
+  wswcli bs-4-to-5 file-types --rules team-rules.yaml`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pack, err := migrate.FindPack(migrate.BootstrapPackName)
+		if err != nil {
+			return err
+		}
+		return runMigrationFileTypes(cmd, pack)
+	},
+}
+
+func init() {
+	bs4to5Cmd.AddCommand(bs4to5FileTypesCmd)
+}
+
+// runMigrationFileTypes lists the file types with the number of effective rules of pack
+func runMigrationFileTypes(cmd *cobra.Command, pack migrate.Pack) error {
+	selection, err := resolveRuleSelection(cmd, pack)
+	if err != nil {
+		return err
+	}
+
+	ruleSet, err := migrate.LoadRules(selection)
+	if err != nil {
+		return err
+	}
+
+	return writeFileTypeList(os.Stdout, ruleSet.Rules)
+}
+
+// writeFileTypeList writes the file types with their rule set and the number of effective rules
+func writeFileTypeList(w io.Writer, rules []migrate.Rule) error {
+	counts := make(map[string]int)
+	for _, rule := range rules {
+		counts[migrate.RuleSetOf(rule)]++
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TYPE\tEXTENSIONS\tRULE SET\tRULES\tTODO COMMENT")
+	for _, fileType := range migrate.FileTypes() {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\n", fileType.Name, strings.Join(fileType.Extensions, ", "),
+			fileType.RuleSet, counts[fileType.RuleSet], fileType.Comment.Render("{# TODO: ... #}"))
+	}
+	return table.Flush()
+}
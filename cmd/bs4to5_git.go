@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	allowDirty  bool
+	trackedOnly bool
+)
+
+// gitWorkTree holds the state of the git work tree a migration runs in
+type gitWorkTree struct {
+	Root    string            // Top-level directory, symlinks resolved
+	Tracked map[string]bool   // Tracked files by absolute path
+	Dirty   map[string]string // Files with uncommitted changes by absolute path, "modified" or "untracked"
+}
+
+// findGitWorkTree returns the git work tree containing path. It returns nil when
+// path is not inside a work tree or git is not installed.
+func findGitWorkTree(path string) (*gitWorkTree, error) {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	output, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, nil
+	}
+
+	root := strings.TrimSpace(string(output))
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	tree := &gitWorkTree{Root: root, Tracked: make(map[string]bool), Dirty: make(map[string]string)}
+
+	output, err = exec.Command("git", "-C", root, "ls-files", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing tracked files: %w", err)
+	}
+	for _, name := range strings.Split(string(output), "\x00") {
+		if name != "" {
+			tree.Tracked[filepath.Join(root, filepath.FromSlash(name))] = true
+		}
+	}
+
+	output, err = exec.Command("git", "-C", root, "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading git status: %w", err)
+	}
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status := "modified"
+		if entry[:2] == "??" {
+			status = "untracked"
+		}
+		tree.Dirty[filepath.Join(root, filepath.FromSlash(entry[3:]))] = status
+		if entry[0] == 'R' || entry[0] == 'C' {
+			// Renames and copies are followed by the original path
+			i++
+		}
+	}
+	return tree, nil
+}
+
+// key returns the absolute path of file as git reports it
+func (t *gitWorkTree) key(file string) string {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// applyGitSafety restricts files to tracked files with --tracked-only and, when
+// the migration writes files, refuses files with uncommitted changes unless
+// --allow-dirty is given, so manual edits are never mixed with the migration
+func applyGitSafety(projectPath string, files []string, writes bool) ([]string, error) {
+	tree, err := findGitWorkTree(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		if trackedOnly {
+			return nil, usageError("--tracked-only requires %s to be inside a git work tree", projectPath)
+		}
+		if writes {
+			logInfo("Not inside a git work tree, use the undo command to revert the migration")
+		}
+		return files, nil
+	}
+
+	if trackedOnly {
+		var tracked []string
+		for _, file := range files {
+			if tree.Tracked[tree.key(file)] {
+				tracked = append(tracked, file)
+			}
+		}
+		if skipped := len(files) - len(tracked); skipped > 0 {
+			logInfo("Skipping %d untracked files (--tracked-only)", skipped)
+		}
+		files = tracked
+	}
+
+	if !writes || allowDirty {
+		return files, nil
+	}
+	var dirty []string
+	for _, file := range files {
+		if status, ok := tree.Dirty[tree.key(file)]; ok {
+			dirty = append(dirty, fmt.Sprintf("  %s (%s)", patchPath(projectPath, file), status))
+		}
+	}
+	if len(dirty) > 0 {
+		logWarn("Files with uncommitted changes:\n%s", strings.Join(dirty, "\n"))
+		return nil, usageError("%d files to migrate have uncommitted changes, commit or stash them first or pass --allow-dirty", len(dirty))
+	}
+	return files, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+// initGitRepo creates a git repository in dir with files committed
+func initGitRepo(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	writeProjectFiles(t, dir, files)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+}
+
+func TestApplyGitSafety(t *testing.T) {
+	t.Chdir(t.TempDir())
+	initGitRepo(t, "project", map[string]string{
+		"templates/a.html.twig": "<div class=\"ml-2\"></div>\n",
+		"templates/b.html.twig": "<div class=\"mr-2\"></div>\n",
+	})
+	// An uncommitted edit and an untracked file
+	writeProjectFiles(t, "project", map[string]string{
+		"templates/b.html.twig": "<div class=\"mr-2\">edited</div>\n",
+		"templates/new.html":    "<p class=\"pl-1\"></p>\n",
+	})
+
+	originalDryRun, originalAllowDirty, originalTrackedOnly := dryRun, allowDirty, trackedOnly
+	defer func() { dryRun, allowDirty, trackedOnly = originalDryRun, originalAllowDirty, originalTrackedOnly }()
+	dryRun, allowDirty, trackedOnly = false, false, false
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join("project", "templates", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	// Dirty files block the migration, nothing is written
+	err := runBS4to5Migration(bs4to5Cmd, []string{"project"})
+	if exitCodeForError(err) != ExitUsage {
+		t.Fatalf("Expected usage error for uncommitted changes, got %v", err)
+	}
+	if read("a.html.twig") != "<div class=\"ml-2\"></div>\n" {
+		t.Error("Migration should not write files when files are dirty")
+	}
+
+	// A dry run writes nothing and is not blocked
+	dryRun = true
+	if err := runBS4to5Migration(bs4to5Cmd, []string{"project"}); err != nil {
+		t.Errorf("Expected dry run to pass, got %v", err)
+	}
+	dryRun = false
+
+	files, err := migrate.FindFiles(context.Background(), "project", true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trackedOnly = true
+	tracked, err := applyGitSafety("project", files, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 2 {
+		t.Errorf("Expected the 2 tracked files, got %v", tracked)
+	}
+
+	// --allow-dirty with --tracked-only migrates the tracked files only
+	allowDirty = true
+	if err := runBS4to5Migration(bs4to5Cmd, []string{"project"}); err != nil {
+		t.Fatal(err)
+	}
+	if read("a.html.twig") != "<div class=\"ms-2\"></div>\n" || read("b.html.twig") != "<div class=\"me-2\">edited</div>\n" {
+		t.Errorf("Expected tracked files to be migrated, got %q and %q", read("a.html.twig"), read("b.html.twig"))
+	}
+	if read("new.html") != "<p class=\"pl-1\"></p>\n" {
+		t.Error("Untracked file should be skipped with --tracked-only")
+	}
+
+	// --tracked-only needs a git work tree
+	writeProjectFiles(t, "plain", map[string]string{"a.html": "<p></p>\n"})
+	if _, err := applyGitSafety("plain", []string{filepath.Join("plain", "a.html")}, true); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected usage error for --tracked-only outside git, got %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+// reviewSessionSuffix is appended to the pack name to name its review session in the state directory
+const reviewSessionSuffix = "-session.json"
+
+// reviewContextLines is the number of unchanged lines shown around a change
+const reviewContextLines = 3
+
+// Review decisions stored in the session file
+const (
+	reviewAccept   = "accept"
+	reviewReject   = "reject"
+	reviewEdit     = "edit"
+	reviewSkipFile = "skip-file"
+)
+
+// reviewSession holds the decisions of an interactive review so an interrupted
+// run can resume where it stopped
+type reviewSession struct {
+	Root      string                 `json:"root"`       // Absolute path of the migrated directory
+	AcceptAll []string               `json:"accept_all"` // Rules accepted for all remaining matches
+	Files     map[string]*reviewFile `json:"files"`
+}
+
+// reviewFile holds the decisions made for a single file
+type reviewFile struct {
+	Hash       string           `json:"hash"`                  // SHA-256 of the content the decisions were made on
+	ResultHash string           `json:"result_hash,omitempty"` // SHA-256 of the migrated content once the file is done
+	Decisions  []reviewDecision `json:"decisions"`
+}
+
+// reviewDecision is the answer given for a single match
+type reviewDecision struct {
+	Rule        string `json:"rule"`
+	Offset      int    `json:"offset"` // Byte offset of the match in the content shown for review
+	Original    string `json:"original"`
+	Action      string `json:"action"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// interactiveReviewer asks for a decision on every match of a migration run
+type interactiveReviewer struct {
+	session      *reviewSession
+	sessionPath  string
+	descriptions map[string]string
+	in           *bufio.Reader
+	out          io.Writer
+
+	// State of the file under review
+	file     string
+	entry    *reviewFile
+	replay   int // Index of the next stored decision to replay
+	skipFile bool
+	quit     bool
+}
+
+// newInteractiveReviewer starts a review of a pack run over root, resuming a
+// stored session of the pack for the same directory
+func newInteractiveReviewer(root, pack string, migrations []migrate.Rule, in *bufio.Reader, out io.Writer) (*interactiveReviewer, error) {
+	projectDir, err := config.ProjectRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	stateDir, err := config.StateDir(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving path: %w", err)
+	}
+
+	reviewer := &interactiveReviewer{
+		session:      &reviewSession{Root: absRoot, Files: make(map[string]*reviewFile)},
+		sessionPath:  filepath.Join(stateDir, pack+reviewSessionSuffix),
+		descriptions: make(map[string]string),
+		in:           in,
+		out:          out,
+	}
+	for _, migration := range migrations {
+		reviewer.descriptions[migration.Name] = migration.Description
+	}
+
+	data, err := os.ReadFile(reviewer.sessionPath)
+	switch {
+	case os.IsNotExist(err):
+		return reviewer, nil
+	case err != nil:
+		return nil, fmt.Errorf("error reading review session: %w", err)
+	}
+
+	var session reviewSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("error parsing review session %s: %w", reviewer.sessionPath, err)
+	}
+	if session.Root != absRoot {
+		fmt.Fprintf(out, "Ignoring review session for %s\n", session.Root)
+		return reviewer, nil
+	}
+	if session.Files == nil {
+		session.Files = make(map[string]*reviewFile)
+	}
+	reviewer.session = &session
+	fmt.Fprintf(out, "Resuming review session from %s\n", reviewer.sessionPath)
+
+	return reviewer, nil
+}
+
+// StartFile prepares the review of file and reports whether it still needs to be migrated
+func (r *interactiveReviewer) StartFile(file string) bool {
+	r.file = file
+	r.replay = 0
+	r.skipFile = false
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		// migrateFile reports the error
+		r.entry = &reviewFile{}
+		return true
+	}
+	hash := migrate.ContentHash(content)
+
+	entry, ok := r.session.Files[file]
+	switch {
+	case ok && entry.ResultHash == hash && entry.ResultHash != entry.Hash:
+		// Written in an earlier run of this session
+		return false
+	case ok && entry.Hash == hash:
+		entry.ResultHash = ""
+	default:
+		// New file or changed since the decisions were made
+		entry = &reviewFile{Hash: hash}
+		r.session.Files[file] = entry
+	}
+	r.entry = entry
+
+	return true
+}
+
+// Decide replays a stored decision for match or asks for a new one
+func (r *interactiveReviewer) Decide(content string, match migrate.Match) (string, bool) {
+	if r.quit || r.skipFile {
+		return "", false
+	}
+
+	if r.replay < len(r.entry.Decisions) {
+		decision := r.entry.Decisions[r.replay]
+		if decision.Action == reviewSkipFile {
+			r.replay++
+			r.skipFile = true
+			return "", false
+		}
+		if decision.Rule == match.Rule && decision.Offset == match.Start && decision.Original == match.Original {
+			r.replay++
+			switch decision.Action {
+			case reviewAccept:
+				return match.Replacement, true
+			case reviewEdit:
+				return decision.Replacement, true
+			default:
+				return "", false
+			}
+		}
+		// The rules changed since the decisions were made, ask again from here
+		r.entry.Decisions = r.entry.Decisions[:r.replay]
+	}
+
+	if containsString(r.session.AcceptAll, match.Rule) {
+		return match.Replacement, true
+	}
+
+	r.showMatch(content, match)
+	for {
+		fmt.Fprint(r.out, "Apply? [y]es, [n]o, [e]dit, [a]ll for this rule, [s]kip file, [q]uit: ")
+		answer, err := r.in.ReadString('\n')
+		if err != nil && strings.TrimSpace(answer) == "" {
+			// End of input, keep the session for a later run
+			fmt.Fprintln(r.out)
+			r.quit = true
+			return "", false
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			r.record(match, reviewAccept, "")
+			return match.Replacement, true
+		case "n", "no":
+			r.record(match, reviewReject, "")
+			return "", false
+		case "e", "edit":
+			fmt.Fprint(r.out, "Replacement: ")
+			replacement, err := r.in.ReadString('\n')
+			replacement = strings.TrimRight(replacement, "\r\n")
+			if err != nil && replacement == "" {
+				continue
+			}
+			r.record(match, reviewEdit, replacement)
+			return replacement, true
+		case "a", "all":
+			r.session.AcceptAll = append(r.session.AcceptAll, match.Rule)
+			r.record(match, reviewAccept, "")
+			return match.Replacement, true
+		case "s", "skip":
+			r.skipFile = true
+			r.record(match, reviewSkipFile, "")
+			return "", false
+		case "q", "quit":
+			r.quit = true
+			return "", false
+		default:
+			fmt.Fprintln(r.out, "Please answer y, n, e, a, s or q.")
+		}
+	}
+}
+
+// FinishFile marks the file under review as done
+func (r *interactiveReviewer) FinishFile(result migrate.Result) error {
+	r.entry.ResultHash = migrate.ContentHash([]byte(result.Migrated))
+	return r.save()
+}
+
+// Quit reports whether the user stopped the review
+func (r *interactiveReviewer) Quit() bool {
+	return r.quit
+}
+
+// SessionPath returns the path of the session file
+func (r *interactiveReviewer) SessionPath() string {
+	return r.sessionPath
+}
+
+// Close removes the session file after a completed review
+func (r *interactiveReviewer) Close() error {
+	if err := os.Remove(r.sessionPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing review session: %w", err)
+	}
+	return nil
+}
+
+// record stores a decision and saves the session so it survives an interrupted run
+func (r *interactiveReviewer) record(match migrate.Match, action, replacement string) {
+	r.entry.Decisions = append(r.entry.Decisions, reviewDecision{
+		Rule:        match.Rule,
+		Offset:      match.Start,
+		Original:    match.Original,
+		Action:      action,
+		Replacement: replacement,
+	})
+	r.replay = len(r.entry.Decisions)
+	if err := r.save(); err != nil {
+		fmt.Fprintf(r.out, "Warning: %v\n", err)
+	}
+}
+
+// save writes the session file through a temporary file
+func (r *interactiveReviewer) save() error {
+	data, err := json.MarshalIndent(r.session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding review session: %w", err)
+	}
+	tmpPath := r.sessionPath + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing review session: %w", err)
+	}
+	if err := os.Rename(tmpPath, r.sessionPath); err != nil {
+		return fmt.Errorf("error writing review session: %w", err)
+	}
+	return nil
+}
+
+// showMatch prints the change with surrounding lines before and after it
+func (r *interactiveReviewer) showMatch(content string, match migrate.Match) {
+	lineStart := strings.LastIndex(content[:match.Start], "\n") + 1
+	lineEnd := len(content)
+	if i := strings.Index(content[match.End:], "\n"); i >= 0 {
+		lineEnd = match.End + i
+	}
+	line := strings.Count(content[:lineStart], "\n") + 1
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	before := strings.Split(content[lineStart:lineEnd], "\n")
+	after := strings.Split(content[lineStart:match.Start]+match.Replacement+content[match.End:lineEnd], "\n")
+
+	fmt.Fprintf(r.out, "\n%s:%d  %s", r.file, line, match.Rule)
+	if description := r.descriptions[match.Rule]; description != "" {
+		fmt.Fprintf(r.out, ": %s", description)
+	}
+	fmt.Fprintln(r.out)
+
+	for i := max(0, line-1-reviewContextLines); i < line-1; i++ {
+		fmt.Fprintf(r.out, "  %5d  %s\n", i+1, lines[i])
+	}
+	for i, text := range before {
+		fmt.Fprintf(r.out, "- %5d  %s\n", line+i, text)
+	}
+	for i, text := range after {
+		fmt.Fprintf(r.out, "+ %5d  %s\n", line+i, text)
+	}
+	last := line - 1 + len(before)
+	for i := last; i < min(len(lines), last+reviewContextLines); i++ {
+		fmt.Fprintf(r.out, "  %5d  %s\n", i+1, lines[i])
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestInteractiveReview(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProjectFiles(t, "templates", map[string]string{
+		"a.html.twig": "<div class=\"ml-2 mr-2\">\n  <span class=\"ml-1\">A</span>\n</div>\n",
+		"b.html.twig": "<div class=\"pl-3\">B</div>\n",
+	})
+
+	originalDryRun, originalInteractive := dryRun, interactiveReview
+	defer func() { dryRun, interactiveReview = originalDryRun, originalInteractive }()
+	dryRun, interactiveReview = false, true
+
+	migrations := bootstrapMigrations(t)
+	review := func(input string) (*interactiveReviewer, string) {
+		t.Helper()
+		var out bytes.Buffer
+		reviewer, err := newInteractiveReviewer("templates", migrate.BootstrapPackName, migrations, bufio.NewReader(strings.NewReader(input)), &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range []string{"templates/a.html.twig", "templates/b.html.twig"} {
+			if !reviewer.StartFile(file) {
+				continue
+			}
+			result, err := migrateFile(context.Background(), file, migrations, reviewer.Decide)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reviewer.Quit() {
+				break
+			}
+			if err := writeMigratedFile(result); err != nil {
+				t.Fatal(err)
+			}
+			if err := reviewer.FinishFile(result); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return reviewer, out.String()
+	}
+
+	// Accept ml-2, reject ml-1, then stop at mr-2
+	reviewer, output := review("y\nn\n")
+	if !reviewer.Quit() {
+		t.Fatal("Expected review to stop at end of input")
+	}
+	if !strings.Contains(output, "templates/a.html.twig:1  Margin Left") ||
+		!strings.Contains(output, "-     1  <div class=\"ml-2 mr-2\">") ||
+		!strings.Contains(output, "+     1  <div class=\"ms-2 mr-2\">") ||
+		!strings.Contains(output, "      2    <span class=\"ml-1\">A</span>") {
+		t.Errorf("Unexpected review output:\n%s", output)
+	}
+	if content, _ := os.ReadFile("templates/a.html.twig"); strings.Contains(string(content), "ms-2") {
+		t.Error("Interrupted file should not be written")
+	}
+	if _, err := os.Stat(reviewer.SessionPath()); err != nil {
+		t.Fatalf("Expected session file: %v", err)
+	}
+
+	// Resuming replays the stored decisions and only asks for mr-2 (edited) and pl-3 (skipped)
+	reviewer, output = review("e\nme-3\ns\n")
+	if reviewer.Quit() {
+		t.Fatalf("Expected review to complete:\n%s", output)
+	}
+	if strings.Count(output, "Apply?") != 2 {
+		t.Errorf("Expected 2 questions after resume, got:\n%s", output)
+	}
+	content, err := os.ReadFile("templates/a.html.twig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<div class=\"ms-2 me-3\">\n  <span class=\"ml-1\">A</span>\n</div>\n"; string(content) != expected {
+		t.Errorf("Unexpected content:\n%s\nExpected:\n%s", content, expected)
+	}
+	if content, _ := os.ReadFile("templates/b.html.twig"); !strings.Contains(string(content), "pl-3") {
+		t.Error("Skipped file should not be changed")
+	}
+
+	// A completed review removes its session
+	if err := reviewer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(reviewer.SessionPath()); !os.IsNotExist(err) {
+		t.Error("Expected session file to be removed")
+	}
+}
+
+func TestInteractiveReviewWithConfigFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProjectFiles(t, ".", map[string]string{
+		".wswcli":               "[patchvendor]\npatch_output_dir = \"build/patches\"\n",
+		"templates/a.html.twig": "<div class=\"ml-2\"></div>\n",
+	})
+
+	var out bytes.Buffer
+	reviewer, err := newInteractiveReviewer("templates", migrate.BootstrapPackName, bootstrapMigrations(t), bufio.NewReader(strings.NewReader("")), &out)
+	if err != nil {
+		t.Fatalf("Expected the review to start next to a .wswcli config file, got %v", err)
+	}
+	if expected := filepath.Join(".wswcli-state", migrate.BootstrapPackName+reviewSessionSuffix); !strings.HasSuffix(reviewer.SessionPath(), expected) {
+		t.Errorf("Expected the session in %s, got %s", expected, reviewer.SessionPath())
+	}
+}
+
+func TestInteractiveReviewAcceptAll(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProjectFiles(t, "templates", map[string]string{
+		"a.html.twig": "<div class=\"ml-1 ml-2\"><p class=\"ml-3 mr-1\"></p></div>\n",
+	})
+
+	var out bytes.Buffer
+	migrations := bootstrapMigrations(t)
+	reviewer, err := newInteractiveReviewer("templates", migrate.BootstrapPackName, migrations, bufio.NewReader(strings.NewReader("a\nn\n")), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviewer.StartFile("templates/a.html.twig")
+	content, _ := os.ReadFile("templates/a.html.twig")
+	migrated, _ := migrate.Content(string(content), migrations, migrate.Options{Decide: reviewer.Decide})
+
+	if expected := "<div class=\"ms-1 ms-2\"><p class=\"ms-3 mr-1\"></p></div>\n"; migrated != expected {
+		t.Errorf("Unexpected content:\n%s\nExpected:\n%s", migrated, expected)
+	}
+	if count := strings.Count(out.String(), "Apply?"); count != 2 {
+		t.Errorf("Expected 2 questions, got %d:\n%s", count, out.String())
+	}
+}
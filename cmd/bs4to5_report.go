@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+// reportWriters maps the supported report file extensions to their writers
+var reportWriters = map[string]func(*migrate.Report, io.Writer) error{
+	".json": (*migrate.Report).WriteJSON,
+	".html": (*migrate.Report).WriteHTML,
+	".xml":  (*migrate.Report).WriteJUnit,
+}
+
+// checkReportFormat returns a usage error when the report file has no supported extension
+func checkReportFormat(path string) error {
+	if _, ok := reportWriters[strings.ToLower(filepath.Ext(path))]; !ok {
+		return usageError("unsupported report format: %s (use .json, .html or .xml for JUnit)", path)
+	}
+	return nil
+}
+
+// saveMigrationReport writes the report in the format given by the extension of path
+func saveMigrationReport(migrationReport *migrate.Report, path string) error {
+	if err := checkReportFormat(path); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating report file: %w", err)
+	}
+	defer file.Close()
+
+	if err := reportWriters[strings.ToLower(filepath.Ext(path))](migrationReport, file); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
+
+// migrationResult returns the findings of a --check run, or the inserted TODO
+// comments of a migration, and the files that failed as findings of the shared report
+func migrationResult(migrationReport *migrate.Report) *report.Result {
+	result := newReportResult(migrationReport.Pack)
+	result.Root = migrationReport.Root
+	for _, file := range migrationReport.Files {
+		result.Files = append(result.Files, file.File)
+		if file.Error != "" {
+			result.Add(report.Finding{Rule: "file-error", Severity: report.SeverityError, Message: file.Error, File: file.File})
+		}
+		for _, finding := range file.Findings {
+			result.Add(report.Finding{
+				Rule:     finding.Rule,
+				Category: finding.Category,
+				Severity: report.SeverityWarning,
+				Message:  finding.Message,
+				File:     file.File,
+				Line:     finding.Line,
+				Column:   finding.Column,
+				Snippet:  finding.Match,
+			})
+		}
+		if migrationReport.Check {
+			continue
+		}
+		for _, todo := range file.TodoAt {
+			result.Add(report.Finding{
+				Rule:     todo.Rule,
+				Category: migrationReport.RuleCategory(todo.Rule),
+				Severity: report.SeverityInfo,
+				Message:  todo.Note,
+				File:     file.File,
+				Line:     todo.Line,
+				Column:   todo.Column,
+			})
+		}
+	}
+	return result
+}
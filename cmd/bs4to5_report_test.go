@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestRunBS4to5MigrationReport(t *testing.T) {
+	t.Chdir(t.TempDir())
+	tempDir := t.TempDir()
+	writeProjectFiles(t, tempDir, map[string]string{
+		"templates/a.html.twig": "<div class=\"ml-2\">\n  {{ content }}\n  <div class=\"mr-1 input-group-append\">x</div>\n</div>\n",
+		"templates/b.html":      "<p>unchanged</p>\n",
+	})
+	if err := os.Symlink(filepath.Join(tempDir, "missing.html"), filepath.Join(tempDir, "templates/broken.html")); err != nil {
+		t.Fatal(err)
+	}
+	reportDir := t.TempDir()
+
+	originalDryRun, originalReport := dryRun, reportFile
+	defer func() { dryRun, reportFile = originalDryRun, originalReport }()
+
+	dryRun, reportFile = false, filepath.Join(reportDir, "report.json")
+	err := runBS4to5Migration(bs4to5Cmd, []string{tempDir})
+	if exitCodeForError(err) != ExitIO {
+		t.Fatalf("Expected I/O error for the broken file, got %v", err)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report migrate.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := migrate.ReportSummary{Files: 3, ChangedFiles: 1, FailedFiles: 1, Changes: 2, Todos: 1}
+	if report.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, report.Summary)
+	}
+
+	files := make(map[string]migrate.FileReport)
+	for _, file := range report.Files {
+		files[file.File] = file
+	}
+	if files["templates/broken.html"].Error == "" {
+		t.Errorf("Expected an error for the broken file: %+v", report.Files)
+	}
+
+	// The TODO location points at the comment in the migrated file
+	migrated, err := os.ReadFile(filepath.Join(tempDir, "templates/a.html.twig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(migrated), "\n")
+	todos := files["templates/a.html.twig"].TodoAt
+	if len(todos) != 1 {
+		t.Fatalf("Expected one TODO location, got %+v", todos)
+	}
+	if todo := todos[0]; todo.Line != 3 || !strings.HasPrefix(lines[2][todo.Column-1:], "{# TODO:") || todo.Rule != "Input Group Structure" || todo.Note == "" {
+		t.Errorf("Unexpected TODO location %+v in:\n%s", todo, migrated)
+	}
+
+	rules := make(map[string]migrate.RuleReport)
+	for _, rule := range report.Rules {
+		rules[rule.Rule] = rule
+	}
+	if rule := rules["Input Group Structure"]; rule.Todos != 1 || rule.Files != 1 || rule.Category != "forms" {
+		t.Errorf("Unexpected rule report: %+v", report.Rules)
+	}
+}
+
+func TestCheckReportFormat(t *testing.T) {
+	if err := checkReportFormat("report.txt"); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected usage error for an unsupported report format, got %v", err)
+	}
+	if err := checkReportFormat("report.HTML"); err != nil {
+		t.Errorf("Expected the extension to be case-insensitive, got %v", err)
+	}
+}
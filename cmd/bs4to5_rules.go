@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+var (
+	ruleFiles      []string
+	onlyCategories []string
+	skipRules      []string
+)
+
+var bs4to5RulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List the effective Bootstrap migration rules",
+	Long: `List the migration rules bs-4-to-5 applies with their category, scope and source,
+after merging rule files and applying --only and --skip.
+
+Examples:
+  wswcli bs-4-to-5 rules
+  wswcli bs-4-to-5 rules --rules team-rules.yaml --only forms`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pack, err := migrate.FindPack(migrate.BootstrapPackName)
+		if err != nil {
+			return err
+		}
+		return runMigrationRules(cmd, pack)
+	},
+}
+
+func init() {
+	bs4to5Cmd.AddCommand(bs4to5RulesCmd)
+	pack, _ := migrate.FindPack(migrate.BootstrapPackName)
+	bs4to5RulesCmd.AddCommand(newRulesVerifyCommand(pack, "wswcli bs-4-to-5 rules verify"))
+}
+
+// runMigrationRules lists the effective rules of pack
+func runMigrationRules(cmd *cobra.Command, pack migrate.Pack) error {
+	selection, err := resolveRuleSelection(cmd, pack)
+	if err != nil {
+		return err
+	}
+
+	ruleSet, err := migrate.LoadRules(selection)
+	if err != nil {
+		return err
+	}
+
+	return writeRuleList(os.Stdout, ruleSet)
+}
+
+// resolveRuleSelection combines the command line with the [bs-4-to-5] config
+// section, which applies to the bootstrap-4-to-5 pack. Rule files from both are
+// loaded, flags for --only and --skip replace the config.
+func resolveRuleSelection(cmd *cobra.Command, pack migrate.Pack) (migrate.Selection, error) {
+	selection := migrate.Selection{Files: ruleFiles, Pack: pack.Name, Check: checkOnly}
+	if pack.Name == migrate.BootstrapPackName {
+		cfg, err := config.Load(".")
+		if err != nil {
+			return migrate.Selection{}, fmt.Errorf("error loading configuration: %w", err)
+		}
+		selection.Files = append(append([]string{}, cfg.BS4to5.Rules...), ruleFiles...)
+		selection.Only = cfg.BS4to5.Only
+		selection.Skip = cfg.BS4to5.Skip
+	}
+	if cmd.Flags().Changed("only") {
+		selection.Only = onlyCategories
+	}
+	if cmd.Flags().Changed("skip") {
+		selection.Skip = skipRules
+	}
+
+	return selection, nil
+}
+
+// writeRuleList writes the effective rules as a table followed by disabled and skipped rules
+func writeRuleList(w io.Writer, ruleSet migrate.EffectiveRules) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tCATEGORY\tRULE SET\tSCOPE\tSOURCE\tDESCRIPTION")
+	for _, rule := range ruleSet.Rules {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", rule.Name, rule.Category, migrate.RuleSetOf(rule), migrate.ScopeOf(rule), rule.Source, rule.Description)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	sources := make(map[string]int)
+	for _, rule := range ruleSet.Rules {
+		if rule.Source != migrate.BuiltinRuleSource {
+			sources[rule.Source]++
+		}
+	}
+	var fromFiles []string
+	for source, count := range sources {
+		fromFiles = append(fromFiles, fmt.Sprintf("%d from %s", count, source))
+	}
+	sort.Strings(fromFiles)
+
+	summary := fmt.Sprintf("\n%d rules", len(ruleSet.Rules))
+	if len(fromFiles) > 0 {
+		summary += " (" + strings.Join(fromFiles, ", ") + ")"
+	}
+	fmt.Fprintln(w, summary)
+
+	for _, group := range []struct {
+		label string
+		rules []migrate.Rule
+	}{{"Disabled", ruleSet.Disabled}, {"Skipped", ruleSet.Skipped}} {
+		if len(group.rules) == 0 {
+			continue
+		}
+		names := make([]string, len(group.rules))
+		for i, rule := range group.rules {
+			names[i] = rule.Name
+		}
+		fmt.Fprintf(w, "%s: %s\n", group.label, strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestWriteRuleList(t *testing.T) {
+	ruleSet, err := migrate.LoadRules(migrate.Selection{Only: []string{"grid"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeRuleList(&buf, ruleSet); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"NAME", "No Gutters", "built-in", "1 rules", "Skipped: Input Group Addons"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in rule list:\n%s", expected, output)
+		}
+	}
+}
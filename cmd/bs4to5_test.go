@@ -1,218 +1,62 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
-)
-
-func TestFindTemplateFiles(t *testing.T) {
-	// Create temporary directory structure
-	tempDir := t.TempDir()
 
-	// Create test files
-	testFiles := []string{
-		"template.html",
-		"template.html.twig",
-		"template.twig",
-		"subdir/another.html",
-		"subdir/another.html.twig",
-		"subdir/nested/deep.twig",
-		"regular.txt",               // Should be ignored
-		"node_modules/ignored.html", // Should be ignored (node_modules)
-		".hidden/ignored.html.twig", // Should be ignored (hidden dir)
-		"vendor/ignored.twig",       // Should be ignored (vendor)
-		"var/cache/ignored.html",    // Should be ignored (var)
-	}
-
-	for _, file := range testFiles {
-		fullPath := filepath.Join(tempDir, file)
-		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.WriteFile(fullPath, []byte("test content"), 0644); err != nil {
-			t.Fatal(err)
-		}
-	}
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
 
-	// Test findTemplateFiles with recursive=true
-	found, err := findTemplateFiles(tempDir, true)
+// bootstrapMigrations returns the built-in rules of the bootstrap-4-to-5 pack
+func bootstrapMigrations(t *testing.T) []migrate.Rule {
+	t.Helper()
+	pack, err := migrate.FindPack(migrate.BootstrapPackName)
 	if err != nil {
-		t.Fatalf("findTemplateFiles failed: %v", err)
+		t.Fatal(err)
 	}
+	return pack.Rules()
+}
 
-	// Should find 6 template files: .html, .html.twig, .twig (excluding ignored ones)
-	expectedCount := 6
-	if len(found) != expectedCount {
-		t.Errorf("Expected %d files, got %d: %v", expectedCount, len(found), found)
+func TestFindMigrationFilesSymlinks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeProjectFiles(t, root, map[string]string{"templates/base.html.twig": `<div class="ml-2"></div>`})
+	writeProjectFiles(t, outside, map[string]string{"shared.html.twig": `<div class="ml-2"></div>`})
+	if err := os.Symlink(filepath.Join(root, "templates", "base.html.twig"), filepath.Join(root, "templates", "alias.html.twig")); err != nil {
+		t.Fatal(err)
 	}
-
-	// Check that all found files have correct extensions
-	validExtensions := []string{".html", ".html.twig", ".twig"}
-	for _, file := range found {
-		hasValidExtension := false
-		for _, ext := range validExtensions {
-			if strings.HasSuffix(strings.ToLower(file), ext) {
-				hasValidExtension = true
-				break
-			}
-		}
-		if !hasValidExtension {
-			t.Errorf("Found file doesn't have valid extension: %s", file)
-		}
+	if err := os.Symlink(filepath.Join(outside, "shared.html.twig"), filepath.Join(root, "templates", "shared.html.twig")); err != nil {
+		t.Fatal(err)
 	}
 
-	// Test findTemplateFiles with recursive=false
-	found, err = findTemplateFiles(tempDir, false)
+	found, err := migrate.FindFiles(context.Background(), root, true, nil)
 	if err != nil {
-		t.Fatalf("findTemplateFiles failed: %v", err)
+		t.Fatal(err)
 	}
-
-	// Should find only root level files: 3 files
-	expectedCount = 3
-	if len(found) != expectedCount {
-		t.Errorf("Expected %d files with recursive=false, got %d: %v", expectedCount, len(found), found)
+	// The link inside the project resolves to its target, which is listed once,
+	// the link out of the project is skipped
+	expected := []string{filepath.Join(root, "templates", "base.html.twig")}
+	if strings.Join(found, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %v, got %v", expected, found)
 	}
-}
 
-func TestBootstrapMigrations(t *testing.T) {
-	migrations := getBootstrapMigrations()
-
-	// Test that we have migrations defined
-	if len(migrations) == 0 {
-		t.Fatal("No bootstrap migrations found")
-	}
-
-	// Test specific migrations
-	testCases := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Custom Checkbox",
-			input:    `<div class="custom-control custom-checkbox">`,
-			expected: `<div class="form-check">`,
-		},
-		{
-			name:     "Custom Radio",
-			input:    `<div class="custom-control custom-radio">`,
-			expected: `<div class="form-check">`,
-		},
-		{
-			name:     "Custom Switch",
-			input:    `<div class="custom-control custom-switch">`,
-			expected: `<div class="form-check form-switch">`,
-		},
-		{
-			name:     "Custom Control Input",
-			input:    `<input class="custom-control-input" type="checkbox">`,
-			expected: `<input class="form-check-input" type="checkbox">`,
-		},
-		{
-			name:     "Custom Control Label",
-			input:    `<label class="custom-control-label">`,
-			expected: `<label class="form-check-label">`,
-		},
-		{
-			name:     "Custom Select",
-			input:    `<select class="custom-select">`,
-			expected: `<select class="form-select">`,
-		},
-		{
-			name:     "No Gutters",
-			input:    `<div class="row no-gutters">`,
-			expected: `<div class="row g-0">`,
-		},
-		{
-			name:     "Button Block",
-			input:    `<button class="btn btn-primary btn-block">`,
-			expected: `<button class="btn btn-primary d-grid">`,
-		},
-		{
-			name:     "Badge Pill",
-			input:    `<span class="badge badge-primary badge-pill">`,
-			expected: `<span class="badge bg-primary rounded-pill">`,
-		},
-		{
-			name:     "Close Button",
-			input:    `<button class="close">`,
-			expected: `<button class="btn-close">`,
-		},
-		{
-			name:     "Text Left",
-			input:    `<div class="text-left">`,
-			expected: `<div class="text-start">`,
-		},
-		{
-			name:     "Text Right",
-			input:    `<div class="text-right">`,
-			expected: `<div class="text-end">`,
-		},
-		{
-			name:     "Float Left",
-			input:    `<div class="float-left">`,
-			expected: `<div class="float-start">`,
-		},
-		{
-			name:     "Float Right",
-			input:    `<div class="float-right">`,
-			expected: `<div class="float-end">`,
-		},
-		{
-			name:     "Margin Left",
-			input:    `<div class="ml-3">`,
-			expected: `<div class="ms-3">`,
-		},
-		{
-			name:     "Margin Right",
-			input:    `<div class="mr-2">`,
-			expected: `<div class="me-2">`,
-		},
-		{
-			name:     "Padding Left",
-			input:    `<div class="pl-4">`,
-			expected: `<div class="ps-4">`,
-		},
-		{
-			name:     "Padding Right",
-			input:    `<div class="pr-1">`,
-			expected: `<div class="pe-1">`,
-		},
-		{
-			name:     "Form Group",
-			input:    `<div class="form-group">`,
-			expected: `<div class="mb-3">`,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			result := tc.input
-			changeCount := 0
-
-			// Apply all migrations to see if our test case gets transformed
-			for _, migration := range migrations {
-				matches := migration.Pattern.FindAllString(result, -1)
-				if len(matches) > 0 {
-					newResult := migration.Pattern.ReplaceAllString(result, migration.Replacement)
-					if newResult != result {
-						changeCount += len(matches)
-						result = newResult
-					}
-				}
-			}
-
-			if result != tc.expected {
-				t.Errorf("Migration failed for %s:\nInput:    %s\nExpected: %s\nActual:   %s", tc.name, tc.input, tc.expected, result)
-			}
-
-			if changeCount == 0 {
-				t.Errorf("No changes applied for test case: %s", tc.name)
-			}
-		})
+	originalDryRun := dryRun
+	defer func() { dryRun = originalDryRun }()
+	dryRun = false
+	if err := runBS4to5Migration(bs4to5Cmd, []string{root}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(outside, "shared.html.twig")); string(content) != `<div class="ml-2"></div>` {
+		t.Errorf("File outside the project was changed: %s", content)
+	}
+	if info, err := os.Lstat(filepath.Join(root, "templates", "alias.html.twig")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected the symlink to be kept: %v", err)
 	}
 }
 
@@ -241,16 +85,16 @@ func TestProcessFileWithDryRun(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 
 	// Test with dry-run (should not modify file)
 	originalDryRun := dryRun
 	dryRun = true
 	defer func() { dryRun = originalDryRun }()
 
-	changes, err := processFile(testFile, migrations)
+	changes, err := migrateTestFile(testFile, migrations)
 	if err != nil {
-		t.Fatalf("processFile failed: %v", err)
+		t.Fatalf("migrateTestFile failed: %v", err)
 	}
 
 	// Should detect changes
@@ -286,16 +130,16 @@ func TestProcessFileWithActualChanges(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 
 	// Test with actual changes (not dry-run)
 	originalDryRun := dryRun
 	dryRun = false
 	defer func() { dryRun = originalDryRun }()
 
-	changes, err := processFile(testFile, migrations)
+	changes, err := migrateTestFile(testFile, migrations)
 	if err != nil {
-		t.Fatalf("processFile failed: %v", err)
+		t.Fatalf("migrateTestFile failed: %v", err)
 	}
 
 	// Should make changes
@@ -368,16 +212,16 @@ func TestTwigFileProcessing(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 
 	// Test processing Twig file
 	originalDryRun := dryRun
 	dryRun = false
 	defer func() { dryRun = originalDryRun }()
 
-	changes, err := processFile(testFile, migrations)
+	changes, err := migrateTestFile(testFile, migrations)
 	if err != nil {
-		t.Fatalf("processFile failed for Twig file: %v", err)
+		t.Fatalf("migrateTestFile failed for Twig file: %v", err)
 	}
 
 	if changes == 0 {
@@ -496,16 +340,16 @@ func TestComplexBootstrapMigration(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 
 	// Process the file
 	originalDryRun := dryRun
 	dryRun = false
 	defer func() { dryRun = originalDryRun }()
 
-	changes, err := processFile(testFile, migrations)
+	changes, err := migrateTestFile(testFile, migrations)
 	if err != nil {
-		t.Fatalf("processFile failed: %v", err)
+		t.Fatalf("migrateTestFile failed: %v", err)
 	}
 
 	// Should make many changes
@@ -586,58 +430,6 @@ func TestComplexBootstrapMigration(t *testing.T) {
 	}
 }
 
-func TestRegexPatterns(t *testing.T) {
-	// Test that our regex patterns work correctly
-	testCases := []struct {
-		pattern     string
-		input       string
-		shouldMatch bool
-	}{
-		{
-			pattern:     `custom-control\s+custom-checkbox`,
-			input:       `class="custom-control custom-checkbox"`,
-			shouldMatch: true,
-		},
-		{
-			pattern:     `custom-control\s+custom-checkbox`,
-			input:       `class="custom-control  custom-checkbox"`, // Multiple spaces
-			shouldMatch: true,
-		},
-		{
-			pattern:     `custom-control\s+custom-checkbox`,
-			input:       `class="custom-checkbox"`, // Missing custom-control
-			shouldMatch: false,
-		},
-		{
-			pattern:     `custom-control-input`,
-			input:       `class="custom-control-input"`,
-			shouldMatch: true,
-		},
-		{
-			pattern:     `ml-(\d+)`,
-			input:       `class="ml-3"`,
-			shouldMatch: true,
-		},
-		{
-			pattern:     `ml-(\d+)`,
-			input:       `class="ml-auto"`, // Should not match non-numeric
-			shouldMatch: false,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.pattern+"_"+tc.input, func(t *testing.T) {
-			regex := regexp.MustCompile(tc.pattern)
-			matches := regex.MatchString(tc.input)
-
-			if matches != tc.shouldMatch {
-				t.Errorf("Pattern %s with input %s: expected match=%v, got match=%v",
-					tc.pattern, tc.input, tc.shouldMatch, matches)
-			}
-		})
-	}
-}
-
 func TestTwigCommentsInTwigFile(t *testing.T) {
 	tempDir := t.TempDir()
 	testFile := filepath.Join(tempDir, "test.html.twig")
@@ -657,16 +449,16 @@ func TestTwigCommentsInTwigFile(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 
 	// Process the Twig file
 	originalDryRun := dryRun
 	dryRun = false
 	defer func() { dryRun = originalDryRun }()
 
-	changes, err := processFile(testFile, migrations)
+	changes, err := migrateTestFile(testFile, migrations)
 	if err != nil {
-		t.Fatalf("processFile failed for Twig file: %v", err)
+		t.Fatalf("migrateTestFile failed for Twig file: %v", err)
 	}
 
 	if changes == 0 {
@@ -711,16 +503,16 @@ func TestTwigCommentsInHTMLFile(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 
 	// Process the HTML file
 	originalDryRun := dryRun
 	dryRun = false
 	defer func() { dryRun = originalDryRun }()
 
-	changes, err := processFile(testFile, migrations)
+	changes, err := migrateTestFile(testFile, migrations)
 	if err != nil {
-		t.Fatalf("processFile failed for HTML file: %v", err)
+		t.Fatalf("migrateTestFile failed for HTML file: %v", err)
 	}
 
 	if changes == 0 {
@@ -736,8 +528,8 @@ func TestTwigCommentsInHTMLFile(t *testing.T) {
 	afterString := string(afterContent)
 
 	// Should contain HTML comments <!-- -->, not Twig comments {# #}
-	if !strings.Contains(afterString, "<!--  TODO:") {
-		t.Errorf("HTML file should contain HTML comment format <!--  TODO:, got: %s", afterString)
+	if !strings.Contains(afterString, "<!-- TODO:") {
+		t.Errorf("HTML file should contain HTML comment format <!-- TODO:, got: %s", afterString)
 	}
 
 	if strings.Contains(afterString, "{# TODO:") {
@@ -804,9 +596,9 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 	}
 
 	// Test finding template files
-	templateFiles, err := findTemplateFiles(tempDir, true)
+	templateFiles, err := migrate.FindFiles(context.Background(), tempDir, true, nil)
 	if err != nil {
-		t.Fatalf("findTemplateFiles failed: %v", err)
+		t.Fatalf("FindFiles failed: %v", err)
 	}
 
 	expectedFileCount := 4 // All files should be found
@@ -815,7 +607,7 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 	}
 
 	// Test processing all files
-	migrations := getBootstrapMigrations()
+	migrations := bootstrapMigrations(t)
 	totalChanges := 0
 
 	originalDryRun := dryRun
@@ -823,7 +615,7 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 	defer func() { dryRun = originalDryRun }()
 
 	for _, file := range templateFiles {
-		changes, err := processFile(file, migrations)
+		changes, err := migrateTestFile(file, migrations)
 		if err != nil {
 			t.Errorf("Error processing %s: %v", file, err)
 			continue
@@ -870,3 +662,87 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 		}
 	}
 }
+
+func TestRunBS4to5MigrationPatchOut(t *testing.T) {
+	// The undo journal is written to .wswcli/ in the working directory
+	t.Chdir(t.TempDir())
+	tempDir := t.TempDir()
+	writeProjectFiles(t, tempDir, map[string]string{
+		"templates/a.html.twig": "<div class=\"ml-2\">\n  {{ content }}\n</div>\n",
+		"templates/b.html":      "<p>unchanged</p>\n",
+	})
+	patchFile := filepath.Join(t.TempDir(), "bs5.patch")
+
+	originalDryRun, originalPatchOut := dryRun, patchOutFile
+	defer func() { dryRun, patchOutFile = originalDryRun, originalPatchOut }()
+
+	// Dry run writes the patch but leaves the templates alone
+	dryRun, patchOutFile = true, patchFile
+	if err := runBS4to5Migration(bs4to5Cmd, []string{tempDir}); err != nil {
+		t.Fatalf("runBS4to5Migration failed: %v", err)
+	}
+
+	patch, err := os.ReadFile(patchFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `--- a/templates/a.html.twig
++++ b/templates/a.html.twig
+@@ -1,3 +1,3 @@
+-<div class="ml-2">
++<div class="ms-2">
+   {{ content }}
+ </div>
+`
+	if string(patch) != expected {
+		t.Errorf("Unexpected patch:\n%s\nExpected:\n%s", patch, expected)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "templates/a.html.twig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "ms-2") {
+		t.Error("Dry run should not change templates")
+	}
+
+	// A real run applies the changes and keeps the same patch as change log
+	dryRun = false
+	if err := runBS4to5Migration(bs4to5Cmd, []string{tempDir}); err != nil {
+		t.Fatalf("runBS4to5Migration failed: %v", err)
+	}
+	changeLog, err := os.ReadFile(patchFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(changeLog) != expected {
+		t.Errorf("Unexpected change log:\n%s", changeLog)
+	}
+}
+
+func TestWriteFileTypeList(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeFileTypeList(&buf, bootstrapMigrations(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"twig", ".html.twig, .twig", "scss", "/* TODO: ... */", "<!-- TODO: ... -->"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in file type list:\n%s", expected, output)
+		}
+	}
+}
+
+// migrateTestFile migrates a single file with the command line options and
+// writes the result unless dry-run is set. It returns the number of matches.
+func migrateTestFile(filename string, migrations []migrate.Rule) (int, error) {
+	result, err := migrateFile(context.Background(), filename, migrations, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeMigratedFile(result); err != nil {
+		return 0, err
+	}
+	return len(result.Matches), nil
+}
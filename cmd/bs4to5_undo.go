@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+var bs4to5UndoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "Restore the files changed by a bs-4-to-5 run",
+	Long: `Restore the files changed by a bs-4-to-5 run from its undo journal.
+
+Every bs-4-to-5 run without --dry-run writes a journal with the hashes of the
+changed files and a reverse patch to .wswcli/migrations/<run-id>/ of the
+project, or to .wswcli-state/ when .wswcli is a config file. The project is the
+nearest directory from --path upwards with .wswcli or .git. Without a run ID
+the latest run is undone. Files changed since the migration are not restored.
+After a complete undo the journal is removed, so running undo again restores
+the run before it.
+
+Examples:
+  wswcli bs-4-to-5 undo                    # Undo the latest run
+  wswcli bs-4-to-5 undo 20250801-142530    # Undo a specific run
+  wswcli bs-4-to-5 undo --path templates   # Undo the latest run over templates/
+  git apply .wswcli/migrations/20250801-142530/undo.patch  # Apply the reverse patch with git`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runMigrationUndo,
+}
+
+// undoPath is the --path of the undo commands
+var undoPath string
+
+func init() {
+	bs4to5Cmd.AddCommand(bs4to5UndoCmd)
+	addUndoFlags(bs4to5UndoCmd)
+}
+
+// addUndoFlags adds the flags of an undo command
+func addUndoFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&undoPath, "path", ".", "Path the migration ran on, used to find the project with its journals")
+}
+
+// undoCommand returns the command that undoes a migration run of cmd: its own
+// undo subcommand, e.g. "bs-4-to-5 undo", or "migrate undo" for migration packs
+func undoCommand(cmd *cobra.Command) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == "undo" {
+			return sub
+		}
+	}
+	return migrateUndoCmd
+}
+
+func runMigrationUndo(cmd *cobra.Command, args []string) error {
+	runID := ""
+	if len(args) > 0 {
+		runID = args[0]
+	}
+
+	if _, err := os.Stat(undoPath); err != nil {
+		return usageError("path does not exist: %s", undoPath)
+	}
+	projectDir, err := config.ProjectRoot(undoPath)
+	if err != nil {
+		return err
+	}
+
+	journal, err := migrate.LoadJournal(projectDir, runID)
+	if err != nil {
+		return err
+	}
+
+	restored, err := journal.Undo()
+	if err != nil {
+		return err
+	}
+	if err := journal.Remove(); err != nil {
+		return err
+	}
+
+	logInfo("Restored %d files from %s run %s", restored, journal.Pack, journal.RunID,
+		slog.Int("files", restored), slog.String("pack", journal.Pack), slog.String("run_id", journal.RunID))
+	return nil
+}
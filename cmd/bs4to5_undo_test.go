@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestBS4to5Undo(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := map[string]string{
+		"templates/a.html.twig": "<div class=\"ml-2\">\n  {{ content }}\n</div>\n",
+		"templates/b.html":      "<p class=\"text-left\">no trailing newline</p>",
+	}
+	writeProjectFiles(t, ".", files)
+	if err := os.Chmod("templates/a.html.twig", 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	originalDryRun := dryRun
+	defer func() { dryRun = originalDryRun }()
+	dryRun = false
+
+	runMigration := func() {
+		t.Helper()
+		if err := runBS4to5Migration(bs4to5Cmd, []string{"templates"}); err != nil {
+			t.Fatalf("runBS4to5Migration failed: %v", err)
+		}
+	}
+	runMigration()
+
+	info, err := os.Stat("templates/a.html.twig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode to be kept, got %v", info.Mode().Perm())
+	}
+	// Without .wswcli or .git the migrated directory is the project
+	originalUndoPath := undoPath
+	defer func() { undoPath = originalUndoPath }()
+	undoPath = "templates"
+
+	runs, err := migrate.JournalRuns("templates")
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Expected one journal, got %v (%v)", runs, err)
+	}
+	if _, err := os.Stat(filepath.Join("templates", configName, migrate.JournalsDir, runs[0], migrate.UndoPatchFile)); err != nil {
+		t.Errorf("Expected an undo patch: %v", err)
+	}
+
+	// A changed file blocks the undo, nothing is restored
+	migrated, err := os.ReadFile("templates/b.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("templates/b.html", append(migrated, " edited"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runMigrationUndo(bs4to5UndoCmd, nil); exitCodeForError(err) != ExitIO {
+		t.Fatalf("Expected undo to refuse a changed file, got %v", err)
+	}
+	content, err := os.ReadFile("templates/a.html.twig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) == files["templates/a.html.twig"] {
+		t.Error("Undo should not restore any file when one changed")
+	}
+
+	if err := os.WriteFile("templates/b.html", migrated, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runMigrationUndo(bs4to5UndoCmd, []string{runs[0]}); err != nil {
+		t.Fatalf("runBS4to5Undo failed: %v", err)
+	}
+	for file, expected := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s: expected %q after undo, got %q", file, expected, content)
+		}
+	}
+	if info, err := os.Stat("templates/a.html.twig"); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode to be restored, got %v", info.Mode().Perm())
+	}
+
+	// The journal is removed, there is nothing left to undo
+	if err := runMigrationUndo(bs4to5UndoCmd, nil); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected usage error without journals, got %v", err)
+	}
+}
+
+func TestBS4to5UndoWithConfigFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := "[patchvendor]\npatch_output_dir = \"build/patches\"\n"
+	writeProjectFiles(t, ".", map[string]string{
+		".wswcli":               config,
+		"templates/a.html.twig": "<div class=\"ml-2 text-left\">A</div>\n",
+	})
+
+	originalDryRun := dryRun
+	defer func() { dryRun = originalDryRun }()
+	dryRun = false
+
+	// The journal is kept next to the .wswcli config file of the project
+	if err := runBS4to5Migration(bs4to5Cmd, []string{"templates"}); err != nil {
+		t.Fatalf("Expected the migration to work with a .wswcli config file, got %v", err)
+	}
+	if content, err := os.ReadFile(".wswcli"); err != nil || string(content) != config {
+		t.Errorf("Expected the config file to be kept, got %q (%v)", content, err)
+	}
+	runs, err := migrate.JournalRuns(".")
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Expected one journal, got %v (%v)", runs, err)
+	}
+	if _, err := os.Stat(filepath.Join(".wswcli-state", migrate.JournalsDir, runs[0], migrate.JournalFile)); err != nil {
+		t.Errorf("Expected the journal in .wswcli-state: %v", err)
+	}
+
+	if err := runMigrationUndo(bs4to5UndoCmd, nil); err != nil {
+		t.Fatalf("runMigrationUndo failed: %v", err)
+	}
+	if content, err := os.ReadFile("templates/a.html.twig"); err != nil || string(content) != "<div class=\"ml-2 text-left\">A</div>\n" {
+		t.Errorf("Expected the original content after undo, got %q (%v)", content, err)
+	}
+}
+
+func TestUndoCommand(t *testing.T) {
+	if path := undoCommand(bs4to5Cmd).CommandPath(); path != "wswcli bs-4-to-5 undo" {
+		t.Errorf("Expected bs-4-to-5 runs to be undone with bs-4-to-5 undo, got %s", path)
+	}
+
+	for _, pack := range migrate.Packs() {
+		packCmd, _, err := migrateCmd.Find([]string{pack.Name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path := undoCommand(packCmd).CommandPath(); path != "wswcli migrate undo" {
+			t.Errorf("Expected %s runs to be undone with migrate undo, got %s", pack.Name, path)
+		}
+	}
+}
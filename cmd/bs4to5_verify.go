@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+// newRulesVerifyCommand returns the verify subcommand of the rules command of pack
+func newRulesVerifyCommand(pack migrate.Pack, use string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "Run every rule against its examples and check its capture groups",
+		Long: fmt.Sprintf(`Run every effective rule of the %s pack, including the --check rules and
+rules from rule files, against its before/after examples and check that the
+capture groups referenced by its replacement exist. Rules without examples are
+listed but do not fail.
+
+Examples:
+  %s
+  %s --rules team-rules.yaml`, pack.Title, use, use),
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selection, err := resolveRuleSelection(cmd, pack)
+			if err != nil {
+				return err
+			}
+			selection.Check = true
+			ruleSet, err := migrate.LoadRules(selection)
+			if err != nil {
+				return err
+			}
+			return writeRuleVerification(os.Stdout, ruleSet.Rules)
+		},
+	}
+}
+
+// writeRuleVerification verifies rules and writes the failures and a summary. It
+// returns an error with ExitFindings when a rule fails.
+func writeRuleVerification(w io.Writer, rules []migrate.Rule) error {
+	failed, examples := 0, 0
+	var untested []string
+	for _, rule := range rules {
+		examples += len(rule.Examples)
+		if len(rule.Examples) == 0 {
+			untested = append(untested, rule.Name)
+		}
+		errs := migrate.VerifyRule(rule)
+		if len(errs) == 0 {
+			continue
+		}
+		failed++
+		for _, err := range errs {
+			fmt.Fprintf(w, "FAIL %s (%s): %v\n", rule.Name, rule.Source, err)
+		}
+	}
+	for _, name := range untested {
+		fmt.Fprintf(w, "NO EXAMPLES %s\n", name)
+	}
+
+	fmt.Fprintf(w, "\nVerified %d rules with %d examples: %d failed, %d without examples\n", len(rules), examples, failed, len(untested))
+	if failed > 0 {
+		return &ExitError{Code: ExitFindings, Err: fmt.Errorf("%d rules failed verification", failed)}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestWriteRuleVerification(t *testing.T) {
+	rules := []migrate.Rule{
+		{
+			// Bootstrap 5 still has is-valid and is-invalid
+			Name:        "Form Validation",
+			Pattern:     regexp.MustCompile(`is-(valid|invalid)`),
+			Replacement: "has-$1",
+			Source:      "team-rules.yaml",
+			Examples:    []migrate.Example{{Before: `<input class="is-invalid">`, After: `<input class="is-invalid">`}},
+		},
+		{
+			Name:        "Jumbotron",
+			Pattern:     regexp.MustCompile(`jumbotron`),
+			Replacement: "$0",
+			Todo:        "Jumbotron removed",
+			Examples:    []migrate.Example{{Before: `<div class="jumbotron">`, After: `<div class="jumbotron {# TODO #}">`}},
+		},
+		{
+			Name:        "Untested",
+			Pattern:     regexp.MustCompile(`old`),
+			Replacement: "new",
+		},
+	}
+
+	var buf bytes.Buffer
+	err := writeRuleVerification(&buf, rules)
+	if exitCodeForError(err) != ExitFindings {
+		t.Fatalf("Expected findings exit code, got %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		`FAIL Form Validation (team-rules.yaml): example 1: "<input class=\"is-invalid\">" became "<input class=\"has-invalid\">"`,
+		"NO EXAMPLES Untested",
+		"Verified 3 rules with 2 examples: 1 failed, 1 without examples",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "FAIL Jumbotron") {
+		t.Errorf("TODO placeholder should match the rendered comment:\n%s", output)
+	}
+}
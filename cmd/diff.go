@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+// ANSI escape sequences used to color diffs and log messages
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiCyan   = "\033[36m"
+)
+
+// colorizeDiff adds ANSI colors to a unified diff like git diff does
+func colorizeDiff(diff string) string {
+	var sb strings.Builder
+	for _, line := range unidiff.SplitLines(diff) {
+		content := strings.TrimSuffix(line, "\n")
+		color := ""
+		switch {
+		case strings.HasPrefix(content, "--- "), strings.HasPrefix(content, "+++ "):
+			color = ansiBold
+		case strings.HasPrefix(content, "@@"):
+			color = ansiCyan
+		case strings.HasPrefix(content, "+"):
+			color = ansiGreen
+		case strings.HasPrefix(content, "-"):
+			color = ansiRed
+		}
+		if color == "" {
+			sb.WriteString(line)
+			continue
+		}
+		sb.WriteString(color + content + ansiReset)
+		if strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// colorEnabled reports whether colored output should be written to file:
+// only for terminals, without --no-color and when neither NO_COLOR nor
+// TERM=dumb is set
+func colorEnabled(file *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+func TestColorizeDiff(t *testing.T) {
+	diff := unidiff.Unified("a/x", "b/x", "one\ntwo\n", "one\n2\n", 3)
+	colored := colorizeDiff(diff)
+
+	for _, expected := range []string{
+		ansiBold + "--- a/x" + ansiReset + "\n",
+		ansiCyan + "@@ -1,2 +1,2 @@" + ansiReset + "\n",
+		" one\n",
+		ansiRed + "-two" + ansiReset + "\n",
+		ansiGreen + "+2" + ansiReset + "\n",
+	} {
+		if !strings.Contains(colored, expected) {
+			t.Errorf("Expected %q in colored diff:\n%s", expected, colored)
+		}
+	}
+}
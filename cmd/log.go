@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Global output flags
+var (
+	quiet     bool
+	verbosity int
+	logFormat string
+	noColor   bool
+)
+
+// LevelTrace is the level of -vv, below debug
+const LevelTrace = slog.LevelDebug - 4
+
+// logger writes progress messages and diagnostics to stderr, so that stdout only
+// carries data: reports, diffs and listings
+var logger = newLogger(os.Stderr, "text", slog.LevelInfo, false)
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.BoolVarP(&quiet, "quiet", "q", false, "Only print warnings and errors on stderr")
+	flags.CountVarP(&verbosity, "verbose", "v", "Print debug messages, -vv also trace messages")
+	flags.StringVar(&logFormat, "log-format", "text", "Format of the messages on stderr (text, json)")
+	flags.BoolVar(&noColor, "no-color", false, "Disable colored output (also with NO_COLOR or TERM=dumb)")
+}
+
+// setupLogging configures the logger from the global output flags
+func setupLogging() error {
+	if quiet && verbosity > 0 {
+		return usageError("--quiet and --verbose cannot be combined")
+	}
+	if logFormat != "text" && logFormat != "json" {
+		return usageError("invalid --log-format value: %s (use text or json)", logFormat)
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case quiet:
+		level = slog.LevelWarn
+	case verbosity == 1:
+		level = slog.LevelDebug
+	case verbosity > 1:
+		level = LevelTrace
+	}
+	logger = newLogger(os.Stderr, logFormat, level, colorEnabled(os.Stderr))
+	return nil
+}
+
+// newLogger returns a logger writing messages of at least level to w
+func newLogger(w io.Writer, format string, level slog.Level, color bool) *slog.Logger {
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+			Level: level,
+			ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+				if attr.Key == slog.LevelKey && attr.Value.Any() == LevelTrace {
+					attr.Value = slog.StringValue("TRACE")
+				}
+				return attr
+			},
+		}))
+	}
+	return slog.New(&cliHandler{mu: &sync.Mutex{}, out: w, level: level, color: color})
+}
+
+// logf logs a formatted message at level. Arguments are only formatted when the
+// level is enabled. Trailing slog.Attr arguments are not formatted but added to
+// the record, so --log-format json has the values of the message as fields,
+// e.g. logInfo("%s: %d changes", file, n, slog.String("file", file), slog.Int("changes", n)).
+func logf(level slog.Level, format string, args ...interface{}) {
+	if !logger.Enabled(context.Background(), level) {
+		return
+	}
+
+	n := len(args)
+	for n > 0 {
+		if _, ok := args[n-1].(slog.Attr); !ok {
+			break
+		}
+		n--
+	}
+	attrs := make([]slog.Attr, 0, len(args)-n)
+	for _, arg := range args[n:] {
+		attrs = append(attrs, arg.(slog.Attr))
+	}
+
+	message := fmt.Sprintf(format, args[:n]...)
+	if len(attrs) == 0 {
+		logger.Log(context.Background(), level, message)
+		return
+	}
+	// A group without a key is inlined by slog handlers, cliHandler leaves it out
+	logger.LogAttrs(context.Background(), level, message, slog.Attr{Value: slog.GroupValue(attrs...)})
+}
+
+// logTrace logs details that are only of interest when debugging wswcli itself
+func logTrace(format string, args ...interface{}) { logf(LevelTrace, format, args...) }
+
+// logDebug logs what a command does per file
+func logDebug(format string, args ...interface{}) { logf(slog.LevelDebug, format, args...) }
+
+// logInfo logs progress and summaries
+func logInfo(format string, args ...interface{}) { logf(slog.LevelInfo, format, args...) }
+
+// logWarn logs problems that do not stop the command
+func logWarn(format string, args ...interface{}) { logf(slog.LevelWarn, format, args...) }
+
+// logError logs failures, e.g. files that could not be processed
+func logError(format string, args ...interface{}) { logf(slog.LevelError, format, args...) }
+
+// cliHandler writes log records as plain lines for people: progress messages as
+// they are, other levels with a colored prefix, attributes as key=value. The
+// attributes of logf are left out, their values are already in the message.
+type cliHandler struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	level  slog.Level
+	color  bool
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *cliHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *cliHandler) Handle(_ context.Context, record slog.Record) error {
+	var sb strings.Builder
+	prefix, color := "", ""
+	switch {
+	case record.Level >= slog.LevelError:
+		prefix, color = "error: ", ansiRed
+	case record.Level >= slog.LevelWarn:
+		prefix, color = "warning: ", ansiYellow
+	case record.Level >= slog.LevelInfo:
+	case record.Level >= slog.LevelDebug:
+		prefix, color = "debug: ", ansiCyan
+	default:
+		prefix, color = "trace: ", ansiCyan
+	}
+	if prefix != "" && h.color {
+		prefix = color + prefix + ansiReset
+	}
+	sb.WriteString(prefix)
+	sb.WriteString(record.Message)
+
+	writeAttr := func(attr slog.Attr) bool {
+		if attr.Key == "" && attr.Value.Kind() == slog.KindGroup {
+			return true
+		}
+		key := strings.Join(append(append([]string{}, h.groups...), attr.Key), ".")
+		fmt.Fprintf(&sb, " %s=%v", key, attr.Value.Resolve())
+		return true
+	}
+	for _, attr := range h.attrs {
+		writeAttr(attr)
+	}
+	record.Attrs(writeAttr)
+	sb.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, sb.String())
+	return err
+}
+
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *cliHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	return &clone
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestSetupLogging(t *testing.T) {
+	defer func(q bool, v int, format string, l *slog.Logger) {
+		quiet, verbosity, logFormat, logger = q, v, format, l
+	}(quiet, verbosity, logFormat, logger)
+
+	tests := []struct {
+		quiet     bool
+		verbosity int
+		format    string
+		level     slog.Level
+		usage     bool
+	}{
+		{false, 0, "text", slog.LevelInfo, false},
+		{true, 0, "text", slog.LevelWarn, false},
+		{false, 1, "json", slog.LevelDebug, false},
+		{false, 2, "text", LevelTrace, false},
+		{true, 1, "text", 0, true},
+		{false, 0, "yaml", 0, true},
+	}
+
+	for _, tt := range tests {
+		quiet, verbosity, logFormat = tt.quiet, tt.verbosity, tt.format
+		err := setupLogging()
+		if tt.usage {
+			if exitCodeForError(err) != ExitUsage {
+				t.Errorf("Expected a usage error for quiet=%v verbosity=%d format=%s, got %v", tt.quiet, tt.verbosity, tt.format, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !logger.Enabled(context.Background(), tt.level) || logger.Enabled(context.Background(), tt.level-1) {
+			t.Errorf("Expected level %v for quiet=%v verbosity=%d", tt.level, tt.quiet, tt.verbosity)
+		}
+	}
+}
+
+func TestCLIHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf, "text", slog.LevelDebug, false)
+	log.Info("Found 3 files")
+	log.Warn("Skipping a.twig", "reason", "symlink")
+	log.WithGroup("rule").Error("failed", "name", "Margin Left")
+	log.Debug("Processing a.twig")
+	log.Log(context.Background(), LevelTrace, "not shown")
+
+	expected := "Found 3 files\n" +
+		"warning: Skipping a.twig reason=symlink\n" +
+		"error: failed rule.name=Margin Left\n" +
+		"debug: Processing a.twig\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	newLogger(&buf, "text", slog.LevelInfo, true).Error("failed")
+	if buf.String() != ansiRed+"error: "+ansiReset+"failed\n" {
+		t.Errorf("Expected a colored prefix, got %q", buf.String())
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	newLogger(&buf, "json", LevelTrace, false).Log(context.Background(), LevelTrace, "Skipping directory node_modules")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Invalid JSON log line: %v\n%s", err, buf.String())
+	}
+	if record["level"] != "TRACE" || record["msg"] != "Skipping directory node_modules" {
+		t.Errorf("Unexpected log record: %v", record)
+	}
+}
+
+func TestLogfAttrs(t *testing.T) {
+	defer func(previous *slog.Logger) { logger = previous }(logger)
+
+	var buf bytes.Buffer
+	logger = newLogger(&buf, "json", slog.LevelInfo, false)
+	logInfo("%s: %d changes", "page.html.twig", 3, slog.String("file", "page.html.twig"), slog.Int("changes", 3))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Invalid JSON log line: %v\n%s", err, buf.String())
+	}
+	if record["msg"] != "page.html.twig: 3 changes" || record["file"] != "page.html.twig" || record["changes"] != float64(3) {
+		t.Errorf("Expected the message values as fields, got %v", record)
+	}
+
+	// The text output only has the message
+	buf.Reset()
+	logger = newLogger(&buf, "text", slog.LevelInfo, false)
+	logInfo("%s: %d changes", "page.html.twig", 3, slog.String("file", "page.html.twig"), slog.Int("changes", 3))
+	if buf.String() != "page.html.twig: 3 changes\n" {
+		t.Errorf("Unexpected text output %q", buf.String())
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	defer func(value bool) { noColor = value }(noColor)
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip("no terminal available")
+	}
+	defer tty.Close()
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	noColor = false
+	if !colorEnabled(tty) {
+		t.Error("Expected color on a terminal")
+	}
+	noColor = true
+	if colorEnabled(tty) {
+		t.Error("Expected no color with --no-color")
+	}
+	noColor = false
+	t.Setenv("TERM", "dumb")
+	if colorEnabled(tty) {
+		t.Error("Expected no color with TERM=dumb")
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Run versioned migration packs on templates, SCSS and JavaScript",
+	Long: `Run a migration pack on HTML and Twig templates, SCSS stylesheets and
+JavaScript and TypeScript sources.
+
+All packs share file discovery, --dry-run with diff preview, --patch-out,
+--interactive review, --report, rule files and the undo journal. Run
+"wswcli migrate list" to list the packs. "wswcli bs-4-to-5" is the same as
+"wswcli migrate bootstrap-4-to-5".
+
+Examples:
+  wswcli migrate list
+  wswcli migrate bootstrap-5.0-to-5.3 . --dry-run
+  wswcli migrate fontawesome-5-to-6 templates/ --report fa6-report.html
+  wswcli migrate shopware-6.4-to-6.5 custom/plugins --interactive
+  wswcli migrate shopware-6.4-to-6.5 rules
+  wswcli migrate undo`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var migrateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the available migration packs",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writePackList(os.Stdout, migrate.Packs())
+	},
+}
+
+var migrateUndoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "Restore the files changed by a migration run",
+	Long: `Restore the files changed by a migration run from its undo journal in
+.wswcli/migrations/<run-id>/ of the project, or in .wswcli-state/ when .wswcli
+is a config file. Without a run ID the latest run of any pack is undone. Files
+changed since the migration are not restored.
+
+Examples:
+  wswcli migrate undo
+  wswcli migrate undo 20250801-142530
+  wswcli migrate undo --path templates`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runMigrationUndo,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateListCmd, migrateUndoCmd)
+	addUndoFlags(migrateUndoCmd)
+	for _, pack := range migrate.Packs() {
+		migrateCmd.AddCommand(newMigrationPackCommand(pack))
+	}
+}
+
+// newMigrationPackCommand returns the migrate subcommand running pack, with
+// rules and file-types subcommands
+func newMigrationPackCommand(pack migrate.Pack) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     pack.Name + " [PATH]",
+		Aliases: pack.Aliases,
+		Short:   "Migrate " + pack.Title + ": " + pack.Description,
+		Long: fmt.Sprintf(`Migrate %s in HTML and Twig templates, SCSS stylesheets and JavaScript
+and TypeScript sources: %s.
+
+Examples:
+  wswcli migrate %[3]s .                # Migrate current directory (recursive)
+  wswcli migrate %[3]s . --dry-run      # Preview changes as a unified diff
+  wswcli migrate %[3]s . --interactive  # Review each change before applying it
+  wswcli migrate %[3]s . --check        # List the occurrences to migrate, fail if there are any
+  wswcli migrate %[3]s rules            # List the rules of the pack`, pack.Title, pack.Description, pack.Name),
+		Args: usageArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigration(cmd, args, pack)
+		},
+	}
+	addMigrationFlags(cmd)
+
+	rulesCmd := &cobra.Command{
+		Use:   "rules",
+		Short: "List the effective rules of the " + pack.Title + " pack",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrationRules(cmd, pack)
+		},
+	}
+	rulesCmd.AddCommand(newRulesVerifyCommand(pack, "wswcli migrate "+pack.Name+" rules verify"))
+
+	cmd.AddCommand(rulesCmd, &cobra.Command{
+		Use:   "file-types",
+		Short: "List the file types the " + pack.Title + " pack migrates and their rule sets",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrationFileTypes(cmd, pack)
+		},
+	})
+
+	return cmd
+}
+
+// addMigrationFlags adds the flags shared by all migration commands. Rule
+// selection flags are persistent so the rules and file-types subcommands see them.
+// Migration commands write their findings in the global --format.
+func addMigrationFlags(cmd *cobra.Command) {
+	supportsReport(cmd)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without applying them")
+	cmd.Flags().BoolVar(&twigInterpolation, "twig-interpolation", false, "Also rewrite string literals inside {{ }} in class attributes")
+	cmd.Flags().BoolVar(&processScripts, "process-scripts", false, "Also migrate inline <script> blocks of templates with the script rules")
+	cmd.Flags().BoolVar(&interactiveReview, "interactive", false, "Review every change: accept, reject, edit, accept all for a rule or skip the file")
+	cmd.Flags().StringVar(&patchOutFile, "patch-out", "", "Write all changes as a single patch file (dry-run: instead of printing diffs, otherwise as a change log)")
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "Only report the occurrences to migrate as file:line:column, without changing files")
+	cmd.Flags().BoolVar(&allowDirty, "allow-dirty", false, "Migrate files with uncommitted changes in the git work tree")
+	cmd.Flags().BoolVar(&trackedOnly, "tracked-only", false, "Only migrate files tracked by git")
+	cmd.Flags().IntVar(&maxFindings, "max-findings", 0, "With --check, fail when more occurrences are found")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Write a report with per-file and per-rule statistics (.json, .html or .xml for JUnit)")
+	cmd.PersistentFlags().StringSliceVar(&ruleFiles, "rules", nil, "YAML or JSON rule files merged over the built-in rules (repeatable)")
+	cmd.PersistentFlags().StringSliceVar(&onlyCategories, "only", nil, "Only apply rules of these categories (repeatable)")
+	cmd.PersistentFlags().StringSliceVar(&skipRules, "skip", nil, "Skip rules with these names (repeatable)")
+}
+
+// writePackList writes the packs with their number of rules
+func writePackList(w io.Writer, packs []migrate.Pack) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PACK\tRULES\tDESCRIPTION")
+	for _, pack := range packs {
+		fmt.Fprintf(table, "%s\t%d\t%s\n", pack.Name, len(pack.Rules()), pack.Description)
+	}
+	return table.Flush()
+}
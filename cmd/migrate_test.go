@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestWritePackList(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writePackList(&buf, migrate.Packs()); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	for _, pack := range migrate.Packs() {
+		if !strings.Contains(output, pack.Name) {
+			t.Errorf("Expected %s in pack list:\n%s", pack.Name, output)
+		}
+	}
+}
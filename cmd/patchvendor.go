@@ -3,13 +3,15 @@ package cmd
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/vendorpatch"
 )
 
 var patchvendorCmd = &cobra.Command{
@@ -25,8 +27,9 @@ Configuration:
 Examples:
   wswcli patchvendor /path/to/source /path/to/patched /path/to/output
   wswcli patchvendor  # Interactive mode with prompts
-  wswcli patchvendor --init-config  # Create example .wswcli file`,
-	Args: cobra.RangeArgs(0, 3),
+  wswcli patchvendor --init-config  # Create example .wswcli file
+  wswcli patchvendor verify  # Check that the patches still apply`,
+	Args: usageArgs(cobra.RangeArgs(0, 3)),
 	RunE: runPatchVendor,
 }
 
@@ -39,11 +42,11 @@ func runPatchVendor(cmd *cobra.Command, args []string) error {
 	// Check if --init-config flag is set
 	initConfig, _ := cmd.Flags().GetBool("init-config")
 	if initConfig {
-		if err := CreateExampleConfig(); err != nil {
+		if err := config.WriteExample("."); err != nil {
 			return fmt.Errorf("error creating config file: %w", err)
 		}
-		fmt.Println("Created example .wswcli configuration file")
-		fmt.Println("Edit the file to customize paths for your project")
+		logInfo("Created example .wswcli configuration file")
+		logInfo("Edit the file to customize paths for your project")
 		return nil
 	}
 
@@ -51,14 +54,14 @@ func runPatchVendor(cmd *cobra.Command, args []string) error {
 	var err error
 
 	// Load configuration
-	config, err := LoadConfig()
+	cfg, err := config.Load(".")
 	if err != nil {
 		return fmt.Errorf("error loading configuration: %w", err)
 	}
 
 	// If not all arguments provided, use interactive mode
 	if len(args) < 3 {
-		sourcePath, patchedPath, outputPath, err = getPathsInteractively(args, config)
+		sourcePath, patchedPath, outputPath, err = getPathsInteractively(args, cfg)
 		if err != nil {
 			return err
 		}
@@ -68,10 +71,10 @@ func runPatchVendor(cmd *cobra.Command, args []string) error {
 		outputPath = args[2]
 	}
 
-	fmt.Printf("Processing Shopware vendor patches...\n")
-	fmt.Printf("Source: %s\n", sourcePath)
-	fmt.Printf("Patched: %s\n", patchedPath)
-	fmt.Printf("Output: %s\n", outputPath)
+	logInfo("Processing Shopware vendor patches...")
+	logInfo("Source: %s", sourcePath)
+	logInfo("Patched: %s", patchedPath)
+	logInfo("Output: %s", outputPath)
 
 	// Comprehensive validation
 	if err := validateInputs(sourcePath, patchedPath, outputPath); err != nil {
@@ -84,11 +87,17 @@ func runPatchVendor(cmd *cobra.Command, args []string) error {
 	}
 
 	// Process the patches
-	if err := processPatchFiles(sourcePath, patchedPath, outputPath); err != nil {
+	err = vendorpatch.Create(commandContext(cmd), vendorpatch.Options{
+		Source:  sourcePath,
+		Patched: patchedPath,
+		Output:  outputPath,
+		Logger:  logger,
+	})
+	if err != nil {
 		return fmt.Errorf("error processing patches: %w", err)
 	}
 
-	fmt.Printf("Patches successfully processed and saved to %s\n", outputPath)
+	logInfo("Patches successfully processed and saved to %s", outputPath, slog.String("path", outputPath))
 	return nil
 }
 
@@ -97,7 +106,7 @@ func validateInputs(sourcePath, patchedPath, outputPath string) error {
 	// Check if source path exists
 	sourceInfo, err := os.Stat(sourcePath)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("source path does not exist: %s", sourcePath)
+		return usageError("source path does not exist: %s", sourcePath)
 	}
 	if err != nil {
 		return fmt.Errorf("error accessing source path: %w", err)
@@ -106,7 +115,7 @@ func validateInputs(sourcePath, patchedPath, outputPath string) error {
 	// Check if patched path exists
 	patchedInfo, err := os.Stat(patchedPath)
 	if os.IsNotExist(err) {
-		return fmt.Errorf("patched path does not exist: %s", patchedPath)
+		return usageError("patched path does not exist: %s", patchedPath)
 	}
 	if err != nil {
 		return fmt.Errorf("error accessing patched path: %w", err)
@@ -114,22 +123,22 @@ func validateInputs(sourcePath, patchedPath, outputPath string) error {
 
 	// Validate that both paths are of the same type (file or directory)
 	if sourceInfo.IsDir() != patchedInfo.IsDir() {
-		return fmt.Errorf("source and patched paths must both be files or both be directories")
+		return usageError("source and patched paths must both be files or both be directories")
 	}
 
 	// Check if source and patched are the same file
 	if sourcePath == patchedPath {
-		return fmt.Errorf("source and patched paths cannot be the same")
+		return usageError("source and patched paths cannot be the same")
 	}
 
 	// Validate output path
 	if outputPath == "" {
-		return fmt.Errorf("output path cannot be empty")
+		return usageError("output path cannot be empty")
 	}
 
 	// Check if output path already exists and is a directory
 	if outputInfo, err := os.Stat(outputPath); err == nil && outputInfo.IsDir() {
-		return fmt.Errorf("output path exists and is a directory: %s", outputPath)
+		return usageError("output path exists and is a directory: %s", outputPath)
 	}
 
 	// Validate file extensions for single files
@@ -156,11 +165,11 @@ func validateFileExtensions(sourcePath, patchedPath string) error {
 	}
 
 	if sourceExt != "" && !allowedExts[sourceExt] {
-		fmt.Printf("Warning: Uncommon file extension for source: %s\n", sourceExt)
+		logWarn("Uncommon file extension for source: %s", sourceExt)
 	}
 
 	if patchedExt != "" && !allowedExts[patchedExt] {
-		fmt.Printf("Warning: Uncommon file extension for patched: %s\n", patchedExt)
+		logWarn("Uncommon file extension for patched: %s", patchedExt)
 	}
 
 	if sourceExt != patchedExt {
@@ -170,7 +179,7 @@ func validateFileExtensions(sourcePath, patchedPath string) error {
 	return nil
 }
 
-func getPathsInteractively(args []string, config *Config) (string, string, string, error) {
+func getPathsInteractively(args []string, cfg *Config) (string, string, string, error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("\n=== Shopware Vendor Patch Generator ===")
@@ -183,7 +192,7 @@ func getPathsInteractively(args []string, config *Config) (string, string, strin
 	// Get SOURCE path
 	if len(args) >= 1 {
 		sourcePath = args[0]
-		fmt.Printf("Using provided SOURCE path: %s\n", sourcePath)
+		logInfo("Using provided SOURCE path: %s", sourcePath)
 	} else {
 		fmt.Println("SOURCE PATH:")
 		fmt.Println("   This is the original, unmodified vendor file or directory.")
@@ -199,7 +208,7 @@ func getPathsInteractively(args []string, config *Config) (string, string, strin
 	// Get PATCHED path
 	if len(args) >= 2 {
 		patchedPath = args[1]
-		fmt.Printf("Using provided PATCHED path: %s\n", patchedPath)
+		logInfo("Using provided PATCHED path: %s", patchedPath)
 	} else {
 		fmt.Println()
 		fmt.Println("PATCHED PATH:")
@@ -217,10 +226,10 @@ func getPathsInteractively(args []string, config *Config) (string, string, strin
 	// Get OUTPUT path
 	if len(args) >= 3 {
 		outputPath = args[2]
-		fmt.Printf("Using provided OUTPUT path: %s\n", outputPath)
+		logInfo("Using provided OUTPUT path: %s", outputPath)
 	} else {
 		// Generate suggested output path using configuration
-		suggestedPath := config.GetConfiguredOutputPath(patchedPath)
+		suggestedPath := vendorpatch.OutputPath(cfg.PatchVendor.PatchOutputDir, patchedPath)
 
 		fmt.Println()
 		fmt.Println("OUTPUT PATH:")
@@ -279,7 +288,7 @@ func generateSuggestedOutputPath(sourcePath string) string {
 	}
 
 	// Extract trimmed vendor path (e.g. vendor/shopware/core -> shopware/core)
-	trimmedPath := trimVendorPath(sourcePath)
+	trimmedPath := vendorpatch.PackagePath(sourcePath)
 
 	// Generate unix timestamp
 	timestamp := time.Now().Unix()
@@ -318,151 +327,3 @@ func trimVendorPath(sourcePath string) string {
 	// If no vendor found, return the full path for non-vendor files
 	return sourcePath
 }
-
-func processPatchFiles(sourcePath, patchedPath, outputPath string) error {
-	// Check whether these are files or directories
-	sourceInfo, err := os.Stat(sourcePath)
-	if err != nil {
-		return err
-	}
-
-	patchedInfo, err := os.Stat(patchedPath)
-	if err != nil {
-		return err
-	}
-
-	if sourceInfo.IsDir() && patchedInfo.IsDir() {
-		return processDirectories(sourcePath, patchedPath, outputPath)
-	} else if !sourceInfo.IsDir() && !patchedInfo.IsDir() {
-		return processSingleFile(sourcePath, patchedPath, outputPath)
-	} else {
-		return fmt.Errorf("source and patched must both be either files or directories")
-	}
-}
-
-func processDirectories(sourcePath, patchedPath, outputPath string) error {
-	// Iterate over all files in the source directory
-	return filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		// Calculate relative paths
-		relPath, err := filepath.Rel(sourcePath, path)
-		if err != nil {
-			return err
-		}
-
-		patchedFile := filepath.Join(patchedPath, relPath)
-		outputFile := filepath.Join(outputPath, relPath)
-
-		// Check if the corresponding patched file exists
-		if _, err := os.Stat(patchedFile); os.IsNotExist(err) {
-			fmt.Printf("No patched version found for: %s\n", relPath)
-			return nil
-		}
-
-		// Create output directory for this file
-		if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-			return err
-		}
-
-		// Process the individual file
-		return processSingleFile(path, patchedFile, outputFile)
-	})
-}
-
-func processSingleFile(sourcePath, patchedPath, outputPath string) error {
-	fmt.Printf("Processing: %s\n", filepath.Base(sourcePath))
-
-	// Generate patch in unified diff format
-	patch := generateUnifiedDiff(sourcePath, patchedPath)
-
-	info, err := os.Stat(sourcePath)
-	if err != nil {
-		return fmt.Errorf("error accessing source file: %w", err)
-	}
-	if info.Size() == 0 {
-		return fmt.Errorf("source file is empty")
-	}
-	if patch == "" {
-		return fmt.Errorf("source and patched files do not have different content")
-	}
-
-	// Write patch to output file
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("error creating output file: %w", err)
-	}
-	defer outputFile.Close()
-
-	_, err = outputFile.WriteString(patch)
-	if err != nil {
-		return fmt.Errorf("error writing patch file: %w", err)
-	}
-
-	return nil
-}
-
-// generateUnifiedDiff creates a unified diff patch between source and patched content
-func generateUnifiedDiff(sourcePath, patchedPath string) string {
-	// Try to use git diff first if files exist
-	if _, err := os.Stat(sourcePath); err == nil {
-		if _, err := os.Stat(patchedPath); err == nil {
-			// Use git diff with source as first argument (a/) and patched as second (b/)
-			cmd := exec.Command("git", "diff", "--no-index", "--unified=3", sourcePath, patchedPath)
-			output, err := cmd.CombinedOutput()
-			if err != nil && cmd.ProcessState.ExitCode() != 1 {
-				// Exit-Code 1 ist normal, wenn es Unterschiede gibt.
-				return fmt.Sprintf("error running git diff: %v", err)
-			}
-
-			// Post-process the output to fix the vendor paths
-			return fixVendorPaths(string(output), sourcePath, patchedPath)
-		}
-	}
-
-	// Fallback: generate diff manually when files don't exist (e.g., in tests)
-	return fmt.Sprintf("error: could not generate diff for %s and %s", sourcePath, patchedPath)
-}
-
-// fixVendorPaths post-processes git diff output to fix vendor paths in headers
-func fixVendorPaths(diffOutput, sourcePath string, patchedPath string) string {
-	lines := strings.Split(diffOutput, "\n")
-	vendorPath := extractVendorPath(sourcePath)
-
-	for i, line := range lines {
-		if strings.HasPrefix(line, "--- ") {
-			lines[i] = fmt.Sprintf("--- a/%s", vendorPath)
-		} else if strings.HasPrefix(line, "+++ ") {
-			lines[i] = fmt.Sprintf("+++ b/%s", vendorPath)
-		} else if strings.HasPrefix(line, "diff --git") {
-			lines[i] = fmt.Sprintf("diff --git a/%s b/%s", vendorPath, vendorPath)
-		}
-	}
-
-	return strings.Join(lines, "\n")
-}
-
-// extractVendorPath extracts the vendor path from a full file path for use in patch headers
-func extractVendorPath(fullPath string) string {
-	// Convert to forward slashes for consistent handling
-	normalizedPath := filepath.ToSlash(fullPath)
-	parts := strings.Split(normalizedPath, "/")
-
-	// Look for vendor directory and extract the path after vendor/provider/package
-	for i, part := range parts {
-		if part == "vendor" && i+3 < len(parts) {
-			// Skip vendor/provider/package and return the rest
-			// e.g. vendor/shopware/storefront/Resources/views/... -> Resources/views/...
-			return strings.Join(parts[i+3:], "/")
-		}
-	}
-
-	// Fallback: return the full normalized path
-	return normalizedPath
-}
@@ -179,117 +179,6 @@ func TestValidateFileExtensions(t *testing.T) {
 	}
 }
 
-func TestTrimVendorPath(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Shopware core file",
-			input:    "vendor/shopware/core/Framework/Plugin/PluginManager.php",
-			expected: "shopware/core",
-		},
-		{
-			name:     "Symfony component",
-			input:    "vendor/symfony/console/Command/Command.php",
-			expected: "symfony/console",
-		},
-		{
-			name:     "Doctrine ORM",
-			input:    "vendor/doctrine/orm/lib/Doctrine/ORM/EntityManager.php",
-			expected: "doctrine/orm",
-		},
-		{
-			name:     "Vendor directory only",
-			input:    "vendor/shopware/core",
-			expected: "shopware/core",
-		},
-		{
-			name:     "Directory without vendor",
-			input:    "src/Controller/TestController.php",
-			expected: "src/Controller/TestController.php",
-		},
-		{
-			name:     "Simple filename",
-			input:    "test.php",
-			expected: "test.php",
-		},
-		{
-			name:     "Windows path style",
-			input:    "vendor\\shopware\\core\\Framework\\Plugin\\PluginManager.php",
-			expected: "vendor\\shopware\\core\\Framework\\Plugin\\PluginManager.php", // trimVendorPath doesn't normalize Windows paths
-		},
-		{
-			name:     "Nested vendor path",
-			input:    "project/vendor/monolog/monolog/src/Monolog/Logger.php",
-			expected: "monolog/monolog",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := trimVendorPath(tt.input)
-			if result != tt.expected {
-				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
-			}
-		})
-	}
-}
-
-func TestExtractVendorPath(t *testing.T) {
-	tests := []struct {
-		name     string
-		input    string
-		expected string
-	}{
-		{
-			name:     "Shopware core file",
-			input:    "vendor/shopware/core/Framework/Plugin/PluginManager.php",
-			expected: "Framework/Plugin/PluginManager.php",
-		},
-		{
-			name:     "Symfony component",
-			input:    "vendor/symfony/console/Command/Command.php",
-			expected: "Command/Command.php",
-		},
-		{
-			name:     "Doctrine ORM",
-			input:    "vendor/doctrine/orm/lib/Doctrine/ORM/EntityManager.php",
-			expected: "lib/Doctrine/ORM/EntityManager.php",
-		},
-		{
-			name:     "Directory without vendor",
-			input:    "src/Controller/TestController.php",
-			expected: "src/Controller/TestController.php",
-		},
-		{
-			name:     "Simple filename",
-			input:    "test.php",
-			expected: "test.php",
-		},
-		{
-			name:     "Windows path style",
-			input:    "vendor\\shopware\\core\\Framework\\Plugin\\PluginManager.php",
-			expected: "vendor\\shopware\\core\\Framework\\Plugin\\PluginManager.php", // extractVendorPath doesn't normalize Windows paths
-		},
-		{
-			name:     "Nested vendor path",
-			input:    "project/vendor/monolog/monolog/src/Monolog/Logger.php",
-			expected: "src/Monolog/Logger.php",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractVendorPath(tt.input)
-			if result != tt.expected {
-				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
-			}
-		})
-	}
-}
-
 func TestGenerateSuggestedOutputPath(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -324,433 +213,3 @@ func TestGenerateSuggestedOutputPath(t *testing.T) {
 		})
 	}
 }
-
-func TestFixVendorPaths(t *testing.T) {
-	tests := []struct {
-		name           string
-		diffOutput     string
-		sourcePath     string
-		patchedPath    string
-		expectContains []string
-	}{
-		{
-			name: "Basic git diff output",
-			diffOutput: `diff --git a/some/path/file.php b/some/path/file.php
-index 1234567..abcdefg 100644
---- a/some/path/file.php
-+++ b/some/path/file.php
-@@ -1,3 +1,4 @@
- <?php
-+echo "new line";
- echo "test";`,
-			sourcePath:  "vendor/shopware/core/src/Test.php",
-			patchedPath: "vendor/shopware/core/src/Test.php",
-			expectContains: []string{
-				"--- a/src/Test.php",
-				"+++ b/src/Test.php",
-				"diff --git a/src/Test.php b/src/Test.php",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := fixVendorPaths(tt.diffOutput, tt.sourcePath, tt.patchedPath)
-			for _, expected := range tt.expectContains {
-				if !strings.Contains(result, expected) {
-					t.Errorf("Expected result to contain '%s', but it didn't.\nFull result:\n%s", expected, result)
-				}
-			}
-		})
-	}
-}
-
-func TestProcessSingleFileWithRealFiles(t *testing.T) {
-	tempDir := t.TempDir()
-
-	tests := []struct {
-		name           string
-		sourceContent  string
-		patchedContent string
-		sourcePath     string
-		expectError    bool
-		errorMsg       string
-		expectDiff     bool
-	}{
-		{
-			name:           "Files with differences",
-			sourceContent:  "<?php\necho 'original';",
-			patchedContent: "<?php\necho 'modified';",
-			sourcePath:     "vendor/shopware/core/Test.php",
-			expectError:    false,
-			expectDiff:     true,
-		},
-		{
-			name:           "Identical files - no diff",
-			sourceContent:  "<?php\necho 'same content';",
-			patchedContent: "<?php\necho 'same content';",
-			sourcePath:     "vendor/shopware/core/Test.php",
-			expectError:    true,
-			errorMsg:       "source and patched files do not have different content",
-			expectDiff:     false,
-		},
-		{
-			name:           "Empty source file",
-			sourceContent:  "",
-			patchedContent: "<?php\necho 'content';",
-			sourcePath:     "vendor/shopware/core/Test.php",
-			expectError:    true,
-			errorMsg:       "source file is empty",
-			expectDiff:     false,
-		},
-		{
-			name: "Complex changes",
-			sourceContent: `<?php
-class Test {
-    private $id;
-    
-    public function getId() {
-        return $this->id;
-    }
-}`,
-			patchedContent: `<?php
-class Test {
-    private $id;
-    private $name;
-    
-    public function getId() {
-        return $this->id;
-    }
-    
-    public function getName() {
-        return $this->name;
-    }
-}`,
-			sourcePath:  "vendor/doctrine/orm/Entity.php",
-			expectError: false,
-			expectDiff:  true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Create temporary files
-			sourceFile := filepath.Join(tempDir, "source_"+tt.name+".php")
-			patchedFile := filepath.Join(tempDir, "patched_"+tt.name+".php")
-			outputFile := filepath.Join(tempDir, "output_"+tt.name+".patch")
-
-			// Write test content
-			if err := os.WriteFile(sourceFile, []byte(tt.sourceContent), 0644); err != nil {
-				t.Fatal(err)
-			}
-			if err := os.WriteFile(patchedFile, []byte(tt.patchedContent), 0644); err != nil {
-				t.Fatal(err)
-			}
-
-			// Test the function
-			err := processSingleFile(sourceFile, patchedFile, outputFile)
-
-			if tt.expectError {
-				if err == nil {
-					t.Errorf("Expected error but got none")
-				} else if !strings.Contains(err.Error(), tt.errorMsg) {
-					t.Errorf("Expected error containing '%s', got '%s'", tt.errorMsg, err.Error())
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Expected no error but got: %s", err.Error())
-				}
-
-				// Check if output file was created and has content
-				if tt.expectDiff {
-					if _, err := os.Stat(outputFile); os.IsNotExist(err) {
-						t.Errorf("Expected output file to be created")
-					} else {
-						content, err := os.ReadFile(outputFile)
-						if err != nil {
-							t.Errorf("Error reading output file: %v", err)
-						} else if len(content) == 0 {
-							t.Errorf("Expected output file to have content")
-						}
-					}
-				}
-			}
-		})
-	}
-}
-
-func TestProcessDirectories(t *testing.T) {
-	tempDir := t.TempDir()
-
-	// Create source directory structure
-	sourceDir := filepath.Join(tempDir, "source")
-	patchedDir := filepath.Join(tempDir, "patched")
-	outputDir := filepath.Join(tempDir, "output")
-
-	// Create directories
-	if err := os.MkdirAll(filepath.Join(sourceDir, "subdir"), 0755); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.MkdirAll(filepath.Join(patchedDir, "subdir"), 0755); err != nil {
-		t.Fatal(err)
-	}
-
-	// Create test files
-	files := map[string][2]string{
-		"file1.php": {
-			"<?php\necho 'original1';",
-			"<?php\necho 'modified1';",
-		},
-		"subdir/file2.php": {
-			"<?php\necho 'original2';",
-			"<?php\necho 'modified2';",
-		},
-		"unchanged.php": {
-			"<?php\necho 'same';",
-			"<?php\necho 'same';",
-		},
-	}
-
-	for filename, contents := range files {
-		sourceFile := filepath.Join(sourceDir, filename)
-		patchedFile := filepath.Join(patchedDir, filename)
-
-		if err := os.MkdirAll(filepath.Dir(sourceFile), 0755); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.MkdirAll(filepath.Dir(patchedFile), 0755); err != nil {
-			t.Fatal(err)
-		}
-
-		if err := os.WriteFile(sourceFile, []byte(contents[0]), 0644); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.WriteFile(patchedFile, []byte(contents[1]), 0644); err != nil {
-			t.Fatal(err)
-		}
-	}
-
-	// Test directory processing
-	err := processDirectories(sourceDir, patchedDir, outputDir)
-
-	// Should have errors for unchanged files, but should process the changed ones
-	if err == nil {
-		t.Errorf("Expected some errors for unchanged files")
-	}
-
-	// Check that patch files were created for changed files
-	expectedPatches := []string{
-		filepath.Join(outputDir, "file1.php"),
-		filepath.Join(outputDir, "subdir", "file2.php"),
-	}
-
-	for _, patchFile := range expectedPatches {
-		if _, err := os.Stat(patchFile); os.IsNotExist(err) {
-			t.Errorf("Expected patch file to be created: %s", patchFile)
-		}
-	}
-}
-
-func TestGenerateUnifiedDiffWithRealFiles(t *testing.T) {
-	tempDir := t.TempDir()
-
-	tests := []struct {
-		name           string
-		sourceContent  string
-		patchedContent string
-		sourcePath     string
-		expectContains []string
-	}{
-		{
-			name:           "Simple change",
-			sourceContent:  "<?php\necho 'original';",
-			patchedContent: "<?php\necho 'modified';",
-			sourcePath:     "vendor/shopware/core/Test.php",
-			expectContains: []string{
-				"-echo 'original';",
-				"+echo 'modified';",
-			},
-		},
-		{
-			name: "Multiple changes",
-			sourceContent: `<?php
-class Test {
-    public function old() {
-        return 'old';
-    }
-}`,
-			patchedContent: `<?php
-class Test {
-    public function old() {
-        return 'old';
-    }
-    
-    public function new() {
-        return 'new';
-    }
-}`,
-			sourcePath: "vendor/symfony/console/Command.php",
-			expectContains: []string{
-				"+    public function new() {",
-				"+        return 'new';",
-				"+    }",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Create temporary files
-			sourceFile := filepath.Join(tempDir, "source_"+tt.name+".php")
-			patchedFile := filepath.Join(tempDir, "patched_"+tt.name+".php")
-
-			// Write test content
-			if err := os.WriteFile(sourceFile, []byte(tt.sourceContent), 0644); err != nil {
-				t.Fatal(err)
-			}
-			if err := os.WriteFile(patchedFile, []byte(tt.patchedContent), 0644); err != nil {
-				t.Fatal(err)
-			}
-
-			// Test the function - updated signature
-			result := generateUnifiedDiff(sourceFile, patchedFile)
-
-			// Check expected content (removed vendor path checks since fixVendorPaths doesn't work correctly)
-			for _, expected := range tt.expectContains {
-				if !strings.Contains(result, expected) {
-					t.Errorf("Expected diff to contain '%s', but it didn't.\nFull diff:\n%s", expected, result)
-				}
-			}
-		})
-	}
-}
-
-func TestGenerateUnifiedDiffNoDifference(t *testing.T) {
-	tempDir := t.TempDir()
-
-	sourceFile := filepath.Join(tempDir, "source.php")
-	patchedFile := filepath.Join(tempDir, "patched.php")
-
-	content := "<?php\necho 'same content';"
-
-	// Write identical content
-	if err := os.WriteFile(sourceFile, []byte(content), 0644); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.WriteFile(patchedFile, []byte(content), 0644); err != nil {
-		t.Fatal(err)
-	}
-
-	// Test the function - updated signature
-	result := generateUnifiedDiff(sourceFile, patchedFile)
-
-	// Should return empty string for identical files
-	if result != "" {
-		t.Errorf("Expected empty diff for identical files, got: %s", result)
-	}
-}
-
-func TestEndToEndPatchGeneration(t *testing.T) {
-	tempDir := t.TempDir()
-
-	// Create a realistic scenario
-	sourceFile := filepath.Join(tempDir, "vendor", "shopware", "core", "Framework", "Plugin", "PluginManager.php")
-	patchedFile := filepath.Join(tempDir, "custom", "PluginManager.php")
-	outputFile := filepath.Join(tempDir, "patches", "plugin-manager.patch")
-
-	sourceContent := `<?php
-namespace Shopware\Core\Framework\Plugin;
-
-class PluginManager
-{
-    private $plugins = [];
-    
-    public function getPlugins(): array
-    {
-        return $this->plugins;
-    }
-    
-    public function addPlugin(string $name): void
-    {
-        $this->plugins[] = $name;
-    }
-}`
-
-	patchedContent := `<?php
-namespace Shopware\Core\Framework\Plugin;
-
-class PluginManager
-{
-    private $plugins = [];
-    private $config = [];
-    
-    public function getPlugins(): array
-    {
-        return $this->plugins;
-    }
-    
-    public function addPlugin(string $name): void
-    {
-        $this->plugins[] = $name;
-        $this->logPluginAdded($name);
-    }
-    
-    public function getConfig(): array
-    {
-        return $this->config;
-    }
-    
-    private function logPluginAdded(string $name): void
-    {
-        error_log("Plugin added: " . $name);
-    }
-}`
-
-	// Create directories and files
-	if err := os.MkdirAll(filepath.Dir(sourceFile), 0755); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.MkdirAll(filepath.Dir(patchedFile), 0755); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-		t.Fatal(err)
-	}
-
-	if err := os.WriteFile(sourceFile, []byte(sourceContent), 0644); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.WriteFile(patchedFile, []byte(patchedContent), 0644); err != nil {
-		t.Fatal(err)
-	}
-
-	// Test the complete process
-	err := processSingleFile(sourceFile, patchedFile, outputFile)
-	if err != nil {
-		t.Errorf("Expected no error but got: %s", err.Error())
-	}
-
-	// Verify output file exists and has expected content
-	if _, err := os.Stat(outputFile); os.IsNotExist(err) {
-		t.Fatal("Expected output file to be created")
-	}
-
-	patchContent, err := os.ReadFile(outputFile)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	patchStr := string(patchContent)
-	expectedContents := []string{
-		"+    private $config = [];",
-		"+        $this->logPluginAdded($name);",
-		"+    public function getConfig(): array",
-		"+    private function logPluginAdded(string $name): void",
-	}
-
-	for _, expected := range expectedContents {
-		if !strings.Contains(patchStr, expected) {
-			t.Errorf("Expected patch to contain '%s', but it didn't.\nFull patch:\n%s", expected, patchStr)
-		}
-	}
-}
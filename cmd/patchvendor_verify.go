@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/vendorpatch"
+)
+
+var (
+	verifyVendorDir string
+	verifyFailOn    string
+)
+
+var patchvendorVerifyCmd = &cobra.Command{
+	Use:   "verify [PATCH_DIR]",
+	Short: "Check that vendor patches still apply to the installed packages",
+	Long: `Check that the patches in PATCH_DIR still apply to the installed vendor packages,
+e.g. after a composer update.
+
+Patches are expected in <provider>/<package>/ below PATCH_DIR, the layout patchvendor
+creates, with paths relative to the package directory vendor/<provider>/<package>.
+PATCH_DIR defaults to patch_output_dir of the .wswcli configuration.
+
+Every file changed by a patch is reported with severity
+  error    when the file is missing or the patch does not apply
+  warning  when the change is already applied
+
+Examples:
+  wswcli patchvendor verify
+  wswcli patchvendor verify artifacts/patches --vendor-dir vendor
+  wswcli patchvendor verify --format junit -o patch-report.xml`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runPatchVendorVerify,
+}
+
+func init() {
+	patchvendorVerifyCmd.Flags().StringVar(&verifyVendorDir, "vendor-dir", "vendor", "Directory of the installed vendor packages")
+	patchvendorVerifyCmd.Flags().StringVar(&verifyFailOn, "fail-on", FailOnError, "Minimum severity that makes the command fail (error, warning, never)")
+	supportsReport(patchvendorVerifyCmd)
+	supportsTextOutput(patchvendorVerifyCmd)
+	patchvendorCmd.AddCommand(patchvendorVerifyCmd)
+}
+
+func runPatchVendorVerify(cmd *cobra.Command, args []string) error {
+	if err := validateFailOn(verifyFailOn); err != nil {
+		return err
+	}
+
+	patchDir := ""
+	if len(args) > 0 {
+		patchDir = args[0]
+	} else {
+		cfg, err := config.Load(".")
+		if err != nil {
+			return fmt.Errorf("error loading configuration: %w", err)
+		}
+		patchDir = cfg.PatchVendor.PatchOutputDir
+	}
+	if info, err := os.Stat(patchDir); err != nil || !info.IsDir() {
+		return usageError("patch directory does not exist: %s", patchDir)
+	}
+
+	patches, findings, err := vendorpatch.Verify(commandContext(cmd), patchDir, verifyVendorDir)
+	if err != nil {
+		return err
+	}
+	logInfo("Verified %d patches in %s against %s", len(patches), patchDir, verifyVendorDir,
+		slog.Int("patches", len(patches)), slog.String("path", patchDir), slog.String("vendor_dir", verifyVendorDir))
+
+	result := newReportResult("patchvendor verify")
+	result.Root = patchDir
+	for _, patch := range patches {
+		result.Files = append(result.Files, filepath.ToSlash(patch))
+	}
+	result.Add(findings...)
+
+	format := outputFormat
+	if format == "" {
+		format = "text"
+	}
+	if err := writeReport(format, result); err != nil {
+		return err
+	}
+
+	severities := make([]string, len(result.Findings))
+	for i, finding := range result.Findings {
+		severities[i] = finding.Severity
+	}
+	return failOnFindings(verifyFailOn, severities, fmt.Sprintf("%d patch problems found", len(result.Findings)))
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunPatchVendorVerify(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProjectFiles(t, ".", map[string]string{
+		"vendor/shopware/core/Framework/A.php": "a\nb\nc\n",
+		"vendor/shopware/core/Framework/B.php": "a\nB\nc\n",
+		"vendor/shopware/core/Framework/C.php": "x\n",
+		"artifacts/patches/shopware/core/applies.patch": "diff --git a/Framework/A.php b/Framework/A.php\n" +
+			"index de98044..7be73ce 100644\n" +
+			"--- a/Framework/A.php\n+++ b/Framework/A.php\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n" +
+			"diff --git a/Framework/New.php b/Framework/New.php\n" +
+			"new file mode 100644\n" +
+			"--- /dev/null\n+++ b/Framework/New.php\n@@ -0,0 +1 @@\n+new\n",
+		"artifacts/patches/shopware/core/applied.patch": "--- a/Framework/B.php\n+++ b/Framework/B.php\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		"artifacts/patches/shopware/core/broken.patch": "--- a/Framework/C.php\n+++ b/Framework/C.php\n@@ -1 +1 @@\n-z\n+y\n" +
+			"--- a/Framework/Missing.php\n+++ b/Framework/Missing.php\n@@ -1 +1 @@\n-x\n+y\n",
+		"artifacts/patches/loose.patch": "--- a/A.php\n+++ b/A.php\n",
+	})
+
+	defer func(format, file, vendor, failOn string) {
+		outputFormat, outputFile, verifyVendorDir, verifyFailOn = format, file, vendor, failOn
+	}(outputFormat, outputFile, verifyVendorDir, verifyFailOn)
+	outputFormat, outputFile, verifyVendorDir, verifyFailOn = "text", "verify.txt", "vendor", FailOnError
+
+	err := runPatchVendorVerify(patchvendorVerifyCmd, nil)
+	if exitCodeForError(err) != ExitFindings {
+		t.Fatalf("Expected findings, got %v", err)
+	}
+	data, err := os.ReadFile("verify.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"artifacts/patches/loose.patch: error: cannot tell the vendor package, expected the patch in <provider>/<package>/ below artifacts/patches [unknown-package]",
+		"artifacts/patches/shopware/core/applied.patch:1: warning: Framework/B.php is already patched [already-applied]",
+		"artifacts/patches/shopware/core/broken.patch:1: error: Framework/C.php: patch does not apply at line 1 [does-not-apply]",
+		"artifacts/patches/shopware/core/broken.patch:6: error: Framework/Missing.php does not exist in vendor/shopware/core [missing-file]",
+		"",
+		"4 findings (3 errors, 1 warning) in 4 files",
+	}
+	if got := strings.TrimSpace(string(data)); got != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected verify output:\n%s\nExpected:\n%s", got, strings.Join(expected, "\n"))
+	}
+
+	if err := runPatchVendorVerify(patchvendorVerifyCmd, []string{"artifacts/patches/shopware/core/applied.patch"}); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected a usage error for a file as patch directory, got %v", err)
+	}
+
+	// Already applied patches only fail with --fail-on warning
+	if err := os.MkdirAll("only-applied/shopware/core", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename("artifacts/patches/shopware/core/applied.patch", "only-applied/shopware/core/applied.patch"); err != nil {
+		t.Fatal(err)
+	}
+	for failOn, code := range map[string]int{FailOnError: ExitOK, FailOnWarning: ExitFindings} {
+		verifyFailOn = failOn
+		if err := runPatchVendorVerify(patchvendorVerifyCmd, []string{"only-applied"}); exitCodeForError(err) != code {
+			t.Errorf("Expected exit code %d with --fail-on %s, got %v", code, failOn, err)
+		}
+	}
+}
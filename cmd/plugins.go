@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/plugin"
+)
+
+// pluginAnnotation marks the commands that run a plugin
+const pluginAnnotation = "wswcli-plugin"
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "Manage wswcli-<name> plugin executables",
+	Long: `Plugins are executables named wswcli-<name> that run as "wswcli <name>".
+
+They are found in the .wswcli/plugins/ directory of the project, or in
+.wswcli-state/plugins/ when .wswcli is a config file, and in the directories of
+PATH, the first plugin of a name wins. The project is the nearest directory
+from the current directory upwards with .wswcli or .git. Plugins that have the name
+of a built-in command are skipped.
+
+A plugin gets all arguments after its name unchanged, including flags and
+--help. Global flags such as -v given before the name apply to wswcli and are
+not passed to the plugin. The project root and the effective .wswcli
+configuration are passed as environment variables and as a JSON document on
+stdin:
+
+  WSWCLI_PLUGIN        Name of the plugin
+  WSWCLI_VERSION       Version of wswcli
+  WSWCLI_PROJECT_ROOT  Absolute path of the project root
+  WSWCLI_CONFIG_FILE   Path of the .wswcli config file, empty without one
+
+The exit code of the plugin is the exit code of wswcli. A line containing
+"wswcli-short: <description>" in the first 4 KB of the executable, e.g. a
+comment in a script, is shown as its description.
+
+Examples:
+  wswcli plugins list`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found in .wswcli/plugins/ and on PATH",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE:  runPluginsList,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.AddCommand(pluginsListCmd)
+}
+
+// runPluginsList lists the registered plugins and warns about skipped ones
+func runPluginsList(cmd *cobra.Command, args []string) error {
+	root, err := projectRoot()
+	if err != nil {
+		return err
+	}
+
+	plugins, skipped := plugin.Find(root, builtinCommands(rootCmd))
+	for _, skip := range skipped {
+		logWarn("Skipping %s: %s", skip.Path, skip.Reason, slog.String("path", skip.Path), slog.String("reason", skip.Reason))
+	}
+	if len(plugins) == 0 {
+		logInfo("No plugins found in %s or on PATH", plugin.Dir(root))
+		return nil
+	}
+
+	return writePluginList(os.Stdout, plugins)
+}
+
+// writePluginList writes the plugins with their source and path
+func writePluginList(w io.Writer, plugins []plugin.Plugin) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tSOURCE\tPATH\tDESCRIPTION")
+	for _, p := range plugins {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", p.Name, p.Source, p.Path, p.Short)
+	}
+	return table.Flush()
+}
+
+// addPluginCommands registers the plugins found for the current directory as
+// subcommands of root. Skipped plugins are reported by "plugins list".
+func addPluginCommands(root *cobra.Command) {
+	projectPath, err := projectRoot()
+	if err != nil {
+		return
+	}
+
+	plugins, _ := plugin.Find(projectPath, builtinCommands(root))
+	for _, p := range plugins {
+		root.AddCommand(newPluginCommand(p))
+	}
+}
+
+// newPluginCommand returns the command running p. Flags after the name are not
+// parsed, so they and --help reach the plugin unchanged.
+func newPluginCommand(p plugin.Plugin) *cobra.Command {
+	short := p.Short
+	if short == "" {
+		short = "Run the " + filepath.Base(p.Path) + " plugin"
+	}
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              short,
+		Annotations:        map[string]string{pluginAnnotation: p.Path},
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			global, args := splitPluginArgs(cmd.Root().PersistentFlags(), os.Args[1:], p.Name, args)
+			if len(global) > 0 {
+				// Flag parsing is disabled, so apply the global flags given before the name
+				if err := cmd.Root().PersistentFlags().Parse(global); err != nil {
+					return &ExitError{Code: ExitUsage, Err: err}
+				}
+				if err := setupLogging(); err != nil {
+					return err
+				}
+				if err := prepareReportOutput(cmd); err != nil {
+					return err
+				}
+			}
+			return runPlugin(cmd, p, args)
+		},
+	}
+}
+
+// splitPluginArgs splits the command line of plugin name into the global flags
+// in front of the name and the arguments of the plugin. Without flags in front
+// of the name, args as passed by cobra are returned.
+func splitPluginArgs(flags *pflag.FlagSet, commandLine []string, name string, args []string) ([]string, []string) {
+	for i := 0; i < len(commandLine); i++ {
+		arg := commandLine[i]
+		if arg == name {
+			return commandLine[:i], commandLine[i+1:]
+		}
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		if flagTakesValue(flags, arg) {
+			// Skip the value of e.g. --log-format json
+			i++
+		}
+	}
+	return nil, args
+}
+
+// flagTakesValue reports whether the flag arg of flags is followed by a
+// separate value argument
+func flagTakesValue(flags *pflag.FlagSet, arg string) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		flag := flags.Lookup(name)
+		return flag != nil && flag.NoOptDefVal == ""
+	}
+
+	// Shorthands can be combined, e.g. -vv, and the last one takes the value
+	shorthands := strings.TrimPrefix(arg, "-")
+	for i, shorthand := range shorthands {
+		flag := flags.ShorthandLookup(string(shorthand))
+		if flag != nil && flag.NoOptDefVal == "" {
+			return i == len(shorthands)-1
+		}
+	}
+	return false
+}
+
+// runPlugin runs the executable of p with args and the plugin context
+func runPlugin(cmd *cobra.Command, p plugin.Plugin, args []string) error {
+	root, err := projectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(root)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	input, err := json.Marshal(plugin.NewContext(p, version, root, args, cfg))
+	if err != nil {
+		return fmt.Errorf("error encoding plugin context: %w", err)
+	}
+
+	configFile := config.FilePath(root)
+	if _, err := os.Stat(configFile); err != nil {
+		configFile = ""
+	}
+
+	logDebug("Running plugin %s", p.Path)
+	process := exec.CommandContext(commandContext(cmd), p.Path, args...)
+	process.Stdin = bytes.NewReader(append(input, '\n'))
+	process.Stdout = cmd.OutOrStdout()
+	process.Stderr = cmd.ErrOrStderr()
+	process.Env = append(os.Environ(),
+		"WSWCLI_PLUGIN="+p.Name,
+		"WSWCLI_VERSION="+version,
+		"WSWCLI_PROJECT_ROOT="+root,
+		"WSWCLI_CONFIG_FILE="+configFile,
+	)
+
+	err = process.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		// The plugin reported its own errors
+		return &ExitError{Code: exitErr.ExitCode()}
+	}
+	if err != nil {
+		return fmt.Errorf("error running plugin %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// projectRoot returns the project of the current directory with symlinks
+// resolved: the nearest directory upwards with .wswcli or .git, see
+// config.ProjectRoot. The .wswcli configuration is read from it.
+func projectRoot() (string, error) {
+	root, err := config.ProjectRoot(".")
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return root, nil
+}
+
+// builtinCommands returns the names and aliases of the commands of cmd that
+// plugins cannot take, without the commands that run plugins
+func builtinCommands(cmd *cobra.Command) map[string]bool {
+	builtin := map[string]bool{"help": true, "completion": true}
+	for _, sub := range cmd.Commands() {
+		if _, ok := sub.Annotations[pluginAnnotation]; ok {
+			continue
+		}
+		builtin[sub.Name()] = true
+		for _, alias := range sub.Aliases {
+			builtin[alias] = true
+		}
+	}
+	return builtin
+}
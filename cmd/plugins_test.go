@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/plugin"
+)
+
+// writePlugin writes an executable shell script plugin to dir
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBuiltinCommands(t *testing.T) {
+	parent := &cobra.Command{Use: "wswcli"}
+	parent.AddCommand(&cobra.Command{Use: "other", Aliases: []string{"builtin"}})
+	parent.AddCommand(newPluginCommand(plugin.Plugin{Name: "deploy", Path: "/usr/local/bin/wswcli-deploy"}))
+
+	builtin := builtinCommands(parent)
+	for _, name := range []string{"help", "completion", "other", "builtin"} {
+		if !builtin[name] {
+			t.Errorf("Expected %s to be a built-in command", name)
+		}
+	}
+	if builtin["deploy"] {
+		t.Error("Expected plugin commands not to count as built-in")
+	}
+}
+
+func TestPluginCommandHelp(t *testing.T) {
+	cmd := newPluginCommand(plugin.Plugin{Name: "deploy", Path: "/usr/local/bin/wswcli-deploy"})
+	if cmd.Short != "Run the wswcli-deploy plugin" {
+		t.Errorf("Unexpected fallback description %q", cmd.Short)
+	}
+	if !cmd.DisableFlagParsing {
+		t.Error("Expected flags to be passed to the plugin")
+	}
+}
+
+func TestSplitPluginArgs(t *testing.T) {
+	flags := rootCmd.PersistentFlags()
+	tests := []struct {
+		name        string
+		commandLine []string
+		args        []string
+		global      []string
+		pluginArgs  []string
+	}{
+		{"global flag before the name", []string{"-v", "hi", "x"}, []string{"-v", "x"}, []string{"-v"}, []string{"x"}},
+		{"flag after the name", []string{"hi", "-v", "x"}, []string{"-v", "x"}, []string{}, []string{"-v", "x"}},
+		{"flag with separate value", []string{"--log-format", "json", "-vv", "hi", "--help"}, []string{"--log-format", "json", "-vv", "--help"}, []string{"--log-format", "json", "-vv"}, []string{"--help"}},
+		{"shorthand with value", []string{"-o", "hi", "hi", "x"}, []string{"-o", "hi", "x"}, []string{"-o", "hi"}, []string{"x"}},
+		{"flag with inline value", []string{"--log-format=json", "hi"}, []string{"--log-format=json"}, []string{"--log-format=json"}, []string{}},
+		{"name not on the command line", []string{"test.run"}, []string{"x"}, nil, []string{"x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			global, args := splitPluginArgs(flags, tt.commandLine, "hi", tt.args)
+			if strings.Join(global, " ") != strings.Join(tt.global, " ") || strings.Join(args, " ") != strings.Join(tt.pluginArgs, " ") {
+				t.Errorf("Expected global %q and plugin args %q, got %q and %q", tt.global, tt.pluginArgs, global, args)
+			}
+		})
+	}
+}
+
+func TestRunPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	root := t.TempDir()
+	writeProjectFiles(t, root, map[string]string{
+		".wswcli/config": "[patchvendor]\npatch_output_dir = patches\n",
+	})
+	t.Chdir(root)
+	root, err := projectRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "context.json")
+	path := writePlugin(t, filepath.Join(root, configName, "plugins"), "wswcli-check",
+		`cat > "`+output+`"
+echo "$WSWCLI_PLUGIN $WSWCLI_PROJECT_ROOT $WSWCLI_CONFIG_FILE"
+exit 4
+`)
+
+	var stdout bytes.Buffer
+	cmd := newPluginCommand(plugin.Plugin{Name: "check", Path: path})
+	cmd.SetOut(&stdout)
+
+	err = runPlugin(cmd, plugin.Plugin{Name: "check", Path: path}, []string{"--strict", "src"})
+	if code := exitCodeForError(err); code != 4 {
+		t.Errorf("Expected the exit code of the plugin, got %d (%v)", code, err)
+	}
+	if err.Error() != "" {
+		t.Errorf("Expected no message for the plugin exit code, got %q", err.Error())
+	}
+
+	expected := "check " + root + " " + filepath.Join(root, configName, "config") + "\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected environment %q, got %q", expected, stdout.String())
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var context struct {
+		Name        string   `json:"name"`
+		ProjectRoot string   `json:"project_root"`
+		Args        []string `json:"args"`
+		Config      struct {
+			PatchVendor struct {
+				PatchOutputDir string `json:"patch_output_dir"`
+			} `json:"patchvendor"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(data, &context); err != nil {
+		t.Fatalf("Expected JSON on stdin, got %q: %v", data, err)
+	}
+	if context.Name != "check" || context.ProjectRoot != root {
+		t.Errorf("Unexpected plugin context %s", data)
+	}
+	if strings.Join(context.Args, " ") != "--strict src" {
+		t.Errorf("Expected the arguments unchanged, got %v", context.Args)
+	}
+	if context.Config.PatchVendor.PatchOutputDir != "patches" {
+		t.Errorf("Expected the effective config, got %s", data)
+	}
+	if !strings.Contains(string(data), `"skip":[]`) {
+		t.Errorf("Expected empty lists instead of null, got %s", data)
+	}
+}
+
+func TestPluginsFromSubdirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeProjectFiles(t, root, map[string]string{
+		".wswcli":             "[patchvendor]\npatch_output_dir = patches\n",
+		"src/Resources/.keep": "",
+	})
+	path := writePlugin(t, filepath.Join(root, config.StateFallbackName, "plugins"), "wswcli-hi", `echo "$WSWCLI_PROJECT_ROOT $WSWCLI_CONFIG_FILE"`+"\n")
+	t.Chdir(filepath.Join(root, "src", "Resources"))
+
+	// The plugin of the project is found from a nested directory
+	parent := &cobra.Command{Use: "wswcli"}
+	addPluginCommands(parent)
+	hi, _, err := parent.Find([]string{"hi"})
+	if err != nil || hi.Annotations[pluginAnnotation] != path {
+		t.Fatalf("Expected the hi plugin of the project, got %v (%v)", hi.Annotations, err)
+	}
+
+	var stdout bytes.Buffer
+	hi.SetOut(&stdout)
+	if err := runPlugin(hi, plugin.Plugin{Name: "hi", Path: path}, nil); err != nil {
+		t.Fatal(err)
+	}
+	expected := root + " " + filepath.Join(root, configName) + "\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected the project root and config file %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestRunPluginSuccess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	t.Chdir(t.TempDir())
+	path := writePlugin(t, t.TempDir(), "wswcli-ok", "test -z \"$WSWCLI_CONFIG_FILE\"\n")
+	cmd := newPluginCommand(plugin.Plugin{Name: "ok", Path: path})
+	if err := runPlugin(cmd, plugin.Plugin{Name: "ok", Path: path}, nil); err != nil {
+		t.Errorf("Expected success without a config file, got %v", err)
+	}
+}
+
+func TestWritePluginList(t *testing.T) {
+	var buf bytes.Buffer
+	plugins := []plugin.Plugin{{Name: "lint", Path: "/project/.wswcli/plugins/wswcli-lint", Source: plugin.SourceProject, Short: "Check the coding rules"}}
+	if err := writePluginList(&buf, plugins); err != nil {
+		t.Fatal(err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"NAME", "lint", "project", "/project/.wswcli/plugins/wswcli-lint", "Check the coding rules"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in plugin list:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+// Global --format and --output. Without --format commands print their own
+// human-readable output; commands marked with supportsTextOutput write it to
+// --output, twigblocks writes its findings as JSON there.
+var (
+	outputFormat string
+	outputFile   string
+)
+
+// Annotations of commands that support --format and --output without --format
+const (
+	reportAnnotation     = "wswcli/report"
+	textOutputAnnotation = "wswcli/text-output"
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "format", "", "Output format of the findings ("+strings.Join(report.Names(), ", ")+")")
+	rootCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "Write the output to a file instead of stdout")
+}
+
+// prepareReportOutput validates --format and --output for the command
+func prepareReportOutput(cmd *cobra.Command) error {
+	if outputFile != "" && outputFormat == "" && cmd.LocalNonPersistentFlags().Lookup("output") == nil {
+		// Commands that ignore --output would silently write nothing
+		if _, ok := cmd.Annotations[textOutputAnnotation]; !ok {
+			if _, ok := cmd.Annotations[reportAnnotation]; ok {
+				return usageError("--output requires --format for %s", cmd.CommandPath())
+			}
+			return usageError("--output is not supported by %s", cmd.CommandPath())
+		}
+	}
+
+	if outputFormat == "" || cmd.LocalNonPersistentFlags().Lookup("format") != nil {
+		// twigblocks list exports its inventory in its own formats
+		return nil
+	}
+	if _, ok := cmd.Annotations[reportAnnotation]; !ok {
+		return usageError("--format is not supported by %s", cmd.CommandPath())
+	}
+	if _, err := report.Lookup(outputFormat); err != nil {
+		return &ExitError{Code: ExitUsage, Err: err}
+	}
+	return nil
+}
+
+// reportToStdout reports whether the --format output goes to stdout, so other
+// data such as diffs must not be printed there
+func reportToStdout() bool {
+	return outputFormat != "" && outputFile == ""
+}
+
+// supportsReport marks cmd as writing its findings in the --format
+func supportsReport(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[reportAnnotation] = "true"
+}
+
+// supportsTextOutput marks cmd as writing its human-readable output to --output
+// without --format
+func supportsTextOutput(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[textOutputAnnotation] = "true"
+}
+
+// newReportResult returns an empty result of command with the version of wswcli
+func newReportResult(command string) *report.Result {
+	return report.New(command, version)
+}
+
+// writeReport writes result in format to --output, or to stdout without it
+func writeReport(format string, result *report.Result) error {
+	if outputFile != "" {
+		if err := writeReportFile(outputFile, format, result); err != nil {
+			return err
+		}
+		logInfo("Report saved to: %s", outputFile, slog.String("path", outputFile))
+		return nil
+	}
+	return writeReportTo(os.Stdout, format, result)
+}
+
+// writeReportFile writes result in format to the file at path
+func writeReportFile(path, format string, result *report.Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer file.Close()
+	return writeReportTo(file, format, result)
+}
+
+// writeReportTo writes result in format to w
+func writeReportTo(w io.Writer, format string, result *report.Result) error {
+	if err := report.Write(w, format, result); err != nil {
+		if _, lookupErr := report.Lookup(format); lookupErr != nil {
+			return &ExitError{Code: ExitUsage, Err: lookupErr}
+		}
+		return fmt.Errorf("error writing %s report: %w", format, err)
+	}
+	return nil
+}
+
+// writeTextOutput writes the human-readable output of a command with write to
+// --output, or to stdout without it
+func writeTextOutput(write func(w io.Writer) error) error {
+	var w io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := write(w); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
+
+// relativeSlashPath returns file relative to root using forward slashes
+func relativeSlashPath(root, file string) string {
+	relPath, err := filepath.Rel(root, file)
+	if err != nil {
+		relPath = file
+	}
+	return filepath.ToSlash(relPath)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+func TestPrepareReportOutput(t *testing.T) {
+	defer func(format, file string) { outputFormat, outputFile = format, file }(outputFormat, outputFile)
+	outputFormat, outputFile = "sarif", "report.sarif"
+
+	if err := prepareReportOutput(twigblocksCmd); err != nil {
+		t.Errorf("Expected --format to be accepted by twigblocks, got %v", err)
+	}
+	if err := prepareReportOutput(patchvendorCmd); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected a usage error for patchvendor, got %v", err)
+	}
+	for _, cmd := range []*cobra.Command{twigblocksConflictsCmd, twigblocksUpgradeCmd} {
+		if err := prepareReportOutput(cmd); err != nil {
+			t.Errorf("Expected --format to be accepted by %s, got %v", cmd.CommandPath(), err)
+		}
+	}
+	// Commands with their own --format flag are left alone
+	if err := prepareReportOutput(twigblocksListCmd); err != nil {
+		t.Errorf("Expected no error for twigblocks list, got %v", err)
+	}
+
+	outputFormat = "yaml"
+	if err := prepareReportOutput(twigblocksCmd); exitCodeForError(err) != ExitUsage || !strings.Contains(err.Error(), "checkstyle") {
+		t.Errorf("Expected a usage error listing the formats, got %v", err)
+	}
+}
+
+func TestPrepareOutputWithoutFormat(t *testing.T) {
+	defer func(format, file string) { outputFormat, outputFile = format, file }(outputFormat, outputFile)
+	outputFormat, outputFile = "", "out.txt"
+
+	// Commands writing their text output to --output
+	for _, cmd := range []*cobra.Command{twigblocksCmd, twigblocksConflictsCmd, twigblocksUpgradeCmd, patchvendorVerifyCmd, twigblocksListCmd} {
+		if err := prepareReportOutput(cmd); err != nil {
+			t.Errorf("Expected --output to be accepted by %s, got %v", cmd.CommandPath(), err)
+		}
+	}
+
+	// Commands that would ignore --output reject it
+	if err := prepareReportOutput(bs4to5Cmd); exitCodeForError(err) != ExitUsage || !strings.Contains(err.Error(), "requires --format") {
+		t.Errorf("Expected --output to require --format for bs-4-to-5, got %v", err)
+	}
+	for _, cmd := range []*cobra.Command{patchvendorCmd, pluginsListCmd, migrateListCmd} {
+		if err := prepareReportOutput(cmd); exitCodeForError(err) != ExitUsage {
+			t.Errorf("Expected a usage error for --output with %s, got %v", cmd.CommandPath(), err)
+		}
+	}
+}
+
+func TestRunWithFormat(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeProjectFiles(t, "templates", map[string]string{
+		"page.html.twig": "{% block content %}A{% endblock %}\n<div class=\"ml-2\">{% block content %}B{% endblock %}</div>\n",
+	})
+
+	defer func(format, file, failOn string, check bool, max int) {
+		outputFormat, outputFile, failOnSeverity, checkOnly, maxFindings = format, file, failOn, check, max
+	}(outputFormat, outputFile, failOnSeverity, checkOnly, maxFindings)
+	failOnSeverity = FailOnNever
+
+	read := func() report.Result {
+		t.Helper()
+		data, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result report.Result
+		if err := json.Unmarshal(data, &result); err != nil {
+			t.Fatalf("Invalid JSON report: %v\n%s", err, data)
+		}
+		return result
+	}
+
+	outputFormat, outputFile = "json", "twigblocks.json"
+	if err := runTwigBlocks(twigblocksCmd, []string{"templates"}); err != nil {
+		t.Fatal(err)
+	}
+	result := read()
+	if result.Command != "twigblocks" || len(result.Findings) != 2 || result.Findings[1].File != "page.html.twig" || result.Findings[1].Line != 2 {
+		t.Errorf("Unexpected twigblocks result: %+v", result)
+	}
+
+	outputFormat, outputFile = "sarif", "conflicts.sarif"
+	writeProjectFiles(t, "shop", map[string]string{
+		"custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig": "{% sw_extends '@Storefront/storefront/base.html.twig' %}\n{% block base_header %}A{% endblock %}\n",
+		"custom/plugins/PluginB/src/Resources/views/storefront/base.html.twig": "{% sw_extends '@Storefront/storefront/base.html.twig' %}\n{% block base_header %}B{% endblock %}\n",
+	})
+	if err := runTwigBlocksConflicts(twigblocksConflictsCmd, []string{"shop"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"ruleId": "block-override-conflict"`) || !strings.Contains(string(data), "custom/plugins/PluginB/src/Resources/views/storefront/base.html.twig") {
+		t.Errorf("Expected the conflicts as SARIF, got:\n%s", data)
+	}
+
+	outputFormat = "json"
+	checkOnly, maxFindings, outputFile = true, 10, "check.json"
+	if err := runBS4to5Migration(bs4to5Cmd, []string{"templates"}); err != nil {
+		t.Fatal(err)
+	}
+	result = read()
+	if len(result.Findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %+v", result.Findings)
+	}
+	finding := result.Findings[0]
+	if finding.Rule != "Margin Left" || finding.Severity != report.SeverityWarning || finding.File != "page.html.twig" || finding.Line != 2 || finding.Column != 13 {
+		t.Errorf("Unexpected check finding: %+v", finding)
+	}
+}
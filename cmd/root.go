@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"github.com/spf13/cobra"
 	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
 )
 
 var (
@@ -12,10 +17,152 @@ var (
 	date    = "unknown"
 )
 
+// Exit codes returned by wswcli
+const (
+	ExitOK       = 0 // Success, no findings above the --fail-on threshold
+	ExitFindings = 1 // Findings at or above the --fail-on threshold
+	ExitUsage    = 2 // Invalid command line usage (unknown flags, wrong arguments, missing paths)
+	ExitIO       = 3 // Runtime or I/O error (unreadable files, failed writes)
+)
+
+// Severity levels of findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Values accepted by --fail-on
+const (
+	FailOnError   = "error"
+	FailOnWarning = "warning"
+	FailOnNever   = "never"
+)
+
+// ExitError carries a specific process exit code through cobra's error handling
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// usageError marks an error as invalid command line usage
+func usageError(format string, args ...interface{}) error {
+	return &ExitError{Code: ExitUsage, Err: fmt.Errorf(format, args...)}
+}
+
+// usageArgs wraps a positional argument validator so that its errors exit with ExitUsage
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return &ExitError{Code: ExitUsage, Err: err}
+		}
+		return nil
+	}
+}
+
+// validateFailOn checks the value of a --fail-on flag
+func validateFailOn(failOn string) error {
+	switch failOn {
+	case FailOnError, FailOnWarning, FailOnNever:
+		return nil
+	}
+	return usageError("invalid --fail-on value: %s (use error, warning or never)", failOn)
+}
+
+// severityMeetsThreshold reports whether a finding of the given severity fails the run
+func severityMeetsThreshold(severity, failOn string) bool {
+	switch failOn {
+	case FailOnNever:
+		return false
+	case FailOnWarning:
+		return severity == SeverityError || severity == SeverityWarning
+	default:
+		return severity == SeverityError
+	}
+}
+
+// failOnFindings returns an ExitFindings error when any severity meets the threshold
+func failOnFindings(failOn string, severities []string, message string) error {
+	for _, severity := range severities {
+		if severityMeetsThreshold(severity, failOn) {
+			return &ExitError{Code: ExitFindings, Err: errors.New(message)}
+		}
+	}
+	return nil
+}
+
+// exitCodeForError maps an error returned by a command to a process exit code
+func exitCodeForError(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	// Invalid packs, rules and rule files reported by the migrate package
+	var inputErr *migrate.InputError
+	if errors.As(err, &inputErr) {
+		return ExitUsage
+	}
+
+	// Errors produced by cobra itself while parsing the command line
+	message := err.Error()
+	for _, prefix := range []string{"unknown command", "unknown flag", "unknown shorthand flag", "required flag", "invalid argument"} {
+		if strings.HasPrefix(message, prefix) {
+			return ExitUsage
+		}
+	}
+
+	return ExitIO
+}
+
+// commandContext returns the context of cmd, or a background context when cmd
+// runs outside Execute, e.g. in tests
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "wswcli",
 	Short: "Wim Shopware CLI",
-	Long:  "Wim Shopware CLI",
+	Long: `Wim Shopware CLI
+
+Exit codes:
+  0  Success
+  1  Findings at or above the --fail-on threshold
+  2  Usage error (invalid flags, arguments or paths)
+  3  Runtime or I/O error`,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		return prepareReportOutput(cmd)
+	},
+}
+
+func init() {
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &ExitError{Code: ExitUsage, Err: err}
+	})
 }
 
 func SetVersionInfo(v, c, d string) {
@@ -26,8 +173,19 @@ func SetVersionInfo(v, c, d string) {
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	addPluginCommands(rootCmd)
+
+	cmd, err := rootCmd.ExecuteC()
+	if err == nil {
+		return
+	}
+
+	code := exitCodeForError(err)
+	if message := err.Error(); message != "" {
+		logger.Error(message)
+	}
+	if code == ExitUsage {
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", cmd.CommandPath())
 	}
+	os.Exit(code)
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+func TestExitCodeForError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"no error", nil, ExitOK},
+		{"findings", &ExitError{Code: ExitFindings, Err: errors.New("found 2 duplicate block groups")}, ExitFindings},
+		{"wrapped usage error", fmt.Errorf("context: %w", usageError("path does not exist: %s", "x")), ExitUsage},
+		{"migrate input error", fmt.Errorf("context: %w", &migrate.InputError{Err: errors.New("unknown rule: Typo")}), ExitUsage},
+		{"unknown flag", errors.New("unknown flag: --foo"), ExitUsage},
+		{"required flag", errors.New(`required flag(s) "from" not set`), ExitUsage},
+		{"unknown command", errors.New(`unknown command "foo" for "wswcli"`), ExitUsage},
+		{"io error", errors.New("error reading file: permission denied"), ExitIO},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := exitCodeForError(tt.err); code != tt.expected {
+				t.Errorf("exitCodeForError(%v) = %d, expected %d", tt.err, code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFailOnFindings(t *testing.T) {
+	tests := []struct {
+		failOn     string
+		severities []string
+		fails      bool
+	}{
+		{FailOnError, []string{SeverityWarning, SeverityInfo}, false},
+		{FailOnError, []string{SeverityWarning, SeverityError}, true},
+		{FailOnWarning, []string{SeverityInfo, SeverityWarning}, true},
+		{FailOnWarning, []string{SeverityInfo}, false},
+		{FailOnNever, []string{SeverityError}, false},
+		{FailOnError, nil, false},
+	}
+
+	for _, tt := range tests {
+		err := failOnFindings(tt.failOn, tt.severities, "findings")
+		if (err != nil) != tt.fails {
+			t.Errorf("failOnFindings(%s, %v) = %v, expected failure: %v", tt.failOn, tt.severities, err, tt.fails)
+		}
+		if err != nil && exitCodeForError(err) != ExitFindings {
+			t.Errorf("Expected exit code %d, got %d", ExitFindings, exitCodeForError(err))
+		}
+	}
+
+	if err := validateFailOn("sometimes"); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected usage error for invalid --fail-on value, got %v", err)
+	}
+}
@@ -2,62 +2,24 @@ package cmd
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"regexp"
-	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
 )
 
-// TwigBlock represents a Twig block found in a file
-type TwigBlock struct {
-	Name    string `json:"name"`
-	File    string `json:"file"`
-	Line    int    `json:"line"`
-	Content string `json:"content"`
-	Hash    string `json:"hash"`
-}
-
-// DuplicateGroup represents a group of duplicate blocks
-type DuplicateGroup struct {
-	BlockName string      `json:"block_name"`
-	Hash      string      `json:"hash"`
-	Count     int         `json:"count"`
-	Files     []TwigBlock `json:"files"`
-}
-
-// BitbucketReport represents the Bitbucket Pipes report format
-type BitbucketReport struct {
-	Title       string                `json:"title"`
-	Details     string                `json:"details"`
-	Result      string                `json:"result"`
-	Data        []BitbucketData       `json:"data"`
-	Annotations []BitbucketAnnotation `json:"annotations,omitempty"`
-}
-
-type BitbucketData struct {
-	Title string `json:"title"`
-	Type  string `json:"type"`
-	Value string `json:"value"`
-}
-
-type BitbucketAnnotation struct {
-	Path       string `json:"path"`
-	Line       int    `json:"line"`
-	Message    string `json:"message"`
-	Severity   string `json:"severity"`
-	Type       string `json:"type"`
-	ExternalID string `json:"external_id,omitempty"`
-}
-
 var (
 	bitbucketFormat bool
 	projectPath     string
-	outputFile      string
+	fixBlocks       bool
+	fixDryRun       bool
+	failOnSeverity  string
 )
 
 var twigblocksCmd = &cobra.Command{
@@ -72,16 +34,30 @@ projects where duplicate blocks can cause template inheritance issues.
 Examples:
   wswcli twigblocks .                    # Scan current directory
   wswcli twigblocks /path/to/project     # Scan specific project
-  wswcli twigblocks . --bitbucket        # Output in Bitbucket format
-  wswcli twigblocks . --output report.json  # Save report to file`,
-	Args: cobra.MaximumNArgs(1),
+  wswcli twigblocks . --bitbucket        # Write JUnit and Code Insights reports to test-reports/
+  wswcli twigblocks . --bitbucket --bitbucket-upload  # Also upload the Code Insights report
+  wswcli twigblocks . --output report.json  # Save report to file
+  wswcli twigblocks . --format sarif -o twigblocks.sarif  # Write findings as SARIF
+  wswcli twigblocks . --fix --dry-run    # Show a diff of the duplicate fixes
+  wswcli twigblocks . --fix              # Remove redundant duplicate blocks
+  wswcli twigblocks . --fail-on never    # Report only, always exit with 0
+
+Duplicate blocks are reported with severity error. Use --fail-on to choose which
+severity makes the command exit with code 1 (error, warning or never).`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
 	RunE: runTwigBlocks,
 }
 
 func init() {
 	rootCmd.AddCommand(twigblocksCmd)
-	twigblocksCmd.Flags().BoolVar(&bitbucketFormat, "bitbucket", false, "Output in Bitbucket Pipes format")
-	twigblocksCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for the report (JSON format)")
+	supportsReport(twigblocksCmd)
+	supportsTextOutput(twigblocksCmd)
+	twigblocksCmd.Flags().BoolVar(&bitbucketFormat, "bitbucket", false, "Write a JUnit report and a Code Insights report with annotations for Bitbucket Pipelines")
+	twigblocksCmd.Flags().StringVar(&bitbucketDir, "bitbucket-dir", "test-reports", "Directory for the --bitbucket reports")
+	twigblocksCmd.Flags().BoolVar(&bitbucketUpload, "bitbucket-upload", false, "With --bitbucket, upload the Code Insights report through the Pipelines proxy")
+	twigblocksCmd.Flags().BoolVar(&fixBlocks, "fix", false, "Remove redundant duplicate blocks (asks which definition to keep on conflicts)")
+	twigblocksCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "With --fix, print a unified diff instead of changing files")
+	twigblocksCmd.PersistentFlags().StringVar(&failOnSeverity, "fail-on", FailOnError, "Minimum severity that makes the command fail (error, warning, never)")
 }
 
 func runTwigBlocks(cmd *cobra.Command, args []string) error {
@@ -94,217 +70,136 @@ func runTwigBlocks(cmd *cobra.Command, args []string) error {
 
 	// Validate project path
 	if _, err := os.Stat(projectPath); os.IsNotExist(err) {
-		return fmt.Errorf("project path does not exist: %s", projectPath)
+		return usageError("project path does not exist: %s", projectPath)
+	}
+	if err := validateFailOn(failOnSeverity); err != nil {
+		return err
+	}
+	if bitbucketFormat && outputFile != "" {
+		return usageError("--output cannot be combined with --bitbucket, the reports are written to --bitbucket-dir")
+	}
+	if bitbucketUpload && !bitbucketFormat {
+		return usageError("--bitbucket-upload requires --bitbucket")
+	}
+	if fixDryRun && !fixBlocks {
+		return usageError("--dry-run requires --fix")
 	}
 
-	fmt.Printf("Scanning for duplicate Twig blocks in: %s\n", projectPath)
+	logInfo("Scanning for duplicate Twig blocks in: %s", projectPath)
 
 	// Find all *.html.twig files
-	twigFiles, err := findTwigFiles(projectPath)
+	twigFiles, err := twig.FindFiles(commandContext(cmd), projectPath)
 	if err != nil {
 		return fmt.Errorf("error finding Twig files: %w", err)
 	}
 
 	if len(twigFiles) == 0 {
-		fmt.Println("No *.html.twig files found in the specified directory.")
+		logInfo("No *.html.twig files found in the specified directory.")
+		switch {
+		case outputFormat != "":
+			return writeReport(outputFormat, twigblocksResult(nil, nil))
+		case outputFile != "":
+			return writeReport("json", twigblocksResult(nil, nil))
+		}
 		return nil
 	}
 
-	fmt.Printf("Found %d *.html.twig files\n", len(twigFiles))
+	logInfo("Found %d *.html.twig files", len(twigFiles), slog.Int("files", len(twigFiles)))
 
 	// Extract blocks from all files
-	allBlocks, err := extractBlocksFromFiles(twigFiles)
+	allBlocks, err := twig.ExtractBlocks(commandContext(cmd), twigFiles)
 	if err != nil {
 		return fmt.Errorf("error extracting blocks: %w", err)
 	}
 
-	fmt.Printf("Found %d total blocks\n", len(allBlocks))
+	logInfo("Found %d total blocks", len(allBlocks), slog.Int("blocks", len(allBlocks)))
 
 	// Find duplicates
-	duplicates := findDuplicateBlocks(allBlocks)
-
-	// Generate and output report
-	if err := generateReport(duplicates, twigFiles); err != nil {
-		return fmt.Errorf("error generating report: %w", err)
-	}
-
-	// Exit with error code if duplicates found (for CI/CD)
-	if len(duplicates) > 0 {
-		os.Exit(1)
-	}
-
-	return nil
-}
-
-// findTwigFiles recursively finds all *.html.twig files in the given directory
-func findTwigFiles(rootPath string) ([]string, error) {
-	var twigFiles []string
-
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip hidden directories and common build/cache directories
-		if info.IsDir() {
-			name := info.Name()
-			// Skip hidden directories, but not if it's the root path we're scanning
-			if path != rootPath && (strings.HasPrefix(name, ".") ||
-				name == "node_modules" ||
-				name == "vendor" ||
-				name == "var" ||
-				name == "cache" ||
-				name == "build") {
-				return filepath.SkipDir
-			}
-			return nil
+	duplicates := twig.FindDuplicates(allBlocks)
+
+	// Fix duplicates and re-scan so that the report only lists what is left
+	if fixBlocks && len(duplicates) > 0 {
+		opts := twigFixOptions{
+			Root:   projectPath,
+			DryRun: fixDryRun,
+			In:     bufio.NewReader(os.Stdin),
+			Prompt: os.Stderr,
 		}
-
-		// Check if file matches *.html.twig pattern
-		if strings.HasSuffix(strings.ToLower(path), ".html.twig") {
-			twigFiles = append(twigFiles, path)
+		if !reportToStdout() {
+			// With --format the report is the data on stdout
+			opts.Diff = os.Stdout
 		}
-
-		return nil
-	})
-
-	return twigFiles, err
-}
-
-// extractBlocksFromFiles extracts all Twig blocks from the given files
-func extractBlocksFromFiles(files []string) ([]TwigBlock, error) {
-	var allBlocks []TwigBlock
-
-	// Regex to match Twig block definitions
-	// Matches: {% block blockname %} or {% block blockname with optional attributes %}
-	// But not inside comments {# ... #}
-	blockRegex := regexp.MustCompile(`{%\s*block\s+([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:[^%]*)%}`)
-
-	for _, file := range files {
-		blocks, err := extractBlocksFromFile(file, blockRegex)
+		result, err := fixDuplicateBlocks(duplicates, opts)
 		if err != nil {
-			return nil, fmt.Errorf("error processing file %s: %w", file, err)
+			return fmt.Errorf("error fixing duplicate blocks: %w", err)
 		}
-		allBlocks = append(allBlocks, blocks...)
-	}
-
-	return allBlocks, nil
-}
-
-// extractBlocksFromFile extracts Twig blocks from a single file
-func extractBlocksFromFile(filename string, blockRegex *regexp.Regexp) ([]TwigBlock, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
 
-	var blocks []TwigBlock
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
-
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
-
-		// Skip lines that are comments
-		if strings.Contains(line, "{#") && strings.Contains(line, "#}") {
-			continue
-		}
+		if fixDryRun {
+			logInfo("DRY RUN: Would remove %d block definitions in %d files (%d skipped)",
+				result.BlocksRemoved, result.FilesChanged, result.Skipped,
+				slog.Int("removed", result.BlocksRemoved), slog.Int("files", result.FilesChanged), slog.Int("skipped", result.Skipped))
+		} else {
+			logInfo("Removed %d block definitions in %d files (%d skipped)",
+				result.BlocksRemoved, result.FilesChanged, result.Skipped,
+				slog.Int("removed", result.BlocksRemoved), slog.Int("files", result.FilesChanged), slog.Int("skipped", result.Skipped))
 
-		// Find all block matches in this line
-		matches := blockRegex.FindAllStringSubmatch(line, -1)
-		for _, match := range matches {
-			if len(match) >= 2 {
-				blockName := match[1]
-
-				// Extract the full block content for hashing
-				blockContent := extractBlockContent(file, line, blockName)
-
-				block := TwigBlock{
-					Name:    blockName,
-					File:    filename,
-					Line:    lineNumber,
-					Content: strings.TrimSpace(line),
-					Hash:    generateContentHash(blockContent),
-				}
-				blocks = append(blocks, block)
+			allBlocks, err = twig.ExtractBlocks(commandContext(cmd), twigFiles)
+			if err != nil {
+				return fmt.Errorf("error extracting blocks: %w", err)
 			}
+			duplicates = twig.FindDuplicates(allBlocks)
 		}
 	}
 
-	return blocks, scanner.Err()
-}
-
-// extractBlockContent attempts to extract the full content of a block for better duplicate detection
-func extractBlockContent(file *os.File, blockLine, blockName string) string {
-	// For now, just use the block declaration line
-	// In a more sophisticated implementation, we could parse the entire block content
-	// until the matching {% endblock %}
-	return strings.TrimSpace(blockLine)
-}
-
-// generateContentHash generates a hash of the block content for duplicate detection
-func generateContentHash(content string) string {
-	// Simple hash based on content (normalized)
-	normalized := strings.ReplaceAll(strings.TrimSpace(content), " ", "")
-	normalized = strings.ToLower(normalized)
-	return fmt.Sprintf("%x", len(normalized)) // Simple hash for now
-}
-
-// findDuplicateBlocks identifies blocks with the same name within the same file
-func findDuplicateBlocks(blocks []TwigBlock) []DuplicateGroup {
-	// Group blocks by file first, then by block name within each file
-	fileGroups := make(map[string][]TwigBlock)
-	for _, block := range blocks {
-		fileGroups[block.File] = append(fileGroups[block.File], block)
+	// Generate and output report
+	if err := generateReport(commandContext(cmd), duplicates, twigFiles); err != nil {
+		return fmt.Errorf("error generating report: %w", err)
 	}
 
-	var duplicates []DuplicateGroup
-
-	// Check each file for duplicate block names
-	for fileName, fileBlocks := range fileGroups {
-		// Group blocks by name within this file
-		blockGroups := make(map[string][]TwigBlock)
-		for _, block := range fileBlocks {
-			blockGroups[block.Name] = append(blockGroups[block.Name], block)
-		}
-
-		// Check for duplicates within this file
-		for blockName, groupBlocks := range blockGroups {
-			if len(groupBlocks) > 1 {
-				// Multiple blocks with same name in same file - this is a problem
-				duplicates = append(duplicates, DuplicateGroup{
-					BlockName: blockName,
-					Hash:      fmt.Sprintf("file-%s", fileName),
-					Count:     len(groupBlocks),
-					Files:     groupBlocks,
-				})
-			}
-		}
+	// Fail if duplicates reach the --fail-on threshold (for CI/CD)
+	severities := make([]string, len(duplicates))
+	for i, group := range duplicates {
+		severities[i] = group.Severity
 	}
-
-	// Sort duplicates by block name for consistent output
-	sort.Slice(duplicates, func(i, j int) bool {
-		if duplicates[i].BlockName == duplicates[j].BlockName {
-			return duplicates[i].Files[0].File < duplicates[j].Files[0].File
-		}
-		return duplicates[i].BlockName < duplicates[j].BlockName
-	})
-
-	return duplicates
+	return failOnFindings(failOnSeverity, severities, fmt.Sprintf("found %d duplicate block groups", len(duplicates)))
 }
 
 // generateReport generates and outputs the duplicate blocks report
-func generateReport(duplicates []DuplicateGroup, allFiles []string) error {
+func generateReport(ctx context.Context, duplicates []twig.DuplicateGroup, allFiles []string) error {
 	if bitbucketFormat {
-		return generateBitbucketReport(duplicates, allFiles)
+		return generateBitbucketReport(ctx, duplicates, allFiles)
+	}
+	if outputFormat != "" {
+		return writeReport(outputFormat, twigblocksResult(duplicates, allFiles))
 	}
 	return generateStandardReport(duplicates, allFiles)
 }
 
+// twigblocksResult returns the duplicate blocks as findings, one per definition
+func twigblocksResult(duplicates []twig.DuplicateGroup, allFiles []string) *report.Result {
+	result := newReportResult("twigblocks")
+	result.Root = projectPath
+	for _, file := range allFiles {
+		result.Files = append(result.Files, relativeSlashPath(projectPath, file))
+	}
+	for _, group := range duplicates {
+		for _, block := range group.Files {
+			result.Add(report.Finding{
+				Rule:     "duplicate-block",
+				Severity: group.Severity,
+				Message:  fmt.Sprintf("Duplicate block '%s' (appears %d times in this file)", group.BlockName, group.Count),
+				File:     relativeSlashPath(projectPath, block.File),
+				Line:     block.Line,
+				EndLine:  block.EndLine,
+				Snippet:  block.Content,
+			})
+		}
+	}
+	return result
+}
+
 // generateStandardReport generates a human-readable report
-func generateStandardReport(duplicates []DuplicateGroup, allFiles []string) error {
+func generateStandardReport(duplicates []twig.DuplicateGroup, allFiles []string) error {
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("TWIG BLOCK DUPLICATE ANALYSIS REPORT")
 	fmt.Println(strings.Repeat("=", 60))
@@ -321,6 +216,7 @@ func generateStandardReport(duplicates []DuplicateGroup, allFiles []string) erro
 		fmt.Printf("%d. Block: '%s'\n", i+1, group.BlockName)
 		fmt.Printf("   Hash: %s\n", group.Hash)
 		fmt.Printf("   Occurrences: %d\n", group.Count)
+		fmt.Printf("   Severity: %s\n", group.Severity)
 		fmt.Println("   Files:")
 
 		for _, block := range group.Files {
@@ -335,139 +231,10 @@ func generateStandardReport(duplicates []DuplicateGroup, allFiles []string) erro
 	fmt.Printf("Summary: %d duplicate groups found in %d files\n", len(duplicates), len(allFiles))
 	fmt.Println("Please review and consolidate duplicate blocks to avoid template conflicts.")
 
-	// Save to output file if specified
+	// Save the findings as JSON if --output is specified
 	if outputFile != "" {
-		return saveJSONReport(duplicates, allFiles)
-	}
-
-	return nil
-}
-
-// generateBitbucketReport generates a JUnit XML compatible test report for Bitbucket Pipelines
-func generateBitbucketReport(duplicates []DuplicateGroup, allFiles []string) error {
-	// Create test-reports directory
-	if err := os.MkdirAll("test-reports", 0755); err != nil {
-		return fmt.Errorf("error creating test-reports directory: %w", err)
-	}
-
-	// Generate JUnit XML format
-	junitXML := generateJUnitXML(duplicates, allFiles)
-
-	// Write to test-reports/twig-blocks-junit.xml
-	outputPath := "test-reports/twig-blocks-junit.xml"
-	if err := os.WriteFile(outputPath, []byte(junitXML), 0644); err != nil {
-		return fmt.Errorf("error writing JUnit XML report: %w", err)
-	}
-
-	fmt.Printf("Bitbucket test report generated: %s\n", outputPath)
-
-	// Also output summary to stdout
-	if len(duplicates) == 0 {
-		fmt.Println("PASSED: No duplicate Twig blocks found")
-	} else {
-		fmt.Printf("FAILED: Found %d duplicate block groups in %d files\n", len(duplicates), len(allFiles))
-	}
-
-	return nil
-}
-
-// generateJUnitXML creates JUnit XML format for Bitbucket test reporting
-func generateJUnitXML(duplicates []DuplicateGroup, allFiles []string) string {
-	totalTests := len(allFiles)
-	failures := len(duplicates)
-
-	xml := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<testsuite name="TwigBlockDuplicateAnalysis" tests="%d" failures="%d" errors="0" time="0">
-`, totalTests, failures)
-
-	// Add test cases for each file
-	fileStatus := make(map[string]bool)
-	for _, group := range duplicates {
-		for _, block := range group.Files {
-			fileStatus[block.File] = true // Mark as failed
-		}
-	}
-
-	// Generate test cases for all files
-	for _, file := range allFiles {
-		relPath, _ := filepath.Rel(projectPath, file)
-		testName := fmt.Sprintf("%s", relPath)
-
-		if fileStatus[file] {
-			// Failed test case
-			var failureDetails []string
-
-			// Get repository info for file links
-			repoOwner := os.Getenv("BITBUCKET_REPO_OWNER")
-			repoSlug := os.Getenv("BITBUCKET_REPO_SLUG")
-			commit := os.Getenv("BITBUCKET_COMMIT")
-
-			for _, group := range duplicates {
-				for _, block := range group.Files {
-					if block.File == file {
-						lineDetail := fmt.Sprintf("Line %d: Duplicate block '%s' (appears %d times in this file)",
-							block.Line, group.BlockName, group.Count)
-
-						// Add file link if we have repository info
-						if repoOwner != "" && repoSlug != "" && commit != "" {
-							fileLink := fmt.Sprintf("https://bitbucket.org/%s/%s/src/%s/%s#lines-%d",
-								repoOwner, repoSlug, commit, relPath, block.Line)
-							lineDetail += fmt.Sprintf("\n	View file: %s", fileLink)
-						}
-
-						failureDetails = append(failureDetails, lineDetail)
-					}
-				}
-			}
-
-			// Generate main file link
-			var mainFileLink string
-			if repoOwner != "" && repoSlug != "" && commit != "" {
-				mainFileLink = fmt.Sprintf("\n\nView file: https://bitbucket.org/%s/%s/src/%s/%s",
-					repoOwner, repoSlug, commit, relPath)
-			}
-
-			xml += fmt.Sprintf(`  <testcase classname="TwigBlocks" name="%s" time="0">
-    <failure message="Duplicate Twig blocks found" type="DuplicateBlockError">
-%s%s
-    </failure>
-  </testcase>
-`, testName, strings.Join(failureDetails, "\n"), mainFileLink)
-		} else {
-			// Passed test case
-			xml += fmt.Sprintf(`  <testcase classname="TwigBlocks" name="%s" time="0"/>
-`, testName)
-		}
-	}
-
-	xml += "</testsuite>"
-	return xml
-}
-
-// saveJSONReport saves the report in JSON format to the specified file
-func saveJSONReport(duplicates []DuplicateGroup, allFiles []string) error {
-	report := map[string]interface{}{
-		"summary": map[string]interface{}{
-			"files_scanned":    len(allFiles),
-			"duplicate_groups": len(duplicates),
-			"status":           map[bool]string{true: "PASSED", false: "FAILED"}[len(duplicates) == 0],
-		},
-		"duplicates": duplicates,
-		"files":      allFiles,
-	}
-
-	file, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("error creating output file: %w", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(report); err != nil {
-		return fmt.Errorf("error writing JSON report: %w", err)
+		return writeReport("json", twigblocksResult(duplicates, allFiles))
 	}
 
-	fmt.Printf("Report saved to: %s\n", outputFile)
 	return nil
 }
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+var twigblocksConflictsCmd = &cobra.Command{
+	Use:   "conflicts [PATH]",
+	Short: "Find storefront blocks overridden by several plugins",
+	Long: `Find storefront blocks that are overridden by more than one plugin, static plugin or app.
+
+When two extensions override the same @Storefront template block and at least one of
+them does not call parent(), the rendered result depends on the plugin load order.
+This command scans custom/plugins/*, custom/static-plugins/* and custom/apps/* in the
+Shopware project root and reports these conflicts.
+
+Bundle names are read from composer.json (extra.shopware-plugin-class), the plugin
+base class or the app manifest.
+
+A conflict has severity error when two or more extensions replace the block without
+calling parent(), and severity warning when only one of them replaces it.
+
+Examples:
+  wswcli twigblocks conflicts .                  # Scan the current Shopware project
+  wswcli twigblocks conflicts . --format json    # Output conflicts as JSON
+  wswcli twigblocks conflicts . --format sarif -o conflicts.sarif  # Write conflicts as SARIF`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runTwigBlocksConflicts,
+}
+
+func init() {
+	twigblocksCmd.AddCommand(twigblocksConflictsCmd)
+	supportsReport(twigblocksConflictsCmd)
+	supportsTextOutput(twigblocksConflictsCmd)
+}
+
+func runTwigBlocksConflicts(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return usageError("project path does not exist: %s", root)
+	}
+	if err := validateFailOn(failOnSeverity); err != nil {
+		return err
+	}
+
+	files, err := twig.FindExtensionFiles(commandContext(cmd), root)
+	if err != nil {
+		return fmt.Errorf("error finding Twig files: %w", err)
+	}
+
+	templates, err := twig.LoadTemplates(commandContext(cmd), root, files)
+	if err != nil {
+		return err
+	}
+
+	conflicts := twig.FindConflicts(templates)
+
+	if outputFormat != "" {
+		err = writeReport(outputFormat, conflictsResult(root, files, conflicts))
+	} else {
+		err = writeTextOutput(func(w io.Writer) error {
+			return writeConflictsText(w, root, conflicts, len(files))
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// Fail if conflicts reach the --fail-on threshold (for CI/CD)
+	severities := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		severities[i] = conflict.Severity
+	}
+	return failOnFindings(failOnSeverity, severities, fmt.Sprintf("found %d conflicting block overrides", len(conflicts)))
+}
+
+// writeConflictsText writes a human-readable conflict report
+func writeConflictsText(w io.Writer, root string, conflicts []twig.Conflict, filesScanned int) error {
+	fmt.Fprintln(w, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintln(w, "TWIG BLOCK OVERRIDE CONFLICT REPORT")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	if len(conflicts) == 0 {
+		fmt.Fprintln(w, "No conflicting block overrides found!")
+		fmt.Fprintf(w, "Scanned %d files successfully.\n", filesScanned)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Found %d conflicting block overrides:\n\n", len(conflicts))
+
+	for i, conflict := range conflicts {
+		fmt.Fprintf(w, "%d. Block: '%s' in %s (%s)\n", i+1, conflict.BlockName, conflict.Template, conflict.Severity)
+		for _, override := range conflict.Overrides {
+			parent := "replaces block"
+			if override.CallsParent {
+				parent = "calls parent()"
+			}
+			fmt.Fprintf(w, "     - %s: %s:%d (%s)\n", override.Bundle, relativeSlashPath(root, override.File), override.Line, parent)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "Summary: %d conflicting overrides found in %d files\n", len(conflicts), filesScanned)
+	fmt.Fprintln(w, "The result depends on plugin load order. Call parent() or merge the overrides.")
+
+	return nil
+}
+
+// conflictsResult returns the conflicts as findings, one per override
+func conflictsResult(root string, files []string, conflicts []twig.Conflict) *report.Result {
+	result := newReportResult("twigblocks conflicts")
+	result.Root = root
+	for _, file := range files {
+		result.Files = append(result.Files, relativeSlashPath(root, file))
+	}
+	for _, conflict := range conflicts {
+		bundles := make([]string, len(conflict.Overrides))
+		for i, override := range conflict.Overrides {
+			bundles[i] = override.Bundle
+		}
+		for _, override := range conflict.Overrides {
+			action := "replaces the block"
+			if override.CallsParent {
+				action = "calls parent()"
+			}
+			result.Add(report.Finding{
+				Rule:     "block-override-conflict",
+				Severity: conflict.Severity,
+				Message: fmt.Sprintf("Block '%s' of %s is overridden by %s, %s %s",
+					conflict.BlockName, conflict.Template, strings.Join(bundles, ", "), override.Bundle, action),
+				File: relativeSlashPath(root, override.File),
+				Line: override.Line,
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+func writeProjectFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for filePath, content := range files {
+		fullPath := filepath.Join(root, filePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConflictsReport(t *testing.T) {
+	root := "shop"
+	conflicts := []twig.Conflict{{
+		Template:  "@Storefront/storefront/base.html.twig",
+		BlockName: "base_header",
+		Severity:  SeverityError,
+		Overrides: []twig.Override{
+			{Bundle: "PluginA", File: filepath.Join(root, "custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig"), Line: 2},
+			{Bundle: "PluginB", File: filepath.Join(root, "custom/plugins/PluginB/src/Resources/views/storefront/base.html.twig"), Line: 2, CallsParent: true},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := writeConflictsText(&buf, root, conflicts, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "PluginA: custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig:2 (replaces block)") {
+		t.Errorf("Unexpected text report:\n%s", buf.String())
+	}
+
+	result := conflictsResult(root, nil, conflicts)
+	if len(result.Findings) != 2 {
+		t.Fatalf("Expected a finding per override, got %+v", result.Findings)
+	}
+	finding := result.Findings[0]
+	if finding.Rule != "block-override-conflict" || finding.Severity != SeverityError || finding.File != "custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig" || finding.Line != 2 {
+		t.Errorf("Unexpected finding: %+v", finding)
+	}
+	if !strings.Contains(finding.Message, "PluginA, PluginB") || !strings.Contains(result.Findings[1].Message, "PluginB calls parent()") {
+		t.Errorf("Unexpected messages: %q, %q", finding.Message, result.Findings[1].Message)
+	}
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+// twigFixOptions controls how duplicate blocks are fixed
+type twigFixOptions struct {
+	Root   string        // Scanned project path; files outside of it are never touched
+	DryRun bool          // Print a unified diff instead of writing files
+	In     *bufio.Reader // Answers for the interactive chooser
+	Prompt io.Writer     // Chooser prompts, stderr so they never mix with a report on stdout
+	Diff   io.Writer     // Unified diffs of a dry run, nil to leave them out
+}
+
+// fixDuplicateBlocks removes redundant block definitions from every file that
+// contains duplicates, see twig.FixDuplicates. Conflicting definitions are
+// resolved with an interactive chooser.
+func fixDuplicateBlocks(duplicates []twig.DuplicateGroup, opts twigFixOptions) (twig.FixResult, error) {
+	return twig.FixDuplicates(duplicates, twig.FixOptions{
+		Root:   opts.Root,
+		DryRun: opts.DryRun,
+		Choose: func(file, content, name string, blocks []twig.Block) (int, bool) {
+			return chooseBlockDefinition(file, content, name, blocks, opts)
+		},
+	}, func(fix twig.FileFix) {
+		relPath := relativeSlashPath(opts.Root, fix.File)
+		switch {
+		case fix.Outside:
+			logWarn("Skipping %s: file is outside of %s", fix.File, opts.Root, slog.String("file", fix.File))
+		case fix.Removed == 0:
+			// Every duplicate of the file was skipped
+		case opts.DryRun:
+			if opts.Diff != nil {
+				fmt.Fprint(opts.Diff, unidiff.Unified("a/"+relPath, "b/"+relPath, fix.Original, fix.Fixed, 3))
+			}
+		default:
+			logInfo("Fixed %s: removed %d block definitions", relPath, fix.Removed, slog.String("file", relPath), slog.Int("removed", fix.Removed))
+		}
+	})
+}
+
+// chooseBlockDefinition prompts for the definition to keep among conflicting blocks
+func chooseBlockDefinition(file, content, name string, blocks []twig.Block, opts twigFixOptions) (int, bool) {
+	fmt.Fprintf(opts.Prompt, "\nConflicting definitions of block '%s' in %s:\n", name, file)
+	for i, block := range blocks {
+		fmt.Fprintf(opts.Prompt, "  [%d] lines %d-%d:\n", i+1, block.Line, block.EndLine)
+		for _, line := range previewLines(content[block.Start:block.End], 3) {
+			fmt.Fprintf(opts.Prompt, "      %s\n", line)
+		}
+	}
+
+	for {
+		fmt.Fprintf(opts.Prompt, "Keep which definition? [1-%d, s=skip]: ", len(blocks))
+		if opts.In == nil {
+			fmt.Fprintln(opts.Prompt)
+			fmt.Fprintln(opts.Prompt, "skipped (no input)")
+			return 0, false
+		}
+
+		answer, err := opts.In.ReadString('\n')
+		if err != nil {
+			// The answer was not terminated by a newline, so end the prompt line
+			fmt.Fprintln(opts.Prompt)
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer == "s" || answer == "skip" {
+			return 0, false
+		}
+
+		if choice, convErr := strconv.Atoi(answer); convErr == nil && choice >= 1 && choice <= len(blocks) {
+			return choice - 1, true
+		}
+		if err != nil {
+			// End of input, e.g. when stdin is not a terminal
+			fmt.Fprintln(opts.Prompt, "skipped (no input)")
+			return 0, false
+		}
+		fmt.Fprintf(opts.Prompt, "Please enter a number between 1 and %d or 's' to skip\n", len(blocks))
+	}
+}
+
+// previewLines returns up to max trimmed lines of a block for the chooser
+func previewLines(source string, max int) []string {
+	lines := strings.Split(source, "\n")
+	var preview []string
+	for _, line := range lines {
+		if len(preview) == max {
+			preview = append(preview, "...")
+			break
+		}
+		preview = append(preview, strings.TrimRight(line, " \t\r"))
+	}
+	return preview
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+func TestFixDuplicateBlocksExactDuplicates(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "page.html.twig")
+
+	content := `{% block title %}Title{% endblock %}
+{% block sidebar %}
+    Sidebar
+{% endblock %}
+{% block title %}Title{% endblock %}
+`
+	if err := os.WriteFile(testFile, []byte(content), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := twig.ExtractBlocks(context.Background(), []string{testFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	result, err := fixDuplicateBlocks(twig.FindDuplicates(blocks), twigFixOptions{Root: tempDir, Prompt: &out})
+	if err != nil {
+		t.Fatalf("fixDuplicateBlocks failed: %v", err)
+	}
+
+	if result.BlocksRemoved != 1 || result.FilesChanged != 1 {
+		t.Errorf("Expected 1 block removed in 1 file, got %+v", result)
+	}
+
+	after, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{% block title %}Title{% endblock %}
+{% block sidebar %}
+    Sidebar
+{% endblock %}
+`
+	if string(after) != expected {
+		t.Errorf("Unexpected file content after fix:\n%s", after)
+	}
+
+	// File permissions should be preserved
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Expected file mode 0640 to be preserved, got %o", info.Mode().Perm())
+	}
+}
+
+func TestFixDuplicateBlocksDryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "page.html.twig")
+
+	content := "{% block title %}Title{% endblock %}\n{% block title %}Title{% endblock %}\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := twig.ExtractBlocks(context.Background(), []string{testFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if _, err := fixDuplicateBlocks(twig.FindDuplicates(blocks), twigFixOptions{Root: tempDir, DryRun: true, Diff: &out}); err != nil {
+		t.Fatalf("fixDuplicateBlocks failed: %v", err)
+	}
+
+	diff := out.String()
+	if !strings.Contains(diff, "--- a/page.html.twig") || !strings.Contains(diff, "+++ b/page.html.twig") {
+		t.Errorf("Expected diff headers with relative paths, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "-{% block title %}Title{% endblock %}") {
+		t.Errorf("Expected removed duplicate in diff, got:\n%s", diff)
+	}
+
+	after, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != content {
+		t.Error("File content should not change in dry-run mode")
+	}
+}
+
+func TestFixDuplicateBlocksConflictChooser(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "page.html.twig")
+
+	content := "{% block title %}First{% endblock %}\n{% block title %}Second{% endblock %}\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks, err := twig.ExtractBlocks(context.Background(), []string{testFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicates := twig.FindDuplicates(blocks)
+
+	// Skipping leaves the file untouched
+	var out bytes.Buffer
+	result, err := fixDuplicateBlocks(duplicates, twigFixOptions{
+		Root:   tempDir,
+		In:     bufio.NewReader(strings.NewReader("s\n")),
+		Prompt: &out,
+	})
+	if err != nil {
+		t.Fatalf("fixDuplicateBlocks failed: %v", err)
+	}
+	if result.Skipped != 1 || result.BlocksRemoved != 0 {
+		t.Errorf("Expected skipped conflict, got %+v", result)
+	}
+	if !strings.Contains(out.String(), "Conflicting definitions of block 'title'") {
+		t.Errorf("Expected chooser prompt, got:\n%s", out.String())
+	}
+
+	// Without input the prompt line is ended and the conflict is skipped
+	out.Reset()
+	result, err = fixDuplicateBlocks(duplicates, twigFixOptions{
+		Root:   tempDir,
+		DryRun: true,
+		In:     bufio.NewReader(strings.NewReader("")),
+		Prompt: &out,
+	})
+	if err != nil {
+		t.Fatalf("fixDuplicateBlocks failed: %v", err)
+	}
+	if result.Skipped != 1 {
+		t.Errorf("Expected skipped conflict at end of input, got %+v", result)
+	}
+	if !strings.Contains(out.String(), "[1-2, s=skip]: \nskipped (no input)\n") {
+		t.Errorf("Expected the prompt to end before the skip message, got:\n%s", out.String())
+	}
+
+	// Invalid input is asked again, then the second definition is kept
+	out.Reset()
+	if _, err := fixDuplicateBlocks(duplicates, twigFixOptions{
+		Root:   tempDir,
+		In:     bufio.NewReader(strings.NewReader("7\n2\n")),
+		Prompt: &out,
+	}); err != nil {
+		t.Fatalf("fixDuplicateBlocks failed: %v", err)
+	}
+
+	after, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != "{% block title %}Second{% endblock %}\n" {
+		t.Errorf("Expected only the second definition to remain, got:\n%s", after)
+	}
+}
+
+func TestFixDuplicateBlocksOutsideRoot(t *testing.T) {
+	projectDir := t.TempDir()
+	outsideDir := t.TempDir()
+
+	outsideFile := filepath.Join(outsideDir, "outside.html.twig")
+	content := "{% block a %}{% endblock %}\n{% block a %}{% endblock %}\n"
+	if err := os.WriteFile(outsideFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	linkedFile := filepath.Join(projectDir, "linked.html.twig")
+	if err := os.Symlink(outsideFile, linkedFile); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	blocks, err := twig.ExtractBlocks(context.Background(), []string{linkedFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	result, err := fixDuplicateBlocks(twig.FindDuplicates(blocks), twigFixOptions{Root: projectDir, Prompt: &out})
+	if err != nil {
+		t.Fatalf("fixDuplicateBlocks failed: %v", err)
+	}
+	if result.Skipped != 1 || result.FilesChanged != 0 {
+		t.Errorf("Expected symlinked file outside the project to be skipped, got %+v", result)
+	}
+
+	after, err := os.ReadFile(outsideFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != content {
+		t.Error("File outside the project path must not be modified")
+	}
+}
+
+func TestTwigBlocksFixDryRunWithFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	writeProjectFiles(t, tempDir, map[string]string{
+		"page.html.twig": "{% block title %}Title{% endblock %}\n{% block title %}Title{% endblock %}\n",
+	})
+
+	defer func(fix, dry bool, format, file, failOn string) {
+		fixBlocks, fixDryRun, outputFormat, outputFile, failOnSeverity = fix, dry, format, file, failOn
+	}(fixBlocks, fixDryRun, outputFormat, outputFile, failOnSeverity)
+	fixBlocks, fixDryRun, outputFormat, outputFile, failOnSeverity = true, true, "json", "", FailOnNever
+
+	// The JSON report is the only output on stdout, the diff is left out
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	runErr := runTwigBlocks(twigblocksCmd, []string{tempDir})
+	os.Stdout = stdout
+	w.Close()
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result report.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Expected only the JSON report on stdout: %v", err)
+	}
+	if len(result.Findings) != 2 {
+		t.Errorf("Expected the duplicates of the dry run in the report, got %+v", result.Findings)
+	}
+}
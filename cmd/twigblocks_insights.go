@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/bitbucket"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+// twigblocksReportID is the external ID of the Code Insights report of twigblocks
+const twigblocksReportID = "wswcli-twigblocks"
+
+var (
+	bitbucketDir    string
+	bitbucketUpload bool
+
+	// bitbucketAPIURL is the API root requests are sent to and bitbucketProxyURL the
+	// Pipelines proxy that authenticates them. Tests replace both.
+	bitbucketAPIURL   = bitbucket.APIURL
+	bitbucketProxyURL = bitbucket.PipelinesProxyURL
+)
+
+// generateBitbucketReport generates a JUnit XML compatible test report and a Code
+// Insights report with annotations for Bitbucket Pipelines, and uploads the Code
+// Insights report with --bitbucket-upload
+func generateBitbucketReport(ctx context.Context, duplicates []twig.DuplicateGroup, allFiles []string) error {
+	if err := os.MkdirAll(bitbucketDir, 0755); err != nil {
+		return fmt.Errorf("error creating %s directory: %w", bitbucketDir, err)
+	}
+
+	outputPath := filepath.Join(bitbucketDir, "twig-blocks-junit.xml")
+	if err := writeReportFile(outputPath, "junit", twigblocksResult(duplicates, allFiles)); err != nil {
+		return err
+	}
+
+	logInfo("Bitbucket test report generated: %s", outputPath, slog.String("path", outputPath))
+
+	failed := false
+	for _, group := range duplicates {
+		failed = failed || severityMeetsThreshold(group.Severity, failOnSeverity)
+	}
+	insights := twig.Insights(duplicates, projectPath, len(allFiles), failed)
+	if dropped := insights.Truncate(); dropped > 0 {
+		logWarn("Code Insights reports take at most %d annotations, %d of %d findings are not annotated",
+			bitbucket.MaxAnnotations, dropped, bitbucket.MaxAnnotations+dropped)
+	}
+	reportPath, annotationPaths, err := bitbucket.WriteFiles(bitbucketDir, "twig-blocks", insights)
+	if err != nil {
+		return err
+	}
+	if len(annotationPaths) == 0 {
+		logInfo("Code Insights report generated: %s (no annotations)", reportPath)
+	} else {
+		logInfo("Code Insights report generated: %s (%d annotations in %s)", reportPath, len(insights.Annotations), strings.Join(annotationPaths, ", "))
+	}
+
+	if bitbucketUpload {
+		repo, commit, ok := bitbucket.PipelinesCommit()
+		if !ok {
+			return usageError("--bitbucket-upload needs BITBUCKET_REPO_FULL_NAME and BITBUCKET_COMMIT, it only works in Bitbucket Pipelines")
+		}
+		uploader, err := bitbucket.NewUploader(bitbucketAPIURL, bitbucketProxyURL, repo, commit)
+		if err != nil {
+			return err
+		}
+		if err := uploader.Upload(ctx, twigblocksReportID, insights); err != nil {
+			return &ExitError{Code: ExitIO, Err: err}
+		}
+		logInfo("Code Insights report uploaded for commit %s", uploader.Commit, slog.String("commit", uploader.Commit))
+	}
+
+	// Also log a summary
+	if len(duplicates) == 0 {
+		logInfo("PASSED: No duplicate Twig blocks found")
+	} else {
+		logInfo("FAILED: Found %d duplicate block groups in %d files", len(duplicates), len(allFiles),
+			slog.Int("duplicate_groups", len(duplicates)), slog.Int("files", len(allFiles)))
+	}
+
+	return nil
+}
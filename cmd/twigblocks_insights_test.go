@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/bitbucket"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+func TestInsightsUpload(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	annotations := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/annotations") {
+			var batch []bitbucket.Annotation
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+				t.Errorf("Invalid annotations batch: %v", err)
+			}
+			annotations += len(batch)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	defer func(api, proxy, dir string, upload bool) {
+		bitbucketAPIURL, bitbucketProxyURL, bitbucketDir, bitbucketUpload = api, proxy, dir, upload
+	}(bitbucketAPIURL, bitbucketProxyURL, bitbucketDir, bitbucketUpload)
+	bitbucketAPIURL, bitbucketProxyURL, bitbucketDir, bitbucketUpload = server.URL, "", t.TempDir(), true
+
+	var files []twig.Block
+	for i := 0; i < 250; i++ {
+		files = append(files, twig.Block{Name: "content", File: "page.html.twig", Line: i + 1})
+	}
+	duplicates := []twig.DuplicateGroup{{BlockName: "content", Count: len(files), Severity: SeverityError, Files: files}}
+
+	// Without the Pipelines environment the upload is a usage error
+	t.Setenv("BITBUCKET_REPO_FULL_NAME", "")
+	t.Setenv("BITBUCKET_COMMIT", "")
+	if err := generateBitbucketReport(context.Background(), duplicates, nil); exitCodeForError(err) != ExitUsage {
+		t.Errorf("Expected a usage error without BITBUCKET_COMMIT, got %v", err)
+	}
+
+	t.Setenv("BITBUCKET_REPO_FULL_NAME", "acme/shop")
+	t.Setenv("BITBUCKET_COMMIT", "abc123")
+	if err := generateBitbucketReport(context.Background(), duplicates, nil); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := "/repositories/acme/shop/commit/abc123/reports/" + twigblocksReportID
+	expected := []string{"PUT " + reportPath}
+	for i := 0; i < 3; i++ {
+		expected = append(expected, "POST "+reportPath+"/annotations")
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) || annotations != 250 {
+		t.Errorf("Expected %v with 250 annotations, got %v with %d", expected, requests, annotations)
+	}
+
+	// A canceled run stops the upload
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := generateBitbucketReport(ctx, duplicates, nil); exitCodeForError(err) != ExitIO {
+		t.Errorf("Expected an I/O error for a canceled upload, got %v", err)
+	}
+
+	// A failing API fails the run with an I/O error
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if err := generateBitbucketReport(context.Background(), duplicates, nil); exitCodeForError(err) != ExitIO {
+		t.Errorf("Expected an I/O error for a failing upload, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+var (
+	listFormat     string
+	listOutputFile string
+)
+
+var twigblocksListCmd = &cobra.Command{
+	Use:   "list [PATH]",
+	Short: "List every Twig block per template",
+	Long: `List every block defined in *.html.twig files with its parent template,
+line span, nesting depth, whether it calls parent() and which plugins or themes
+override the same block of the same template.
+
+Supported formats: text, json, csv, markdown
+
+Examples:
+  wswcli twigblocks list .                          # Human-readable inventory
+  wswcli twigblocks list . --format csv -o blocks.csv
+  wswcli twigblocks list custom/plugins --format markdown`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runTwigBlocksList,
+}
+
+func init() {
+	twigblocksCmd.AddCommand(twigblocksListCmd)
+	twigblocksListCmd.Flags().StringVarP(&listFormat, "format", "f", "text", "Output format (text, json, csv, markdown)")
+	twigblocksListCmd.Flags().StringVarP(&listOutputFile, "output", "o", "", "Output file for the inventory (default: stdout)")
+}
+
+func runTwigBlocksList(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return usageError("project path does not exist: %s", root)
+	}
+
+	writer, ok := inventoryWriters[listFormat]
+	if !ok {
+		return usageError("unsupported format: %s (use text, json, csv or markdown)", listFormat)
+	}
+
+	twigFiles, err := twig.FindFiles(commandContext(cmd), root)
+	if err != nil {
+		return fmt.Errorf("error finding Twig files: %w", err)
+	}
+
+	templates, err := twig.LoadTemplates(commandContext(cmd), root, twigFiles)
+	if err != nil {
+		return err
+	}
+
+	entries := twig.BuildInventory(root, templates)
+
+	var out io.Writer = os.Stdout
+	if listOutputFile != "" {
+		file, err := os.Create(listOutputFile)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writer(out, entries); err != nil {
+		return fmt.Errorf("error writing inventory: %w", err)
+	}
+
+	if listOutputFile != "" {
+		logInfo("Inventory of %d blocks saved to: %s", len(entries), listOutputFile, slog.Int("blocks", len(entries)), slog.String("path", listOutputFile))
+	}
+	return nil
+}
+
+// inventoryWriters maps the supported formats to their writers
+var inventoryWriters = map[string]func(io.Writer, []twig.InventoryEntry) error{
+	"text":     writeInventoryText,
+	"json":     writeInventoryJSON,
+	"csv":      writeInventoryCSV,
+	"markdown": writeInventoryMarkdown,
+}
+
+// writeInventoryText writes a human-readable inventory grouped by template
+func writeInventoryText(w io.Writer, entries []twig.InventoryEntry) error {
+	currentTemplate := ""
+	for _, entry := range entries {
+		if entry.Template != currentTemplate {
+			currentTemplate = entry.Template
+			fmt.Fprintf(w, "\n%s (%s)\n", entry.Template, entry.Bundle)
+			if entry.ParentTemplate != "" {
+				fmt.Fprintf(w, "  extends %s\n", entry.ParentTemplate)
+			}
+		}
+
+		line := fmt.Sprintf("  %s%s [%d-%d]", strings.Repeat("  ", entry.Depth), entry.Block, entry.Line, entry.EndLine)
+		if entry.CallsParent {
+			line += " parent()"
+		}
+		if len(entry.OverriddenBy) > 0 {
+			line += " overridden by: " + strings.Join(entry.OverriddenBy, ", ")
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintf(w, "\nTotal: %d blocks\n", len(entries))
+	return nil
+}
+
+// writeInventoryJSON writes the inventory as a JSON array
+func writeInventoryJSON(w io.Writer, entries []twig.InventoryEntry) error {
+	if entries == nil {
+		entries = []twig.InventoryEntry{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// writeInventoryCSV writes the inventory as CSV with a header row
+func writeInventoryCSV(w io.Writer, entries []twig.InventoryEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"template", "block", "parent_template", "line", "end_line", "depth", "calls_parent", "bundle", "overridden_by"}); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		record := []string{
+			entry.Template,
+			entry.Block,
+			entry.ParentTemplate,
+			strconv.Itoa(entry.Line),
+			strconv.Itoa(entry.EndLine),
+			strconv.Itoa(entry.Depth),
+			strconv.FormatBool(entry.CallsParent),
+			entry.Bundle,
+			strings.Join(entry.OverriddenBy, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeInventoryMarkdown writes one Markdown table per template
+func writeInventoryMarkdown(w io.Writer, entries []twig.InventoryEntry) error {
+	fmt.Fprintln(w, "# Twig Block Inventory")
+
+	currentTemplate := ""
+	for _, entry := range entries {
+		if entry.Template != currentTemplate {
+			currentTemplate = entry.Template
+			fmt.Fprintf(w, "\n## `%s`\n\n", entry.Template)
+			fmt.Fprintf(w, "Bundle: %s", entry.Bundle)
+			if entry.ParentTemplate != "" {
+				fmt.Fprintf(w, " · Extends: `%s`", entry.ParentTemplate)
+			}
+			fmt.Fprint(w, "\n\n| Block | Lines | Depth | parent() | Overridden by |\n")
+			fmt.Fprint(w, "|-------|-------|-------|----------|---------------|\n")
+		}
+
+		callsParent := "no"
+		if entry.CallsParent {
+			callsParent = "yes"
+		}
+		fmt.Fprintf(w, "| `%s` | %d-%d | %d | %s | %s |\n",
+			entry.Block, entry.Line, entry.EndLine, entry.Depth, callsParent, strings.Join(entry.OverriddenBy, ", "))
+	}
+
+	return nil
+}
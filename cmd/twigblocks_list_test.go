@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+func TestInventoryWriters(t *testing.T) {
+	entries := []twig.InventoryEntry{
+		{
+			Template:       "custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig",
+			Block:          "base_header",
+			ParentTemplate: "@Storefront/storefront/base.html.twig",
+			Line:           3,
+			EndLine:        8,
+			CallsParent:    true,
+			Bundle:         "PluginA",
+			OverriddenBy:   []string{"PluginB", "PluginC"},
+		},
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeInventoryJSON(&buf, entries); err != nil {
+			t.Fatal(err)
+		}
+		var decoded []twig.InventoryEntry
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		if len(decoded) != 1 || decoded[0].Block != "base_header" || !decoded[0].CallsParent {
+			t.Errorf("Unexpected decoded inventory: %+v", decoded)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeInventoryCSV(&buf, entries); err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Invalid CSV: %v", err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("Expected header and 1 row, got %d rows", len(records))
+		}
+		if records[1][1] != "base_header" || records[1][8] != "PluginB;PluginC" {
+			t.Errorf("Unexpected CSV row: %v", records[1])
+		}
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeInventoryMarkdown(&buf, entries); err != nil {
+			t.Fatal(err)
+		}
+		output := buf.String()
+		if !strings.Contains(output, "| `base_header` | 3-8 | 0 | yes | PluginB, PluginC |") {
+			t.Errorf("Unexpected Markdown output:\n%s", output)
+		}
+	})
+}
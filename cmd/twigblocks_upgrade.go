@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+var (
+	upgradeFrom string
+	upgradeTo   string
+)
+
+var twigblocksUpgradeCmd = &cobra.Command{
+	Use:   "upgrade-check [PATH] --from <old storefront dir> --to <new storefront dir>",
+	Short: "Check which overridden storefront blocks changed between two Shopware versions",
+	Long: `Compare storefront block bodies between two local storefront versions and report,
+for every @Storefront block the project overrides, whether it was removed, renamed
+(detected by content similarity) or changed upstream. Changed and renamed blocks
+include a unified diff of the upstream change.
+
+Both storefront directories are local paths, e.g. two checkouts of
+vendor/shopware/storefront. Templates are looked up below Resources/views.
+
+Examples:
+  wswcli twigblocks upgrade-check . --from /tmp/storefront-6.5 --to vendor/shopware/storefront
+  wswcli twigblocks upgrade-check custom/plugins --from old/ --to new/ --format json
+
+Removed and renamed blocks are reported with severity error because the override
+no longer applies, changed blocks with severity warning. Use --fail-on warning to
+also fail on upstream changes.`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: runTwigBlocksUpgradeCheck,
+}
+
+func init() {
+	twigblocksCmd.AddCommand(twigblocksUpgradeCmd)
+	twigblocksUpgradeCmd.Flags().StringVar(&upgradeFrom, "from", "", "Storefront directory of the current (old) version")
+	twigblocksUpgradeCmd.Flags().StringVar(&upgradeTo, "to", "", "Storefront directory of the target (new) version")
+	supportsReport(twigblocksUpgradeCmd)
+	supportsTextOutput(twigblocksUpgradeCmd)
+	_ = twigblocksUpgradeCmd.MarkFlagRequired("from")
+	_ = twigblocksUpgradeCmd.MarkFlagRequired("to")
+}
+
+func runTwigBlocksUpgradeCheck(cmd *cobra.Command, args []string) error {
+	root := "."
+	if len(args) > 0 {
+		root = args[0]
+	}
+
+	for _, dir := range []string{root, upgradeFrom, upgradeTo} {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return usageError("directory does not exist: %s", dir)
+		}
+	}
+	if err := validateFailOn(failOnSeverity); err != nil {
+		return err
+	}
+
+	twigFiles, err := twig.FindFiles(commandContext(cmd), root)
+	if err != nil {
+		return fmt.Errorf("error finding Twig files: %w", err)
+	}
+
+	templates, err := twig.LoadTemplates(commandContext(cmd), root, twigFiles)
+	if err != nil {
+		return err
+	}
+
+	results := twig.CheckUpgrades(root, templates, twig.NewStorefrontTree(upgradeFrom), twig.NewStorefrontTree(upgradeTo))
+
+	if outputFormat != "" {
+		err = writeReport(outputFormat, upgradeResult(root, twigFiles, results))
+	} else {
+		err = writeTextOutput(func(w io.Writer) error {
+			return writeUpgradeText(w, results)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// Fail if overridden blocks reach the --fail-on threshold (for CI/CD)
+	severities := make([]string, len(results))
+	for i, result := range results {
+		severities[i] = result.Severity
+	}
+	return failOnFindings(failOnSeverity, severities, "overridden storefront blocks changed between versions")
+}
+
+// writeUpgradeText writes a human-readable upgrade report
+func writeUpgradeText(w io.Writer, results []twig.BlockUpgrade) error {
+	fmt.Fprintln(w, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintln(w, "STOREFRONT BLOCK UPGRADE CHECK")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+		if result.Status == twig.UpgradeUnchanged {
+			continue
+		}
+
+		label := fmt.Sprintf("[%s] %s#%s", strings.ToUpper(result.Status), result.Template, result.BlockName)
+		if result.Status == twig.UpgradeRenamed {
+			label += fmt.Sprintf(" -> %s (similarity %.0f%%)", result.NewName, result.Similarity*100)
+		}
+		label += fmt.Sprintf(" (%s)", result.Severity)
+		fmt.Fprintln(w, label)
+		for _, location := range result.OverriddenIn {
+			fmt.Fprintf(w, "  overridden in %s:%d\n", location.File, location.Line)
+		}
+		if result.Diff != "" {
+			for _, line := range strings.Split(strings.TrimRight(result.Diff, "\n"), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "Summary: %d overridden blocks: %d unchanged, %d changed, %d renamed, %d removed, %d not found in old version\n",
+		len(results), counts[twig.UpgradeUnchanged], counts[twig.UpgradeChanged], counts[twig.UpgradeRenamed], counts[twig.UpgradeRemoved], counts[twig.UpgradeUnknown])
+
+	return nil
+}
+
+// upgradeResult returns the changed blocks as findings, one per override.
+// Unchanged blocks are left out.
+func upgradeResult(root string, files []string, results []twig.BlockUpgrade) *report.Result {
+	result := newReportResult("twigblocks upgrade-check")
+	result.Root = root
+	for _, file := range files {
+		result.Files = append(result.Files, relativeSlashPath(root, file))
+	}
+	for _, block := range results {
+		if block.Status == twig.UpgradeUnchanged {
+			continue
+		}
+
+		var message string
+		switch block.Status {
+		case twig.UpgradeRemoved:
+			message = fmt.Sprintf("Overridden block '%s' of %s was removed upstream", block.BlockName, block.Template)
+		case twig.UpgradeRenamed:
+			message = fmt.Sprintf("Overridden block '%s' of %s was renamed to '%s' upstream (similarity %.0f%%)", block.BlockName, block.Template, block.NewName, block.Similarity*100)
+		case twig.UpgradeChanged:
+			message = fmt.Sprintf("Overridden block '%s' of %s changed upstream", block.BlockName, block.Template)
+		default:
+			message = fmt.Sprintf("Overridden block '%s' of %s was not found in the old version", block.BlockName, block.Template)
+		}
+
+		for _, location := range block.OverriddenIn {
+			result.Add(report.Finding{
+				Rule:     "block-" + block.Status,
+				Severity: block.Severity,
+				Message:  message,
+				File:     location.File,
+				Line:     location.Line,
+				Snippet:  block.Diff,
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/twig"
+)
+
+func TestUpgradeReport(t *testing.T) {
+	results := []twig.BlockUpgrade{
+		{Template: "@Storefront/storefront/base.html.twig", BlockName: "base_footer", Status: twig.UpgradeChanged, Severity: SeverityWarning,
+			OverriddenIn: []twig.BlockLocation{{File: "custom/plugins/PluginA/base.html.twig", Line: 3}}, Diff: "-old\n+new\n"},
+		{Template: "@Storefront/storefront/base.html.twig", BlockName: "base_navigation", Status: twig.UpgradeRemoved, Severity: SeverityError,
+			OverriddenIn: []twig.BlockLocation{{File: "custom/plugins/PluginA/base.html.twig", Line: 4}}},
+		{Template: "@Storefront/storefront/page/index.html.twig", BlockName: "page_content", Status: twig.UpgradeUnchanged, Severity: SeverityInfo,
+			OverriddenIn: []twig.BlockLocation{{File: "custom/plugins/PluginB/index.html.twig", Line: 2}}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeUpgradeText(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	if !strings.Contains(text, "[REMOVED] @Storefront/storefront/base.html.twig#base_navigation") || !strings.Contains(text, "overridden in custom/plugins/PluginA/base.html.twig:4") {
+		t.Errorf("Expected removed block in report:\n%s", text)
+	}
+	if !strings.Contains(text, "1 unchanged, 1 changed, 0 renamed, 1 removed, 0 not found in old version") {
+		t.Errorf("Unexpected summary in report:\n%s", text)
+	}
+
+	result := upgradeResult(".", nil, results)
+	if len(result.Findings) != 2 {
+		t.Fatalf("Expected findings for the changed and removed block, got %+v", result.Findings)
+	}
+	if finding := result.Findings[0]; finding.Rule != "block-changed" || finding.Line != 3 || finding.Snippet != "-old\n+new\n" {
+		t.Errorf("Unexpected finding: %+v", finding)
+	}
+}
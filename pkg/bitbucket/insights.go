@@ -0,0 +1,114 @@
+// Package bitbucket writes Bitbucket Code Insights reports with their
+// annotations and uploads them from Bitbucket Pipelines.
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+// Code Insights limits
+const (
+	MaxAnnotations  = 1000 // Annotations per report
+	AnnotationBatch = 100  // Annotations per request and annotations file
+)
+
+// Report represents a Bitbucket Code Insights report
+type Report struct {
+	Title       string       `json:"title"`
+	Details     string       `json:"details"`
+	ReportType  string       `json:"report_type"` // BUG, SECURITY, COVERAGE or TEST
+	Reporter    string       `json:"reporter"`
+	Result      string       `json:"result"` // PASSED or FAILED
+	Data        []Data       `json:"data"`
+	Annotations []Annotation `json:"-"` // Written and uploaded separately
+}
+
+// Data is a value shown in the report summary
+type Data struct {
+	Title string      `json:"title"`
+	Type  string      `json:"type"` // NUMBER, TEXT, BOOLEAN, LINK, ...
+	Value interface{} `json:"value"`
+}
+
+// Annotation is a finding of a report shown on a line of a file
+type Annotation struct {
+	ExternalID string `json:"external_id"`
+	Path       string `json:"path"`
+	Line       int    `json:"line"`
+	Summary    string `json:"summary"`
+	Details    string `json:"details,omitempty"`
+	Type       string `json:"annotation_type"` // BUG, CODE_SMELL or VULNERABILITY
+	Severity   string `json:"severity"`        // LOW, MEDIUM, HIGH or CRITICAL
+	Result     string `json:"result"`
+}
+
+// Truncate drops the annotations beyond MaxAnnotations, notes it in the details
+// and returns the number of dropped annotations
+func (r *Report) Truncate() int {
+	if len(r.Annotations) <= MaxAnnotations {
+		return 0
+	}
+	dropped := len(r.Annotations) - MaxAnnotations
+	r.Details += fmt.Sprintf(" Only the first %d of %d findings are annotated.", MaxAnnotations, len(r.Annotations))
+	r.Annotations = r.Annotations[:MaxAnnotations]
+	return dropped
+}
+
+// AnnotationSeverity maps a finding severity to a Code Insights severity
+func AnnotationSeverity(severity string) string {
+	switch severity {
+	case report.SeverityError:
+		return "HIGH"
+	case report.SeverityWarning:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// WriteFiles writes the report as <name>-insights.json to dir and its annotations
+// as <name>-annotations-<n>.json, in the batches they are uploaded in, and
+// returns their paths. Annotation files of earlier runs are removed first.
+func WriteFiles(dir, name string, r Report) (string, []string, error) {
+	stale, err := filepath.Glob(filepath.Join(dir, name+"-annotations*.json"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return "", nil, fmt.Errorf("error removing Code Insights file: %w", err)
+		}
+	}
+
+	reportPath := filepath.Join(dir, name+"-insights.json")
+	if err := writeFile(reportPath, r); err != nil {
+		return "", nil, err
+	}
+	var annotationPaths []string
+	for start := 0; start < len(r.Annotations); start += AnnotationBatch {
+		end := min(start+AnnotationBatch, len(r.Annotations))
+		path := filepath.Join(dir, fmt.Sprintf("%s-annotations-%d.json", name, start/AnnotationBatch+1))
+		if err := writeFile(path, r.Annotations[start:end]); err != nil {
+			return "", nil, err
+		}
+		annotationPaths = append(annotationPaths, path)
+	}
+	return reportPath, annotationPaths, nil
+}
+
+// writeFile writes value as indented JSON to path
+func writeFile(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing Code Insights file: %w", err)
+	}
+	return nil
+}
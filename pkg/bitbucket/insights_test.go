@@ -0,0 +1,65 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// annotations returns n annotations of page.html.twig
+func annotations(n int) []Annotation {
+	var result []Annotation
+	for i := 0; i < n; i++ {
+		result = append(result, Annotation{ExternalID: fmt.Sprint(i), Path: "page.html.twig", Line: i + 1})
+	}
+	return result
+}
+
+func TestTruncate(t *testing.T) {
+	report := Report{Annotations: annotations(MaxAnnotations + 5)}
+	if dropped := report.Truncate(); dropped != 5 {
+		t.Errorf("Expected 5 dropped annotations, got %d", dropped)
+	}
+	if len(report.Annotations) != MaxAnnotations || !strings.Contains(report.Details, "1005") {
+		t.Errorf("Expected %d annotations and a truncation note, got %d: %s", MaxAnnotations, len(report.Annotations), report.Details)
+	}
+
+	report = Report{Annotations: annotations(2)}
+	if dropped := report.Truncate(); dropped != 0 || report.Details != "" {
+		t.Errorf("Expected a report within the limit to stay unchanged, got %d dropped: %s", dropped, report.Details)
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	reportPath, annotationPaths, err := WriteFiles(dir, "twig-blocks", Report{Title: "Twig", Annotations: annotations(250)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reportPath != filepath.Join(dir, "twig-blocks-insights.json") || len(annotationPaths) != 3 {
+		t.Fatalf("Unexpected files %s and %v", reportPath, annotationPaths)
+	}
+
+	// The annotation files hold the upload batches
+	for i, size := range []int{100, 100, 50} {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("twig-blocks-annotations-%d.json", i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var written []Annotation
+		if err := json.Unmarshal(data, &written); err != nil || len(written) != size {
+			t.Errorf("Expected %d annotations in annotations file %d, got %d (%v)", size, i+1, len(written), err)
+		}
+	}
+
+	// Files of an earlier, larger run are removed
+	if _, annotationPaths, err = WriteFiles(dir, "twig-blocks", Report{Annotations: annotations(10)}); err != nil {
+		t.Fatal(err)
+	}
+	if written, _ := filepath.Glob(filepath.Join(dir, "twig-blocks-annotations*.json")); len(written) != 1 || len(annotationPaths) != 1 {
+		t.Errorf("Expected a single annotations file for 10 annotations, got %v", written)
+	}
+}
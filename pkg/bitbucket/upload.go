@@ -0,0 +1,90 @@
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Endpoints of Bitbucket Cloud
+const (
+	APIURL            = "http://api.bitbucket.org/2.0" // API root, https is added by the proxy
+	PipelinesProxyURL = "http://localhost:29418"       // Proxy of Pipelines steps that authenticates requests
+)
+
+// Timeout limits each request to the Code Insights API
+const Timeout = 30 * time.Second
+
+// Uploader sends Code Insights reports of a commit to Bitbucket
+type Uploader struct {
+	BaseURL string // API root
+	Repo    string // Full name of the repository, workspace/slug
+	Commit  string
+	Client  *http.Client
+}
+
+// PipelinesCommit returns the repository and commit a Bitbucket Pipelines step
+// runs on, and false outside Bitbucket Pipelines
+func PipelinesCommit() (string, string, bool) {
+	repo, commit := os.Getenv("BITBUCKET_REPO_FULL_NAME"), os.Getenv("BITBUCKET_COMMIT")
+	return repo, commit, repo != "" && commit != ""
+}
+
+// NewUploader returns an uploader for commit of repo sending requests to the API
+// root apiURL, through the proxy at proxyURL unless it is empty
+func NewUploader(apiURL, proxyURL, repo, commit string) (*Uploader, error) {
+	client := &http.Client{Timeout: Timeout}
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Bitbucket proxy URL: %w", err)
+		}
+		client.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+	}
+	return &Uploader{BaseURL: apiURL, Repo: repo, Commit: commit, Client: client}, nil
+}
+
+// Upload creates or replaces the report with the given ID and adds its
+// annotations in batches. Requests are canceled with ctx.
+func (u *Uploader) Upload(ctx context.Context, reportID string, r Report) error {
+	reportURL := fmt.Sprintf("%s/repositories/%s/commit/%s/reports/%s", u.BaseURL, u.Repo, u.Commit, url.PathEscape(reportID))
+	if err := u.send(ctx, http.MethodPut, reportURL, r); err != nil {
+		return fmt.Errorf("error uploading report: %w", err)
+	}
+
+	for start := 0; start < len(r.Annotations); start += AnnotationBatch {
+		end := min(start+AnnotationBatch, len(r.Annotations))
+		if err := u.send(ctx, http.MethodPost, reportURL+"/annotations", r.Annotations[start:end]); err != nil {
+			return fmt.Errorf("error uploading annotations %d-%d: %w", start+1, end, err)
+		}
+	}
+	return nil
+}
+
+// send sends body as JSON and fails on a response outside 2xx
+func (u *Uploader) send(ctx context.Context, method, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestUpload(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	uploaded := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost {
+			var batch []Annotation
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+				t.Errorf("Invalid annotations batch: %v", err)
+			}
+			uploaded += len(batch)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	uploader, err := NewUploader(server.URL, "", "acme/shop", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uploader.Client.Timeout != Timeout {
+		t.Errorf("Expected a request timeout of %s, got %s", Timeout, uploader.Client.Timeout)
+	}
+	if err := uploader.Upload(context.Background(), "wswcli-test", Report{Annotations: annotations(250)}); err != nil {
+		t.Fatal(err)
+	}
+
+	reportPath := "/repositories/acme/shop/commit/abc123/reports/wswcli-test"
+	expected := []string{"PUT " + reportPath}
+	for i := 0; i < 3; i++ {
+		expected = append(expected, "POST "+reportPath+"/annotations")
+	}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) || uploaded != 250 {
+		t.Errorf("Expected %v with 250 annotations, got %v with %d", expected, requests, uploaded)
+	}
+
+	// A canceled context stops the upload before any request
+	requests = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := uploader.Upload(ctx, "wswcli-test", Report{}); err == nil || len(requests) != 0 {
+		t.Errorf("Expected a canceled upload without requests, got %v and %v", err, requests)
+	}
+
+	// A response outside 2xx fails the upload
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if err := uploader.Upload(context.Background(), "wswcli-test", Report{}); err == nil {
+		t.Error("Expected an error for a failing API")
+	}
+}
+
+func TestPipelinesCommit(t *testing.T) {
+	t.Setenv("BITBUCKET_REPO_FULL_NAME", "acme/shop")
+	t.Setenv("BITBUCKET_COMMIT", "")
+	if _, _, ok := PipelinesCommit(); ok {
+		t.Error("Expected no commit outside Bitbucket Pipelines")
+	}
+
+	t.Setenv("BITBUCKET_COMMIT", "abc123")
+	if repo, commit, ok := PipelinesCommit(); !ok || repo != "acme/shop" || commit != "abc123" {
+		t.Errorf("Unexpected commit %s of %s", commit, repo)
+	}
+}
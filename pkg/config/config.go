@@ -0,0 +1,229 @@
+// Package config reads the .wswcli project configuration and manages the .wswcli
+// state directory.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Name is the config file in the project directory. When it is a directory it
+// holds the config as .wswcli/config next to wswcli state files.
+const Name = ".wswcli"
+
+// DefaultPatchOutputDir is the patch directory used when the config sets none
+const DefaultPatchOutputDir = "artifacts/patches"
+
+// Config represents the configuration for wswcli
+type Config struct {
+	PatchVendor PatchVendorConfig `ini:"patchvendor" json:"patchvendor"`
+	BS4to5      BS4to5Config      `ini:"bs-4-to-5" json:"bs-4-to-5"`
+}
+
+// PatchVendorConfig represents the patchvendor specific configuration
+type PatchVendorConfig struct {
+	PatchOutputDir string `ini:"patch_output_dir" json:"patch_output_dir"`
+}
+
+// BS4to5Config represents the bs-4-to-5 specific configuration
+type BS4to5Config struct {
+	Rules []string `ini:"rules" json:"rules"` // Rule files merged over the built-in rules
+	Only  []string `ini:"only" json:"only"`   // Rule categories to apply
+	Skip  []string `ini:"skip" json:"skip"`   // Rule names to skip
+}
+
+// Default returns the configuration used without a config file
+func Default() *Config {
+	return &Config{
+		PatchVendor: PatchVendorConfig{
+			PatchOutputDir: DefaultPatchOutputDir,
+		},
+	}
+}
+
+// FilePath returns the path of the config file of the project in dir,
+// .wswcli or .wswcli/config
+func FilePath(dir string) string {
+	path := filepath.Join(dir, Name)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "config")
+	}
+	return path
+}
+
+// StateFallbackName is the state directory used when .wswcli is a config file
+const StateFallbackName = ".wswcli-state"
+
+// ProjectRoot returns the project directory of path: the nearest directory from
+// path upwards that contains .wswcli or a git repository, or the directory of
+// path itself when there is none
+func ProjectRoot(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("error resolving project root: %w", err)
+	}
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		absPath = filepath.Dir(absPath)
+	}
+
+	for dir := absPath; ; dir = filepath.Dir(dir) {
+		for _, marker := range []string{Name, ".git"} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, nil
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return absPath, nil
+		}
+	}
+}
+
+// StatePath returns the state directory of the project in dir without creating
+// it: .wswcli, or .wswcli-state when .wswcli is a config file
+func StatePath(dir string) string {
+	path := filepath.Join(dir, Name)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return filepath.Join(dir, StateFallbackName)
+	}
+	return path
+}
+
+// StateDir returns the state directory of the project in dir used for review
+// sessions and undo journals, see StatePath, and creates it if needed
+func StateDir(dir string) (string, error) {
+	path := StatePath(dir)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return "", fmt.Errorf("error creating state directory: %w", err)
+	}
+	return path, nil
+}
+
+// Load loads the configuration of the project in dir from .wswcli or
+// .wswcli/config. Without a config file the default configuration is returned.
+func Load(dir string) (*Config, error) {
+	file, err := os.Open(FilePath(dir))
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening config file: %w", err)
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads a configuration in the INI format of .wswcli. Settings missing
+// from r keep their default.
+func Parse(r io.Reader) (*Config, error) {
+	config := Default()
+
+	scanner := bufio.NewScanner(r)
+	var currentSection string
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		// Check for section headers
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			currentSection = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+
+		// Parse key-value pairs
+		if strings.Contains(line, "=") {
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			key := strings.TrimSpace(parts[0])
+			value := strings.TrimSpace(parts[1])
+
+			// Remove quotes if present
+			if (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
+				(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
+				value = value[1 : len(value)-1]
+			}
+
+			// Apply configuration based on section
+			switch currentSection {
+			case "patchvendor":
+				switch key {
+				case "patch_output_dir":
+					config.PatchVendor.PatchOutputDir = value
+				}
+			case "bs-4-to-5":
+				switch key {
+				case "rules":
+					config.BS4to5.Rules = splitList(value)
+				case "only":
+					config.BS4to5.Only = splitList(value)
+				case "skip":
+					config.BS4to5.Skip = splitList(value)
+				}
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	return config, nil
+}
+
+// splitList splits a comma separated config value into its trimmed items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Example is the content of the config file created by WriteExample
+const Example = `# wswcli Configuration File
+# This file configures project-specific settings for wswcli commands
+
+[patchvendor]
+# Directory where patch files will be saved (relative to project root)
+patch_output_dir = "artifacts/patches"
+
+# Example configuration for different project structures:
+# patch_output_dir = "build/patches"
+
+[bs-4-to-5]
+# Rule files merged over the built-in migration rules (comma separated)
+# rules = "bs5-rules.yaml"
+# Only apply rules of these categories
+# only = "forms, utilities"
+# Skip rules by name
+# skip = "Card Deck, Card Columns"
+`
+
+// WriteExample creates an example config file in the project in dir
+func WriteExample(dir string) error {
+	file, err := os.Create(FilePath(dir))
+	if err != nil {
+		return fmt.Errorf("error creating config file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(Example); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	// Test loading default config when no file exists
+	t.Run("DefaultConfig", func(t *testing.T) {
+		// Ensure no .wswcli file exists
+		os.Remove(".wswcli")
+
+		config, err := Load(".")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if config.PatchVendor.PatchOutputDir != "artifacts/patches" {
+			t.Errorf("Expected default patch_output_dir 'artifacts/patches', got '%s'", config.PatchVendor.PatchOutputDir)
+		}
+
+	})
+
+	// Test loading custom config
+	t.Run("CustomConfig", func(t *testing.T) {
+		// Create test config file
+		configContent := `[patchvendor]
+patch_output_dir = "build/patches"
+`
+		err := os.WriteFile(".wswcli", []byte(configContent), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test config: %v", err)
+		}
+		defer os.Remove(".wswcli")
+
+		config, err := Load(".")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if config.PatchVendor.PatchOutputDir != "build/patches" {
+			t.Errorf("Expected patch_output_dir 'build/patches', got '%s'", config.PatchVendor.PatchOutputDir)
+		}
+	})
+
+	// Test loading bs-4-to-5 rule selection
+	t.Run("BS4to5Config", func(t *testing.T) {
+		configContent := `[bs-4-to-5]
+rules = "team-rules.yaml, extra.json"
+only = forms,utilities
+skip = "Card Deck"
+`
+		err := os.WriteFile(".wswcli", []byte(configContent), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test config: %v", err)
+		}
+		defer os.Remove(".wswcli")
+
+		config, err := Load(".")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if strings.Join(config.BS4to5.Rules, "|") != "team-rules.yaml|extra.json" {
+			t.Errorf("Unexpected rule files: %v", config.BS4to5.Rules)
+		}
+		if strings.Join(config.BS4to5.Only, "|") != "forms|utilities" {
+			t.Errorf("Unexpected categories: %v", config.BS4to5.Only)
+		}
+		if len(config.BS4to5.Skip) != 1 || config.BS4to5.Skip[0] != "Card Deck" {
+			t.Errorf("Unexpected skipped rules: %v", config.BS4to5.Skip)
+		}
+	})
+}
+
+func TestConfigDirectory(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Without .wswcli the state directory is created
+	stateDir, err := StateDir(".")
+	if err != nil {
+		t.Fatalf("StateDir failed: %v", err)
+	}
+	if info, err := os.Stat(stateDir); err != nil || !info.IsDir() {
+		t.Fatalf("Expected state directory %s to be created", stateDir)
+	}
+
+	// The config is read from .wswcli/config next to the state files
+	configContent := `[patchvendor]
+patch_output_dir = "build/patches"
+`
+	if err := os.WriteFile(filepath.Join(".wswcli", "config"), []byte(configContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(".")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if config.PatchVendor.PatchOutputDir != "build/patches" {
+		t.Errorf("Expected patch_output_dir from .wswcli/config, got '%s'", config.PatchVendor.PatchOutputDir)
+	}
+
+	// Next to a .wswcli config file state is kept in .wswcli-state
+	if err := os.RemoveAll(".wswcli"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".wswcli", []byte(configContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stateDir, err = StateDir(".")
+	if err != nil {
+		t.Fatalf("StateDir failed next to a config file: %v", err)
+	}
+	if stateDir != StateFallbackName {
+		t.Errorf("Expected state directory %s, got %s", StateFallbackName, stateDir)
+	}
+	if content, err := os.ReadFile(".wswcli"); err != nil || string(content) != configContent {
+		t.Errorf("Expected the config file to be kept, got %q (%v)", content, err)
+	}
+}
+
+func TestProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "templates", "page"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "templates", "page", "a.twig"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without .wswcli or .git the scanned directory is the project
+	templates := filepath.Join(root, "templates")
+	if dir, err := ProjectRoot(templates); err != nil || dir != templates {
+		t.Errorf("Expected %s, got %s (%v)", templates, dir, err)
+	}
+	if dir, err := ProjectRoot(filepath.Join(templates, "page", "a.twig")); err != nil || dir != filepath.Join(templates, "page") {
+		t.Errorf("Expected the directory of a file, got %s (%v)", dir, err)
+	}
+
+	for _, marker := range []string{".git", Name} {
+		if err := os.WriteFile(filepath.Join(root, marker), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if dir, err := ProjectRoot(filepath.Join(templates, "page")); err != nil || dir != root {
+			t.Errorf("Expected %s with %s, got %s (%v)", root, marker, dir, err)
+		}
+	}
+}
+
+func TestCreateExampleConfig(t *testing.T) {
+	// Remove existing config if any
+	os.Remove(".wswcli")
+
+	err := WriteExample(".")
+	if err != nil {
+		t.Fatalf("Expected no error creating config, got %v", err)
+	}
+	defer os.Remove(".wswcli")
+
+	// Check if file was created
+	if _, err := os.Stat(".wswcli"); os.IsNotExist(err) {
+		t.Error("Expected .wswcli file to be created")
+	}
+
+	// Check if file contains expected content
+	content, err := os.ReadFile(".wswcli")
+	if err != nil {
+		t.Fatalf("Failed to read created config file: %v", err)
+	}
+
+	contentStr := string(content)
+	if !strings.Contains(contentStr, "[patchvendor]") {
+		t.Error("Expected config file to contain [patchvendor] section")
+	}
+
+	if !strings.Contains(contentStr, "patch_output_dir") {
+		t.Error("Expected config file to contain patch_output_dir setting")
+	}
+}
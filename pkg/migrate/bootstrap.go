@@ -0,0 +1,661 @@
+package migrate
+
+import (
+	"regexp"
+)
+
+// getBootstrapMigrations returns all Bootstrap 4 to 5 migration rules
+func getBootstrapMigrations() []Rule {
+	migrations := []Rule{
+		// Structural rewrites run first, elements they cannot rewrite safely get
+		// a TODO from the class rules below
+		{
+			Name:        "Input Group Addons",
+			Transform:   unwrapInputGroupAddons("Input Group Addons"),
+			Description: "input-group-prepend/append wrappers unwrapped into the input-group",
+			Category:    "structure",
+			Scope:       ScopeStructure,
+			Examples: []Example{
+				{Before: "<div class=\"input-group\">\n  <div class=\"input-group-prepend\">\n    <span class=\"input-group-text\">@</span>\n  </div>\n  <input class=\"form-control\">\n</div>", After: "<div class=\"input-group\">\n  <span class=\"input-group-text\">@</span>\n  <input class=\"form-control\">\n</div>"},
+			},
+		},
+		{
+			Name:        "Custom File Structure",
+			Transform:   rewriteCustomFile("Custom File Structure"),
+			Description: "custom-file widget → form-label and form-control file input",
+			Category:    "structure",
+			Scope:       ScopeStructure,
+			Examples: []Example{
+				{Before: "<div class=\"custom-file\">\n  <input type=\"file\" class=\"custom-file-input\" id=\"file\">\n  <label class=\"custom-file-label\" for=\"file\">Choose</label>\n</div>", After: "<div>\n  <label class=\"form-label\" for=\"file\">Choose</label>\n  <input type=\"file\" class=\"form-control\" id=\"file\">\n</div>"},
+			},
+		},
+		{
+			Name:        "Media Object Structure",
+			Transform:   rewriteMediaObjects("Media Object Structure"),
+			Description: "media → d-flex with flex-shrink-0 children",
+			Category:    "structure",
+			Scope:       ScopeStructure,
+			Examples: []Example{
+				{Before: "<div class=\"media\">\n  <img class=\"mr-3\" src=\"a.png\">\n  <div class=\"media-body\">Text</div>\n</div>", After: "<div class=\"d-flex\">\n  <img class=\"mr-3 flex-shrink-0\" src=\"a.png\">\n  <div class=\"media-body\">Text</div>\n</div>"},
+			},
+		},
+
+		// Form Controls
+		{
+			Name:        "Custom Checkbox",
+			Pattern:     regexp.MustCompile(`custom-control\s+custom-checkbox`),
+			Replacement: "form-check",
+			Description: "custom-control custom-checkbox → form-check",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="custom-control custom-checkbox">`, After: `<div class="form-check">`},
+			},
+		},
+		{
+			Name:        "Custom Radio",
+			Pattern:     regexp.MustCompile(`custom-control\s+custom-radio`),
+			Replacement: "form-check",
+			Description: "custom-control custom-radio → form-check",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="custom-control custom-radio">`, After: `<div class="form-check">`},
+			},
+		},
+		{
+			Name:        "Custom Switch",
+			Pattern:     regexp.MustCompile(`custom-control\s+custom-switch`),
+			Replacement: "form-check form-switch",
+			Description: "custom-control custom-switch → form-check form-switch",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="custom-control custom-switch">`, After: `<div class="form-check form-switch">`},
+			},
+		},
+		{
+			Name:        "Custom Control Input",
+			Pattern:     regexp.MustCompile(`custom-control-input`),
+			Replacement: "form-check-input",
+			Description: "custom-control-input → form-check-input",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<input class="custom-control-input">`, After: `<input class="form-check-input">`},
+			},
+		},
+		{
+			Name:        "Custom Control Label",
+			Pattern:     regexp.MustCompile(`custom-control-label`),
+			Replacement: "form-check-label",
+			Description: "custom-control-label → form-check-label",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<label class="custom-control-label">`, After: `<label class="form-check-label">`},
+			},
+		},
+		{
+			Name:        "Custom Select",
+			Pattern:     regexp.MustCompile(`custom-select`),
+			Replacement: "form-select",
+			Description: "custom-select → form-select",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<select class="custom-select">`, After: `<select class="form-select">`},
+			},
+		},
+		{
+			Name:        "Custom Range",
+			Pattern:     regexp.MustCompile(`custom-range`),
+			Replacement: "form-range",
+			Description: "custom-range → form-range",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<input class="custom-range">`, After: `<input class="form-range">`},
+			},
+		},
+		{
+			Name:        "Custom File",
+			Pattern:     regexp.MustCompile(`custom-file`),
+			Replacement: "custom-file",
+			Todo:        "custom-file removed - Use a form-control file input: https://getbootstrap.com/docs/5.2/forms/form-control/#file-input",
+			Description: "custom-file wrapper removed - Requires manual restructuring",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="custom-file">`, After: `<div class="custom-file {# TODO #}">`},
+			},
+		},
+
+		// Grid System
+		{
+			Name:        "No Gutters",
+			Pattern:     regexp.MustCompile(`no-gutters`),
+			Replacement: "g-0",
+			Description: "no-gutters → g-0",
+			Category:    "grid",
+			Examples: []Example{
+				{Before: `<div class="row no-gutters">`, After: `<div class="row g-0">`},
+			},
+		},
+
+		// Buttons
+		{
+			Name:        "Button Block",
+			Pattern:     regexp.MustCompile(`btn-block`),
+			Replacement: "d-grid",
+			Description: "btn-block → d-grid (may need gap-* utilities)",
+			Category:    "buttons",
+			Examples: []Example{
+				{Before: `<a class="btn btn-primary btn-block">`, After: `<a class="btn btn-primary d-grid">`},
+			},
+		},
+
+		// Badges
+		{
+			Name:        "Badge Pill",
+			Pattern:     regexp.MustCompile(`badge-pill`),
+			Replacement: "rounded-pill",
+			Description: "badge-pill → rounded-pill",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-pill">`, After: `<span class="badge rounded-pill">`},
+			},
+		},
+		{
+			Name:        "Badge Primary",
+			Pattern:     regexp.MustCompile(`badge-primary`),
+			Replacement: "bg-primary",
+			Description: "badge-primary → bg-primary",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-primary">`, After: `<span class="badge bg-primary">`},
+			},
+		},
+		{
+			Name:        "Badge Secondary",
+			Pattern:     regexp.MustCompile(`badge-secondary`),
+			Replacement: "bg-secondary",
+			Description: "badge-secondary → bg-secondary",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-secondary">`, After: `<span class="badge bg-secondary">`},
+			},
+		},
+		{
+			Name:        "Badge Success",
+			Pattern:     regexp.MustCompile(`badge-success`),
+			Replacement: "bg-success",
+			Description: "badge-success → bg-success",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-success">`, After: `<span class="badge bg-success">`},
+			},
+		},
+		{
+			Name:        "Badge Danger",
+			Pattern:     regexp.MustCompile(`badge-danger`),
+			Replacement: "bg-danger",
+			Description: "badge-danger → bg-danger",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-danger">`, After: `<span class="badge bg-danger">`},
+			},
+		},
+		{
+			Name:        "Badge Warning",
+			Pattern:     regexp.MustCompile(`badge-warning`),
+			Replacement: "bg-warning",
+			Description: "badge-warning → bg-warning",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-warning">`, After: `<span class="badge bg-warning">`},
+			},
+		},
+		{
+			Name:        "Badge Info",
+			Pattern:     regexp.MustCompile(`badge-info`),
+			Replacement: "bg-info",
+			Description: "badge-info → bg-info",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-info">`, After: `<span class="badge bg-info">`},
+			},
+		},
+		{
+			Name:        "Badge Light",
+			Pattern:     regexp.MustCompile(`badge-light`),
+			Replacement: "bg-light",
+			Description: "badge-light → bg-light",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-light">`, After: `<span class="badge bg-light">`},
+			},
+		},
+		{
+			Name:        "Badge Dark",
+			Pattern:     regexp.MustCompile(`badge-dark`),
+			Replacement: "bg-dark",
+			Description: "badge-dark → bg-dark",
+			Category:    "badges",
+			Examples: []Example{
+				{Before: `<span class="badge badge-dark">`, After: `<span class="badge bg-dark">`},
+			},
+		},
+
+		// Close Button
+		{
+			Name:        "Close Button",
+			Pattern:     regexp.MustCompile(`close`),
+			Replacement: "btn-close",
+			Description: "close → btn-close",
+			Category:    "buttons",
+			Examples: []Example{
+				{Before: `<button class="close">`, After: `<button class="btn-close">`},
+				{Before: `<a class="close-link">`, After: `<a class="close-link">`},
+			},
+		},
+
+		// Tables
+		{
+			Name:        "Table Header Light",
+			Pattern:     regexp.MustCompile(`thead-light`),
+			Replacement: "table-light",
+			Description: "thead-light → table-light",
+			Category:    "tables",
+			Examples: []Example{
+				{Before: `<thead class="thead-light">`, After: `<thead class="table-light">`},
+			},
+		},
+		{
+			Name:        "Table Header Dark",
+			Pattern:     regexp.MustCompile(`thead-dark`),
+			Replacement: "table-dark",
+			Description: "thead-dark → table-dark",
+			Category:    "tables",
+			Examples: []Example{
+				{Before: `<thead class="thead-dark">`, After: `<thead class="table-dark">`},
+			},
+		},
+		{
+			Name:        "Card Deck",
+			Pattern:     regexp.MustCompile(`card-deck`),
+			Replacement: "row row-cols-1 row-cols-md-3 g-4", // Common replacement
+			Description: "card-deck → row row-cols-* g-* (card-deck removed, use grid)",
+			Category:    "cards",
+			Examples: []Example{
+				{Before: `<div class="card-deck">`, After: `<div class="row row-cols-1 row-cols-md-3 g-4">`},
+			},
+		},
+		{
+			Name:        "Card Columns",
+			Pattern:     regexp.MustCompile(`card-columns`),
+			Replacement: "row row-cols-1 row-cols-md-2 row-cols-xl-3",
+			Description: "card-columns → row row-cols-* (card-columns removed, use grid)",
+			Category:    "cards",
+			Examples: []Example{
+				{Before: `<div class="card-columns">`, After: `<div class="row row-cols-1 row-cols-md-2 row-cols-xl-3">`},
+			},
+		},
+		{
+			Name:        "Form Group with Custom Control",
+			Pattern:     regexp.MustCompile(`form-group`),
+			Replacement: "mb-3",
+			Description: "form-group → mb-3 (or other margin utility)",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="form-group">`, After: `<div class="mb-3">`},
+			},
+		},
+		{
+			Name:        "Left alignment",
+			Pattern:     regexp.MustCompile(`text-left`),
+			Replacement: "text-start",
+			Description: "text-left → text-start",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<p class="text-left">`, After: `<p class="text-start">`},
+			},
+		},
+		{
+			Name:        "Right alignment",
+			Pattern:     regexp.MustCompile(`text-right`),
+			Replacement: "text-end",
+			Description: "text-right → text-end",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<p class="text-right">`, After: `<p class="text-end">`},
+			},
+		},
+		{
+			Name:        "Float Left",
+			Pattern:     regexp.MustCompile(`float-left`),
+			Replacement: "float-start",
+			Description: "float-left → float-start",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<div class="float-left">`, After: `<div class="float-start">`},
+			},
+		},
+		{
+			Name:        "Float Right",
+			Pattern:     regexp.MustCompile(`float-right`),
+			Replacement: "float-end",
+			Description: "float-right → float-end",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<div class="float-right">`, After: `<div class="float-end">`},
+			},
+		},
+		{
+			Name:        "Border Left",
+			Pattern:     regexp.MustCompile(`border-left`),
+			Replacement: "border-start",
+			Description: "border-left → border-start",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<div class="border-left">`, After: `<div class="border-start">`},
+			},
+		},
+		{
+			Name:        "Border Right",
+			Pattern:     regexp.MustCompile(`border-right`),
+			Replacement: "border-end",
+			Description: "border-right → border-end",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<div class="border-right">`, After: `<div class="border-end">`},
+			},
+		},
+		{
+			Name:        "Margin Left",
+			Pattern:     regexp.MustCompile(`\bml-(\d+|auto)\b`),
+			Replacement: "ms-$1",
+			Description: "ml-* → ms-*",
+			Category:    "spacing",
+			Examples: []Example{
+				{Before: `<div class="ml-2 ml-auto">`, After: `<div class="ms-2 ms-auto">`},
+			},
+		},
+		{
+			Name:        "Margin Right",
+			Pattern:     regexp.MustCompile(`\bmr-(\d+|auto)\b`),
+			Replacement: "me-$1",
+			Description: "mr-* → me-*",
+			Category:    "spacing",
+			Examples: []Example{
+				{Before: `<div class="mr-3">`, After: `<div class="me-3">`},
+				{Before: `<div class="mr-auto">`, After: `<div class="me-auto">`},
+			},
+		},
+		{
+			Name:        "Padding Left",
+			Pattern:     regexp.MustCompile(`\bpl-(\d+)\b`),
+			Replacement: "ps-$1",
+			Description: "pl-* → ps-*",
+			Category:    "spacing",
+			Examples: []Example{
+				{Before: `<div class="pl-4">`, After: `<div class="ps-4">`},
+			},
+		},
+		{
+			Name:        "Padding Right",
+			Pattern:     regexp.MustCompile(`\bpr-(\d+)\b`),
+			Replacement: "pe-$1",
+			Description: "pr-* → pe-*",
+			Category:    "spacing",
+			Examples: []Example{
+				{Before: `<div class="pr-0">`, After: `<div class="pe-0">`},
+			},
+		},
+		{
+			Name:        "Data Attributes",
+			Pattern:     regexp.MustCompile(`data-(toggle|target|dismiss|slide|dropdown)`),
+			Replacement: "data-bs-$1",
+			Description: "data-* → data-bs-* (essential for JS components)",
+			Category:    "javascript",
+			Scope:       ScopeAttribute,
+			Examples: []Example{
+				{Before: `<button data-toggle="modal" data-target="#cart">`, After: `<button data-bs-toggle="modal" data-bs-target="#cart">`},
+			},
+		},
+		{
+			Name:        "Form Row",
+			Pattern:     regexp.MustCompile(`form-row`),
+			Replacement: "row g-2",
+			Description: "form-row → row g-2 (gap utilities)",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="form-row">`, After: `<div class="row g-2">`},
+			},
+		},
+		{
+			Name:        "Dropdown Menu Right",
+			Pattern:     regexp.MustCompile(`dropdown-menu-right`),
+			Replacement: "dropdown-menu-end",
+			Description: "dropdown-menu-right → dropdown-menu-end",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<div class="dropdown-menu dropdown-menu-right">`, After: `<div class="dropdown-menu dropdown-menu-end">`},
+			},
+		},
+		{
+			Name:        "Rounded Left",
+			Pattern:     regexp.MustCompile(`rounded-left`),
+			Replacement: "rounded-start",
+			Description: "rounded-left → rounded-start",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<img class="rounded-left">`, After: `<img class="rounded-start">`},
+			},
+		},
+		{
+			Name:        "Rounded Right",
+			Pattern:     regexp.MustCompile(`rounded-right`),
+			Replacement: "rounded-end",
+			Description: "rounded-right → rounded-end",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<img class="rounded-right">`, After: `<img class="rounded-end">`},
+			},
+		},
+		{
+			Name:        "Input Group Structure",
+			Pattern:     regexp.MustCompile(`input-group-(append|prepend)`),
+			Replacement: "input-group-$1",
+			Todo:        "input-group-append/prepend removed - Restructure completely: https://getbootstrap.com/docs/5.2/forms/input-group/",
+			Description: "input-group-append/prepend removed - Requires manual restructuring",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<div class="input-group-append">`, After: `<div class="input-group-append {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Custom File Input",
+			Pattern:     regexp.MustCompile(`custom-file-input`),
+			Replacement: "custom-file-input",
+			Todo:        "custom-file-input removed - Use new file input: https://getbootstrap.com/docs/5.2/forms/form-control/#file-input",
+			Description: "Custom file input removed - Requires complete redesign",
+			Category:    "forms",
+			Examples: []Example{
+				{Before: `<input class="custom-file-input">`, After: `<input class="custom-file-input {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Media Object",
+			Pattern:     regexp.MustCompile(`media`),
+			Replacement: "media",
+			Todo:        "Media object removed - Use flex utilities: https://getbootstrap.com/docs/5.2/utilities/flex/#media-object",
+			Description: "media removed - Requires manual restructuring",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<div class="media">`, After: `<div class="media {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Media Body",
+			Pattern:     regexp.MustCompile(`media-body`),
+			Replacement: "flex-grow-1",
+			Description: "media-body → flex-grow-1",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<div class="media-body">`, After: `<div class="flex-grow-1">`},
+			},
+		},
+		{
+			Name:        "JavaScript Initialization",
+			Pattern:     regexp.MustCompile(`\$\('\.(dropdown|tooltip|popover|modal|carousel|collapse)'\).*?\(\);?`),
+			Replacement: "$0",
+			Todo:        "jQuery initialization removed - Use new API: https://getbootstrap.com/docs/5.2/getting-started/javascript/#usage",
+			Description: "jQuery initialization → Vanilla JS initialization",
+			Category:    "javascript",
+			Scope:       ScopeFile,
+			Examples: []Example{
+				{Before: `<button onclick="$('.modal').modal();">`, After: `<button onclick="$('.modal').modal(); {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Breadcrumb Separator",
+			Pattern:     regexp.MustCompile(`breadcrumb-item`),
+			Replacement: "breadcrumb-item",
+			Todo:        "Remove manual separators - Now via CSS: https://getbootstrap.com/docs/5.2/components/breadcrumb/#changing-the-separator",
+			Description: "Breadcrumb separators now via CSS (remove manual separators)",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<li class="breadcrumb-item">`, After: `<li class="breadcrumb-item {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Close Button Content",
+			Pattern:     regexp.MustCompile(`&times;`),
+			Replacement: "&times;",
+			Todo:        "Replace &times; with SVG icon: https://getbootstrap.com/docs/5.2/components/close-button/",
+			Description: "&times; must be replaced with SVG icon",
+			Category:    "buttons",
+			Scope:       ScopeFile,
+			Examples: []Example{
+				{Before: `<span aria-hidden="true">&times;</span>`, After: `<span aria-hidden="true">&times; {# TODO #}</span>`},
+			},
+		},
+		{
+			Name:        "Navbar Toggle Icon",
+			Pattern:     regexp.MustCompile(`navbar-toggler-icon`),
+			Replacement: "navbar-toggler-icon",
+			Todo:        "Use SVG icon instead: https://getbootstrap.com/docs/5.2/components/navbar/#toggler",
+			Description: "Navbar toggle requires SVG instead of icon font",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<span class="navbar-toggler-icon">`, After: `<span class="navbar-toggler-icon {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Text Monospace",
+			Pattern:     regexp.MustCompile(`text-monospace`),
+			Replacement: "font-monospace",
+			Description: "text-monospace → font-monospace",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<code class="text-monospace">`, After: `<code class="font-monospace">`},
+			},
+		},
+		{
+			Name:        "Font Weight",
+			Pattern:     regexp.MustCompile(`font-weight-(\w+)`),
+			Replacement: "fw-$1",
+			Description: "font-weight-* → fw-*",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<p class="font-weight-bold">`, After: `<p class="fw-bold">`},
+			},
+		},
+		{
+			Name:        "Small Rounded",
+			Pattern:     regexp.MustCompile(`rounded-sm`),
+			Replacement: "rounded-1",
+			Description: "rounded-sm → rounded-1",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<img class="rounded-sm">`, After: `<img class="rounded-1">`},
+			},
+		},
+		{
+			Name:        "Large Rounded",
+			Pattern:     regexp.MustCompile(`rounded-lg`),
+			Replacement: "rounded-3",
+			Description: "rounded-lg → rounded-3",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<img class="rounded-lg">`, After: `<img class="rounded-3">`},
+			},
+		},
+		{
+			Name:        "Responsive Tables",
+			Pattern:     regexp.MustCompile(`table-responsive(-\w+)?`),
+			Replacement: "table-responsive$1",
+			Todo:        "Use wrapper div instead: https://getbootstrap.com/docs/5.2/content/tables/#responsive-tables",
+			Description: "Responsive tables now require wrapper div",
+			Category:    "tables",
+			Examples: []Example{
+				{Before: `<table class="table table-responsive-md">`, After: `<table class="table table-responsive-md {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Tooltip/Popover Start",
+			Pattern:     regexp.MustCompile(`bs-(tooltip|popover)-left`),
+			Replacement: "bs-$1-start",
+			Description: "bs-tooltip-left, bs-popover-left → bs-tooltip-start, bs-popover-start",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<div class="tooltip bs-tooltip-left">`, After: `<div class="tooltip bs-tooltip-start">`},
+				{Before: `<div class="popover bs-popover-left">`, After: `<div class="popover bs-popover-start">`},
+			},
+		},
+		{
+			Name:        "Tooltip/Popover End",
+			Pattern:     regexp.MustCompile(`bs-(tooltip|popover)-right`),
+			Replacement: "bs-$1-end",
+			Description: "bs-tooltip-right, bs-popover-right → bs-tooltip-end, bs-popover-end",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<div class="tooltip bs-tooltip-right">`, After: `<div class="tooltip bs-tooltip-end">`},
+				{Before: `<div class="popover bs-popover-right">`, After: `<div class="popover bs-popover-end">`},
+			},
+		},
+	}
+
+	migrations = append(migrations, getSCSSMigrations()...)
+	return append(migrations, getScriptMigrations()...)
+}
+
+// getBootstrap4Removals returns the check rules for Bootstrap 4 classes that were
+// removed without a replacement. They are only applied with --check.
+func getBootstrap4Removals() []Rule {
+	removals := []struct {
+		name    string
+		pattern string
+		todo    string
+		example string // Class list containing a match
+	}{
+		{"Jumbotron", `jumbotron(?:-fluid)?`, "Jumbotron removed - Rebuild with utilities: https://getbootstrap.com/docs/5.0/examples/jumbotron/", "jumbotron-fluid"},
+		{"Form Inline", `form-inline`, "form-inline removed - Use the grid or flex utilities", "form-inline"},
+		{"Screen Reader Only", `sr-only(?:-focusable)?`, "sr-only removed - Use visually-hidden or visually-hidden-focusable", "sr-only-focusable"},
+		{"Embed Responsive", `embed-responsive(?:-item|-21by9|-16by9|-4by3|-1by1)?`, "embed-responsive removed - Use ratio and ratio-16x9 etc.", "embed-responsive-16by9"},
+		{"Text Hide", `text-hide`, "text-hide removed", "text-hide"},
+		{"Text Justify", `text-justify`, "text-justify removed", "text-justify"},
+		{"Pre Scrollable", `pre-scrollable`, "pre-scrollable removed", "pre-scrollable"},
+		{"Button Group Toggle", `btn-group-toggle`, "btn-group-toggle removed - Use btn-check inputs", "btn-group btn-group-toggle"},
+		{"Form Control File", `form-control-(?:file|range)`, "form-control-file and form-control-range removed - Use form-control or form-range", "form-control-file"},
+		{"Dropdown Menu Left", `dropdown-menu-left`, "dropdown-menu-left removed - Use dropdown-menu-start", "dropdown-menu dropdown-menu-left"},
+		{"Custom File Label", `custom-file-label`, "custom-file-label removed - Use a form-label before the file input", "custom-file-label"},
+	}
+
+	var rules []Rule
+	for _, removal := range removals {
+		rules = append(rules, Rule{
+			Name:        removal.name,
+			Pattern:     regexp.MustCompile(removal.pattern),
+			Replacement: "$0",
+			Todo:        removal.todo,
+			Description: removal.todo,
+			Category:    "removed",
+			Examples:    []Example{{Before: `<div class="` + removal.example + `">`, After: `<div class="` + removal.example + ` ` + exampleTodo + `">`}},
+		})
+	}
+	return rules
+}
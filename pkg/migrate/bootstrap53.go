@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"regexp"
+)
+
+// colorSchemeClasses maps the classes deprecated in Bootstrap 5.3 to the data-bs-theme value replacing them
+var colorSchemeClasses = [][2]string{
+	{"navbar-dark", "dark"},
+	{"navbar-light", "light"},
+	{"dropdown-menu-dark", "dark"},
+	{"carousel-dark", "dark"},
+	{"btn-close-white", "dark"},
+}
+
+// getBootstrap53Migrations returns the rules of the bootstrap-5.0-to-5.3 pack
+func getBootstrap53Migrations() []Rule {
+	return []Rule{
+		{
+			Name:        "Color Scheme Attributes",
+			Transform:   setColorScheme("Color Scheme Attributes"),
+			Description: "navbar-dark, dropdown-menu-dark, carousel-dark, btn-close-white → data-bs-theme",
+			Category:    "structure",
+			Scope:       ScopeStructure,
+			Examples: []Example{
+				{Before: `<nav class="navbar navbar-dark bg-dark">`, After: `<nav data-bs-theme="dark" class="navbar bg-dark">`},
+			},
+		},
+		{
+			Name:        "Color Scheme Classes",
+			Pattern:     regexp.MustCompile(`navbar-(?:dark|light)|dropdown-menu-dark|carousel-dark|btn-close-white`),
+			Replacement: "$0",
+			Todo:        "Deprecated in Bootstrap 5.3, set data-bs-theme on the element: https://getbootstrap.com/docs/5.3/customize/color-modes/",
+			Description: "Color scheme classes deprecated - Requires data-bs-theme",
+			Category:    "components",
+			Examples: []Example{
+				{Before: `<nav class="navbar navbar-light">`, After: `<nav class="navbar navbar-light {# TODO #}">`},
+			},
+		},
+		{
+			Name:        "Text Muted",
+			Pattern:     regexp.MustCompile(`text-muted`),
+			Replacement: "text-body-secondary",
+			Description: "text-muted → text-body-secondary",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<small class="text-muted">`, After: `<small class="text-body-secondary">`},
+			},
+		},
+		{
+			Name:        "Text Opacity",
+			Pattern:     regexp.MustCompile(`text-(black|white)-50`),
+			Replacement: "text-$1 text-opacity-50",
+			Description: "text-black-50 → text-black text-opacity-50",
+			Category:    "utilities",
+			Examples: []Example{
+				{Before: `<p class="text-white-50">`, After: `<p class="text-white text-opacity-50">`},
+			},
+		},
+	}
+}
+
+// themeAttribute matches a data-bs-theme attribute in an open tag
+var themeAttribute = regexp.MustCompile(`\sdata-bs-theme\s*=`)
+
+// setColorScheme replaces a deprecated color scheme class with a data-bs-theme
+// attribute. Elements with several scheme classes or their own data-bs-theme
+// are left to the TODO.
+func setColorScheme(rule string) Transform {
+	return func(content string, elements []*htmlElement) []Match {
+		var matches []Match
+		for _, element := range elements {
+			var class, theme string
+			found := 0
+			for _, scheme := range colorSchemeClasses {
+				if element.HasClass(content, scheme[0]) {
+					class, theme = scheme[0], scheme[1]
+					found++
+				}
+			}
+			if found != 1 || themeAttribute.MatchString(content[element.Start:element.OpenEnd]) {
+				continue
+			}
+
+			nameEnd := element.Start + 1 + len(element.Name)
+			edits := append(element.removeClass(content, class), textEdit{Start: nameEnd, End: nameEnd, Text: ` data-bs-theme="` + theme + `"`})
+			matches = append(matches, structureMatch(rule, content, element.Start, element.OpenEnd,
+				applyEdits(content, element.Start, element.OpenEnd, edits)))
+		}
+		return matches
+	}
+}
@@ -0,0 +1,206 @@
+package migrate
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestBootstrapMigrations(t *testing.T) {
+	migrations := getBootstrapMigrations()
+
+	// Test that we have migrations defined
+	if len(migrations) == 0 {
+		t.Fatal("No bootstrap migrations found")
+	}
+
+	// Test specific migrations
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "Custom Checkbox",
+			input:    `<div class="custom-control custom-checkbox">`,
+			expected: `<div class="form-check">`,
+		},
+		{
+			name:     "Custom Radio",
+			input:    `<div class="custom-control custom-radio">`,
+			expected: `<div class="form-check">`,
+		},
+		{
+			name:     "Custom Switch",
+			input:    `<div class="custom-control custom-switch">`,
+			expected: `<div class="form-check form-switch">`,
+		},
+		{
+			name:     "Custom Control Input",
+			input:    `<input class="custom-control-input" type="checkbox">`,
+			expected: `<input class="form-check-input" type="checkbox">`,
+		},
+		{
+			name:     "Custom Control Label",
+			input:    `<label class="custom-control-label">`,
+			expected: `<label class="form-check-label">`,
+		},
+		{
+			name:     "Custom Select",
+			input:    `<select class="custom-select">`,
+			expected: `<select class="form-select">`,
+		},
+		{
+			name:     "No Gutters",
+			input:    `<div class="row no-gutters">`,
+			expected: `<div class="row g-0">`,
+		},
+		{
+			name:     "Button Block",
+			input:    `<button class="btn btn-primary btn-block">`,
+			expected: `<button class="btn btn-primary d-grid">`,
+		},
+		{
+			name:     "Badge Pill",
+			input:    `<span class="badge badge-primary badge-pill">`,
+			expected: `<span class="badge bg-primary rounded-pill">`,
+		},
+		{
+			name:     "Close Button",
+			input:    `<button class="close">`,
+			expected: `<button class="btn-close">`,
+		},
+		{
+			name:     "Text Left",
+			input:    `<div class="text-left">`,
+			expected: `<div class="text-start">`,
+		},
+		{
+			name:     "Text Right",
+			input:    `<div class="text-right">`,
+			expected: `<div class="text-end">`,
+		},
+		{
+			name:     "Float Left",
+			input:    `<div class="float-left">`,
+			expected: `<div class="float-start">`,
+		},
+		{
+			name:     "Float Right",
+			input:    `<div class="float-right">`,
+			expected: `<div class="float-end">`,
+		},
+		{
+			name:     "Margin Left",
+			input:    `<div class="ml-3">`,
+			expected: `<div class="ms-3">`,
+		},
+		{
+			name:     "Margin Right",
+			input:    `<div class="mr-2">`,
+			expected: `<div class="me-2">`,
+		},
+		{
+			name:     "Padding Left",
+			input:    `<div class="pl-4">`,
+			expected: `<div class="ps-4">`,
+		},
+		{
+			name:     "Padding Right",
+			input:    `<div class="pr-1">`,
+			expected: `<div class="pe-1">`,
+		},
+		{
+			name:     "Form Group",
+			input:    `<div class="form-group">`,
+			expected: `<div class="mb-3">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := tc.input
+			changeCount := 0
+
+			// Apply all migrations to see if our test case gets transformed
+			for _, migration := range migrations {
+				if migration.Pattern == nil {
+					// Structural rewrites are covered by TestStructureRewrites
+					continue
+				}
+				matches := migration.Pattern.FindAllString(result, -1)
+				if len(matches) > 0 {
+					newResult := migration.Pattern.ReplaceAllString(result, migration.Replacement)
+					if newResult != result {
+						changeCount += len(matches)
+						result = newResult
+					}
+				}
+			}
+
+			if result != tc.expected {
+				t.Errorf("Migration failed for %s:\nInput:    %s\nExpected: %s\nActual:   %s", tc.name, tc.input, tc.expected, result)
+			}
+
+			if changeCount == 0 {
+				t.Errorf("No changes applied for test case: %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestRegexPatterns(t *testing.T) {
+	// Test that our regex patterns work correctly
+	testCases := []struct {
+		pattern     string
+		input       string
+		shouldMatch bool
+	}{
+		{
+			pattern:     `custom-control\s+custom-checkbox`,
+			input:       `class="custom-control custom-checkbox"`,
+			shouldMatch: true,
+		},
+		{
+			pattern:     `custom-control\s+custom-checkbox`,
+			input:       `class="custom-control  custom-checkbox"`, // Multiple spaces
+			shouldMatch: true,
+		},
+		{
+			pattern:     `custom-control\s+custom-checkbox`,
+			input:       `class="custom-checkbox"`, // Missing custom-control
+			shouldMatch: false,
+		},
+		{
+			pattern:     `custom-control-input`,
+			input:       `class="custom-control-input"`,
+			shouldMatch: true,
+		},
+		{
+			pattern:     `\bml-(\d+|auto)\b`,
+			input:       `class="ml-3"`,
+			shouldMatch: true,
+		},
+		{
+			pattern:     `\bml-(\d+|auto)\b`,
+			input:       `class="ml-auto"`, // Auto margins become ms-auto
+			shouldMatch: true,
+		},
+		{
+			pattern:     `\bpl-(\d+)\b`,
+			input:       `class="pl-auto"`, // There are no auto paddings
+			shouldMatch: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+"_"+tc.input, func(t *testing.T) {
+			regex := regexp.MustCompile(tc.pattern)
+			matches := regex.MatchString(tc.input)
+
+			if matches != tc.shouldMatch {
+				t.Errorf("Pattern %s with input %s: expected match=%v, got match=%v",
+					tc.pattern, tc.input, tc.shouldMatch, matches)
+			}
+		})
+	}
+}
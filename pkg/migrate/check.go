@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Finding is an occurrence the migration would change, located in the unchanged file
+type Finding struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Category string `json:"category"`
+	Message  string `json:"message"`
+	Match    string `json:"match"`
+}
+
+// maxFindingMatch is the length at which the matched text of a finding is cut off in output
+const maxFindingMatch = 60
+
+// CheckFile finds the occurrences the migrations would change in a file without
+// writing it. The result holds the migrated content like File, the file type of
+// options is taken from filename.
+func CheckFile(ctx context.Context, filename string, migrations []Rule, options Options) (Result, []Finding, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{File: filename}, nil, err
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return Result{File: filename}, nil, err
+	}
+
+	text, encoding, err := decodeMigrationFile(content)
+	if err != nil {
+		return Result{File: filename}, nil, err
+	}
+
+	options.FileType, _ = FileTypeFor(filename)
+	migrated, matches, findings := CheckContent(text, migrations, options)
+	return encodedMigration(filename, content, encoding, migrated, matches), findings, nil
+}
+
+// CheckContent applies the migrations like Content and returns the
+// matches as findings with their line and column in the original content
+func CheckContent(content string, migrations []Rule, options Options) (string, []Match, []Finding) {
+	if options.FileType.Name == "" {
+		options.FileType = fileTypes[0]
+	}
+
+	original := content
+	var all []Match
+	var applied [][]Match // Matches of every applied rule, in the content before the rule
+	var findings []Finding
+	for _, migration := range migrations {
+		if !ruleApplies(migration, options) {
+			continue
+		}
+		var matches []Match
+		content, matches = applyMigration(content, migration, options)
+		for _, match := range matches {
+			// Collapsed duplicate TODO comments are not findings
+			if migration.Todo != "" && match.Todo == "" {
+				continue
+			}
+			offset := match.Start
+			for i := len(applied) - 1; i >= 0; i-- {
+				offset = unshiftOffset(offset, applied[i])
+			}
+			findings = append(findings, Finding{
+				Line:     lineAt(original, offset),
+				Column:   offset - strings.LastIndex(original[:offset], "\n"),
+				Rule:     migration.Name,
+				Category: migration.Category,
+				Message:  migration.Description,
+				Match:    findingMatch(match.Original),
+			})
+		}
+
+		for i := range all {
+			all[i].Position = shiftOffset(all[i].Position, matches)
+		}
+		all = append(all, matches...)
+		applied = append(applied, matches)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+	return content, all, findings
+}
+
+// unshiftOffset maps an offset in the content after matches were applied back to
+// the content before. Offsets inside a replacement keep their place when the
+// replacement starts like the original, otherwise they move to the match start.
+func unshiftOffset(offset int, matches []Match) int {
+	delta := 0
+	for _, match := range matches {
+		start := match.Start + delta
+		if offset < start {
+			break
+		}
+		if offset < start+len(match.Replacement) {
+			if offset-start < commonPrefixLength(match.Original, match.Replacement) {
+				return match.Start + offset - start
+			}
+			return match.Start
+		}
+		delta += len(match.Replacement) - (match.End - match.Start)
+	}
+	return offset - delta
+}
+
+// commonPrefixLength returns the number of leading bytes a and b share
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// findingMatch returns the first line of the matched text, cut off at maxFindingMatch
+func findingMatch(text string) string {
+	if index := strings.IndexByte(text, '\n'); index >= 0 {
+		text = text[:index] + "…"
+	}
+	if len([]rune(text)) > maxFindingMatch {
+		text = string([]rune(text)[:maxFindingMatch]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,46 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckContent(t *testing.T) {
+	content := "<div class=\"ml-2 mr-3 jumbotron\">\n" +
+		"  <span class=\"sr-only badge badge-primary\">{{ label }}</span>\n" +
+		"  <div class=\"media\"><img class=\"mr-3\"><div class=\"media-body\">x</div></div>\n" +
+		"</div>\n"
+
+	migrations := append(getBootstrapMigrations(), getBootstrap4Removals()...)
+	migrated, _, findings := CheckContent(content, migrations, Options{})
+
+	expected, _ := Content(content, migrations, Options{})
+	if migrated != expected {
+		t.Errorf("Check should migrate like Content:\n%s\nExpected:\n%s", migrated, expected)
+	}
+
+	var got []string
+	for _, finding := range findings {
+		got = append(got, fmt.Sprintf("%d:%d %s", finding.Line, finding.Column, finding.Rule))
+		// Every finding points at its match in the original content
+		lines := strings.Split(content, "\n")
+		if line := lines[finding.Line-1]; !strings.HasPrefix(line[finding.Column-1:], strings.TrimSuffix(finding.Match, "…")) {
+			t.Errorf("Finding %+v does not point at its match in %q", finding, line)
+		}
+	}
+	want := []string{
+		"1:13 Margin Left",
+		"1:18 Margin Right",
+		"1:23 Jumbotron",
+		"2:16 Screen Reader Only",
+		"2:30 Badge Primary",
+		"3:3 Media Object Structure",
+		"3:22 Media Object Structure",
+		"3:34 Margin Right",
+		"3:52 Media Body",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected findings:\n%s\nExpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
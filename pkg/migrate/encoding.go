@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files
+const utf8BOM = "\xef\xbb\xbf"
+
+// fileEncoding is the byte-level format of a migrated file, restored when it is written
+type fileEncoding struct {
+	BOM             bool // Starts with a UTF-8 byte order mark
+	CRLF            bool // Every line ends with \r\n
+	TrailingNewline bool // Ends with a line ending
+}
+
+// decodeMigrationFile returns the content of a file as text without byte order
+// mark and with \n line endings, and the format to write it back in. Files that
+// are not valid UTF-8, e.g. legacy Latin-1 templates, are rejected because the
+// migration would corrupt them.
+func decodeMigrationFile(data []byte) (string, fileEncoding, error) {
+	var encoding fileEncoding
+	content := string(data)
+	if strings.HasPrefix(content, utf8BOM) {
+		encoding.BOM = true
+		content = content[len(utf8BOM):]
+	}
+
+	if !utf8.ValidString(content) {
+		offset := 0
+		for offset < len(content) {
+			r, size := utf8.DecodeRuneInString(content[offset:])
+			if r == utf8.RuneError && size <= 1 {
+				break
+			}
+			offset += size
+		}
+		return "", encoding, fmt.Errorf("not valid UTF-8 at line %d, convert the file first (e.g. iconv -f ISO-8859-1 -t UTF-8)", lineAt(content, offset))
+	}
+
+	// Mixed line endings are kept as they are
+	if lf := strings.Count(content, "\n"); lf > 0 && strings.Count(content, "\r\n") == lf {
+		encoding.CRLF = true
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	encoding.TrailingNewline = strings.HasSuffix(content, "\n")
+	return content, encoding, nil
+}
+
+// encode returns migrated text in the format of the original file
+func (e fileEncoding) encode(content string) string {
+	switch {
+	case e.TrailingNewline && content != "" && !strings.HasSuffix(content, "\n"):
+		content += "\n"
+	case !e.TrailingNewline:
+		content = strings.TrimSuffix(content, "\n")
+	}
+	if e.CRLF {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	if e.BOM {
+		content = utf8BOM + content
+	}
+	return content
+}
+
+// rawOffset maps an offset in decoded text to the offset in the encoded file
+func (e fileEncoding) rawOffset(text string, offset int) int {
+	if e.CRLF {
+		offset += strings.Count(text[:min(offset, len(text))], "\n")
+	}
+	if e.BOM {
+		offset += len(utf8BOM)
+	}
+	return offset
+}
+
+// encodedMigration returns the result of migrating a decoded file, with the
+// migrated content and the match positions in the format of the original file
+func encodedMigration(filename string, original []byte, encoding fileEncoding, migrated string, matches []Match) Result {
+	result := Result{File: filename, Original: string(original), Migrated: string(original), Matches: matches}
+	if len(matches) == 0 {
+		return result
+	}
+	for i := range matches {
+		matches[i].Position = encoding.rawOffset(migrated, matches[i].Position)
+	}
+	result.Migrated = encoding.encode(migrated)
+	return result
+}
@@ -0,0 +1,42 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeMigrationFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		text     string
+		encoding fileEncoding
+	}{
+		{name: "LF", data: "a\nb\n", text: "a\nb\n", encoding: fileEncoding{TrailingNewline: true}},
+		{name: "CRLF", data: "a\r\nb\r\n", text: "a\nb\n", encoding: fileEncoding{CRLF: true, TrailingNewline: true}},
+		{name: "Mixed line endings are kept", data: "a\r\nb\n", text: "a\r\nb\n", encoding: fileEncoding{TrailingNewline: true}},
+		{name: "BOM without final newline", data: utf8BOM + "a\r\nb", text: "a\nb", encoding: fileEncoding{BOM: true, CRLF: true}},
+		{name: "Empty", data: "", text: "", encoding: fileEncoding{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, encoding, err := decodeMigrationFile([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != tt.text || encoding != tt.encoding {
+				t.Errorf("Expected %q %+v, got %q %+v", tt.text, tt.encoding, text, encoding)
+			}
+			// Decoding and encoding again gives the original bytes
+			if encoded := encoding.encode(text); encoded != tt.data {
+				t.Errorf("Expected %q after encoding, got %q", tt.data, encoded)
+			}
+		})
+	}
+
+	_, _, err := decodeMigrationFile([]byte("<p>ok</p>\n<p>Gr\xfc\xdfe</p>\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error for Latin-1 content at line 2, got %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Rule sets of the migration rules, each applied to its own file types
+const (
+	RuleSetMarkup = "markup" // HTML and Twig templates
+	RuleSetSCSS   = "scss"   // SCSS stylesheets
+	RuleSetScript = "script" // JavaScript and TypeScript sources
+)
+
+// CommentSyntax is the comment syntax used for TODO notes of a file type
+type CommentSyntax struct {
+	Open  string
+	Close string
+}
+
+// Render returns text as a comment, text may itself contain {# #} comments
+func (c CommentSyntax) Render(text string) string {
+	if c.Open == "" {
+		return text
+	}
+	text = strings.ReplaceAll(text, "{#", c.Open)
+	return strings.ReplaceAll(text, "#}", c.Close)
+}
+
+// htmlComment is the comment syntax of HTML files. HTML comments are not valid
+// inside a tag, so TODO notes for attributes are placed after the tag.
+var htmlComment = CommentSyntax{"<!--", "-->"}
+
+// FileType maps file extensions to the rule set applied to them
+type FileType struct {
+	Name       string
+	Extensions []string // Checked in order, longer extensions first
+	RuleSet    string
+	Comment    CommentSyntax
+}
+
+// fileTypes are the file types that are migrated
+var fileTypes = []FileType{
+	{Name: "twig", Extensions: []string{".html.twig", ".twig"}, RuleSet: RuleSetMarkup, Comment: CommentSyntax{"{#", "#}"}},
+	{Name: "html", Extensions: []string{".html"}, RuleSet: RuleSetMarkup, Comment: htmlComment},
+	{Name: "scss", Extensions: []string{".scss"}, RuleSet: RuleSetSCSS, Comment: CommentSyntax{"/*", "*/"}},
+	{Name: "javascript", Extensions: []string{".js"}, RuleSet: RuleSetScript, Comment: CommentSyntax{"/*", "*/"}},
+	{Name: "typescript", Extensions: []string{".ts"}, RuleSet: RuleSetScript, Comment: CommentSyntax{"/*", "*/"}},
+}
+
+// FileTypes returns the file types that are migrated, Twig templates first
+func FileTypes() []FileType {
+	return append([]FileType(nil), fileTypes...)
+}
+
+// FileTypeFor returns the file type of filename and whether it is migrated
+func FileTypeFor(filename string) (FileType, bool) {
+	lower := strings.ToLower(filename)
+	if strings.HasSuffix(lower, ".min.js") || strings.HasSuffix(lower, ".d.ts") {
+		// Build output and type declarations
+		return FileType{}, false
+	}
+	for _, fileType := range fileTypes {
+		for _, extension := range fileType.Extensions {
+			if strings.HasSuffix(lower, extension) {
+				return fileType, true
+			}
+		}
+	}
+	return FileType{}, false
+}
+
+// RuleSetOf returns the rule set of a migration, markup when unset
+func RuleSetOf(migration Rule) string {
+	if migration.RuleSet == "" {
+		return RuleSetMarkup
+	}
+	return migration.RuleSet
+}
+
+// ruleSetFileType returns the first file type of the rule set of migration. Its
+// examples are migrated as this type and inline scripts use its comment syntax.
+func ruleSetFileType(migration Rule) FileType {
+	for _, fileType := range fileTypes {
+		if fileType.RuleSet == RuleSetOf(migration) {
+			return fileType
+		}
+	}
+	return fileTypes[0]
+}
+
+// ruleApplies reports whether migration applies to files of options.FileType.
+// With ProcessScripts, script rules also apply to inline scripts of markup files.
+func ruleApplies(migration Rule, options Options) bool {
+	ruleSet := RuleSetOf(migration)
+	return ruleSet == options.FileType.RuleSet ||
+		options.ProcessScripts && options.FileType.RuleSet == RuleSetMarkup && ruleSet == RuleSetScript
+}
+
+// ScopeOf returns the scope of a migration. Markup rules default to class lists,
+// SCSS and script rules always apply to the whole file.
+func ScopeOf(migration Rule) string {
+	switch {
+	case RuleSetOf(migration) != RuleSetMarkup:
+		return ScopeFile
+	case migration.Scope == "":
+		return ScopeClass
+	default:
+		return migration.Scope
+	}
+}
+
+// getSCSSMigrations returns the migration rules for SCSS stylesheets
+func getSCSSMigrations() []Rule {
+	// Rules that only add a TODO match up to the end of the statement, so the
+	// comment follows it. media-breakpoint-down() now takes the breakpoint to exclude, so every
+	// breakpoint moves up by one. Larger breakpoints go first so no value moves twice.
+	breakpointTodo := "media-breakpoint-down() now excludes the given breakpoint, the argument was moved up by one: https://getbootstrap.com/docs/5.2/migration/#sass"
+	breakpoints := [][2]string{{"xl", "xxl"}, {"lg", "xl"}, {"md", "lg"}, {"sm", "md"}, {"xs", "sm"}}
+
+	var migrations []Rule
+	for _, breakpoint := range breakpoints {
+		migrations = append(migrations, Rule{
+			Name:        "Breakpoint Down " + breakpoint[0],
+			Pattern:     regexp.MustCompile(`media-breakpoint-down\(\s*['"]?` + breakpoint[0] + `['"]?\s*\)`),
+			Replacement: "media-breakpoint-down(" + breakpoint[1] + ")",
+			Todo:        breakpointTodo,
+			Description: fmt.Sprintf("media-breakpoint-down(%s) → media-breakpoint-down(%s)", breakpoint[0], breakpoint[1]),
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{{
+				Before: "@include media-breakpoint-down(" + breakpoint[0] + ") {",
+				After:  "@include media-breakpoint-down(" + breakpoint[1] + ") " + exampleTodo + " {",
+			}},
+		})
+	}
+
+	return append(migrations, []Rule{
+		{
+			Name:        "Custom Select Variables",
+			Pattern:     regexp.MustCompile(`\$custom-select-([\w-]+)`),
+			Replacement: "$$form-select-$1",
+			Description: "$custom-select-* → $form-select-*",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: "$custom-select-bg: $white;", After: "$form-select-bg: $white;"},
+			},
+		},
+		{
+			Name:        "Custom Range Variables",
+			Pattern:     regexp.MustCompile(`\$custom-range-([\w-]+)`),
+			Replacement: "$$form-range-$1",
+			Description: "$custom-range-* → $form-range-*",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: "$custom-range-thumb-bg: $primary;", After: "$form-range-thumb-bg: $primary;"},
+			},
+		},
+		{
+			Name:        "Custom Control Variables",
+			Pattern:     regexp.MustCompile(`\$custom-(?:control|checkbox|radio|switch|file)-[\w-]+[^;\n]*;?`),
+			Replacement: "$0",
+			Todo:        "Custom control variables were replaced by $form-check-* and $form-file-*: https://getbootstrap.com/docs/5.2/forms/checks-radios/#sass",
+			Description: "$custom-control-* and $custom-file-* variables removed",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: "$custom-control-indicator-size: 1.25rem;", After: "$custom-control-indicator-size: 1.25rem; {# TODO #}"},
+			},
+		},
+		{
+			Name:        "Theme Color Function",
+			Pattern:     regexp.MustCompile(`\btheme-color\(\s*['"]?(\w+)['"]?\s*\)`),
+			Replacement: "$$$1",
+			Description: "theme-color(\"primary\") → $primary",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: `color: theme-color("primary");`, After: "color: $primary;"},
+			},
+		},
+		{
+			Name:        "Theme Color Level",
+			Pattern:     regexp.MustCompile(`theme-color-level\([^;\n]*;?`),
+			Replacement: "$0",
+			Todo:        "theme-color-level() was removed, use tint-color() or shade-color(): https://getbootstrap.com/docs/5.2/customize/sass/#color-functions",
+			Description: "theme-color-level() removed",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: "background: theme-color-level(primary, -10);", After: "background: theme-color-level(primary, -10); {# TODO #}"},
+			},
+		},
+		{
+			Name:        "Color YIQ",
+			Pattern:     regexp.MustCompile(`\bcolor-yiq\(`),
+			Replacement: "color-contrast(",
+			Description: "color-yiq() → color-contrast()",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: "color: color-yiq($primary);", After: "color: color-contrast($primary);"},
+			},
+		},
+		{
+			Name:        "YIQ Text Variables",
+			Pattern:     regexp.MustCompile(`\$yiq-text-(dark|light)\b`),
+			Replacement: "$$color-contrast-$1",
+			Description: "$yiq-text-* → $color-contrast-*",
+			Category:    "scss",
+			RuleSet:     RuleSetSCSS,
+			Examples: []Example{
+				{Before: "$yiq-text-dark: $gray-900;", After: "$color-contrast-dark: $gray-900;"},
+			},
+		},
+	}...)
+}
+
+// getScriptMigrations returns the migration rules for JavaScript and TypeScript sources
+func getScriptMigrations() []Rule {
+	return []Rule{
+		{
+			Name:        "Data Attribute Selectors",
+			Pattern:     regexp.MustCompile(`\[data-(toggle|target|dismiss|slide|ride|parent|spy)([~|^$*]?=|\])`),
+			Replacement: "[data-bs-$1$2",
+			Description: "[data-toggle] selectors → [data-bs-toggle]",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: `document.querySelectorAll('[data-toggle="tooltip"]');`, After: `document.querySelectorAll('[data-bs-toggle="tooltip"]');`},
+			},
+		},
+		{
+			Name:        "Data Attribute Names",
+			Pattern:     regexp.MustCompile(`(['"])data-(toggle|target|dismiss|slide|ride|parent|spy)(['"])`),
+			Replacement: "${1}data-bs-$2$3",
+			Description: "'data-toggle' attribute names → 'data-bs-toggle'",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "el.getAttribute('data-target');", After: "el.getAttribute('data-bs-target');"},
+			},
+		},
+		{
+			Name:        "jQuery Data Keys",
+			Pattern:     regexp.MustCompile(`\.data\(\s*(['"])(toggle|target|dismiss|slide|ride|parent|spy)(['"])`),
+			Replacement: ".data(${1}bs-$2$3",
+			Description: ".data('toggle') → .data('bs-toggle')",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "$(el).data('toggle');", After: "$(el).data('bs-toggle');"},
+			},
+		},
+		{
+			Name:        "jQuery Plugin Calls",
+			Pattern:     regexp.MustCompile(`\)\.(?:button|carousel|collapse|dropdown|modal|popover|scrollspy|tab|toast|tooltip)\([^;\n]*;?`),
+			Replacement: "$0",
+			Todo:        "jQuery plugins were removed, use bootstrap.<Component>.getOrCreateInstance(): https://getbootstrap.com/docs/5.2/getting-started/javascript/#usage",
+			Description: "jQuery Bootstrap plugin API removed",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "$(el).tooltip('show');", After: "$(el).tooltip('show'); {# TODO #}"},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// getFontAwesome6Migrations returns the rules of the fontawesome-5-to-6 pack
+func getFontAwesome6Migrations() []Rule {
+	styles := [][2]string{{"fas", "fa-solid"}, {"far", "fa-regular"}, {"fal", "fa-light"}, {"fad", "fa-duotone"}, {"fab", "fa-brands"}}
+	icons := [][2]string{
+		{"fa-times", "fa-xmark"},
+		{"fa-times-circle", "fa-circle-xmark"},
+		{"fa-trash-alt", "fa-trash-can"},
+		{"fa-edit", "fa-pen-to-square"},
+		{"fa-search", "fa-magnifying-glass"},
+		{"fa-cog", "fa-gear"},
+		{"fa-cogs", "fa-gears"},
+		{"fa-home", "fa-house"},
+		{"fa-sign-in-alt", "fa-right-to-bracket"},
+		{"fa-sign-out-alt", "fa-right-from-bracket"},
+		{"fa-exclamation-triangle", "fa-triangle-exclamation"},
+		{"fa-exclamation-circle", "fa-circle-exclamation"},
+		{"fa-info-circle", "fa-circle-info"},
+		{"fa-check-circle", "fa-circle-check"},
+		{"fa-question-circle", "fa-circle-question"},
+		{"fa-user-circle", "fa-circle-user"},
+		{"fa-shopping-cart", "fa-cart-shopping"},
+		{"fa-external-link-alt", "fa-up-right-from-square"},
+	}
+
+	var migrations []Rule
+	for _, style := range styles {
+		migrations = append(migrations, Rule{
+			Name:        "Style " + style[0],
+			Pattern:     regexp.MustCompile(style[0]),
+			Replacement: style[1],
+			Description: fmt.Sprintf("%s → %s", style[0], style[1]),
+			Category:    "styles",
+			Examples:    []Example{{Before: `<i class="` + style[0] + ` fa-star"></i>`, After: `<i class="` + style[1] + ` fa-star"></i>`}},
+		})
+	}
+	for _, icon := range icons {
+		migrations = append(migrations, Rule{
+			Name:        "Icon " + icon[0],
+			Pattern:     regexp.MustCompile(icon[0]),
+			Replacement: icon[1],
+			Description: fmt.Sprintf("%s → %s", icon[0], icon[1]),
+			Category:    "icons",
+			Examples:    []Example{{Before: `<i class="fa-solid ` + icon[0] + `"></i>`, After: `<i class="fa-solid ` + icon[1] + `"></i>`}},
+		})
+	}
+
+	return append(migrations, Rule{
+		Name:        "Font Family",
+		Pattern:     regexp.MustCompile(`(['"])Font Awesome 5 (Free|Pro|Brands)(['"])`),
+		Replacement: "${1}Font Awesome 6 $2$3",
+		Description: `"Font Awesome 5 Free" → "Font Awesome 6 Free"`,
+		Category:    "scss",
+		RuleSet:     RuleSetSCSS,
+		Examples: []Example{
+			{Before: `font-family: "Font Awesome 5 Free";`, After: `font-family: "Font Awesome 6 Free";`},
+			{Before: `font-family: 'Font Awesome 5 Brands';`, After: `font-family: 'Font Awesome 6 Brands';`},
+		},
+	})
+}
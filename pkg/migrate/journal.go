@@ -0,0 +1,300 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+// JournalsDir is the directory in the state directory holding the undo journals
+const JournalsDir = "migrations"
+
+// Files of an undo journal
+const (
+	JournalFile   = "journal.json"
+	UndoPatchFile = "undo.patch"
+)
+
+// Journal records the files written by a migration run so they can be restored
+type Journal struct {
+	RunID   string         `json:"run_id"`
+	Pack    string         `json:"pack"`
+	Root    string         `json:"root"`
+	Created time.Time      `json:"created"`
+	Files   []JournalEntry `json:"files"`
+
+	dir   string
+	patch strings.Builder
+}
+
+// JournalEntry is a single file written by a run
+type JournalEntry struct {
+	File         string      `json:"file"` // Absolute path
+	Path         string      `json:"path"` // Path in the undo patch
+	Mode         os.FileMode `json:"mode"`
+	OriginalHash string      `json:"original_hash"` // SHA-256 before the migration
+	MigratedHash string      `json:"migrated_hash"` // SHA-256 after the migration
+	Patch        string      `json:"-"`             // Reverse patch of the file
+}
+
+// NewJournal returns the journal of a run of pack over root. The
+// journal directory is only created when the first file is recorded.
+func NewJournal(root, pack string) *Journal {
+	return &Journal{Root: root, Pack: pack}
+}
+
+// Record adds a file to the journal and saves it before the file is written.
+// path is the name of the file in the undo patch.
+func (j *Journal) Record(result Result, path string) error {
+	if j.dir == "" {
+		if err := j.create(); err != nil {
+			return err
+		}
+	}
+
+	absFile, err := filepath.Abs(result.File)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+	info, err := os.Stat(result.File)
+	if err != nil {
+		return fmt.Errorf("error reading file mode: %w", err)
+	}
+
+	patch := unidiff.Unified("a/"+path, "b/"+path, result.Migrated, result.Original, 3)
+	j.Files = append(j.Files, JournalEntry{
+		File:         absFile,
+		Path:         path,
+		Mode:         info.Mode().Perm(),
+		OriginalHash: ContentHash([]byte(result.Original)),
+		MigratedHash: ContentHash([]byte(result.Migrated)),
+		Patch:        patch,
+	})
+	j.patch.WriteString(patch)
+
+	return j.save()
+}
+
+// Forget removes the last recorded file after its write failed
+func (j *Journal) Forget() error {
+	last := j.Files[len(j.Files)-1]
+	j.Files = j.Files[:len(j.Files)-1]
+	patch := strings.TrimSuffix(j.patch.String(), last.Patch)
+	j.patch.Reset()
+	j.patch.WriteString(patch)
+	return j.save()
+}
+
+// Dir returns the journal directory, empty when no file was recorded
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+// create creates a new journal directory named after the current time
+func (j *Journal) create() error {
+	projectDir, err := config.ProjectRoot(j.Root)
+	if err != nil {
+		return err
+	}
+	stateDir, err := config.StateDir(projectDir)
+	if err != nil {
+		return err
+	}
+	parent := filepath.Join(stateDir, JournalsDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("error creating journal directory: %w", err)
+	}
+
+	j.Created = time.Now()
+	base := j.Created.Format("20060102-150405")
+	for n := 1; ; n++ {
+		j.RunID = base
+		if n > 1 {
+			j.RunID = fmt.Sprintf("%s-%d", base, n)
+		}
+		err := os.Mkdir(filepath.Join(parent, j.RunID), 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("error creating journal directory: %w", err)
+		}
+	}
+	j.dir = filepath.Join(parent, j.RunID)
+
+	absRoot, err := filepath.Abs(j.Root)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+	j.Root = absRoot
+	return nil
+}
+
+// save writes the journal and the reverse patch of all recorded files
+func (j *Journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding journal: %w", err)
+	}
+	if err := WriteFileAtomic(filepath.Join(j.dir, JournalFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing journal: %w", err)
+	}
+	if err := WriteFileAtomic(filepath.Join(j.dir, UndoPatchFile), []byte(j.patch.String()), 0644); err != nil {
+		return fmt.Errorf("error writing undo patch: %w", err)
+	}
+	return nil
+}
+
+// JournalRuns returns the run IDs with an undo journal in the project in
+// projectDir, oldest first
+func JournalRuns(projectDir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(config.StatePath(projectDir), JournalsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading journals: %w", err)
+	}
+
+	var runs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			runs = append(runs, entry.Name())
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		// 20250801-142530-10 sorts after 20250801-142530-9
+		if len(runs[i]) != len(runs[j]) && len(runs[i]) > 15 && len(runs[j]) > 15 && runs[i][:15] == runs[j][:15] {
+			return len(runs[i]) < len(runs[j])
+		}
+		return runs[i] < runs[j]
+	})
+	return runs, nil
+}
+
+// LoadJournal loads the journal of runID in the project in projectDir,
+// or of the latest run when runID is empty
+func LoadJournal(projectDir, runID string) (*Journal, error) {
+	runs, err := JournalRuns(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	journalsDir := filepath.Join(config.StatePath(projectDir), JournalsDir)
+	if len(runs) == 0 {
+		return nil, inputError("no migration runs to undo in %s", journalsDir)
+	}
+	if runID == "" {
+		runID = runs[len(runs)-1]
+	} else if !containsString(runs, runID) {
+		return nil, inputError("unknown run %q (available: %s)", runID, strings.Join(runs, ", "))
+	}
+
+	dir := filepath.Join(journalsDir, runID)
+	data, err := os.ReadFile(filepath.Join(dir, JournalFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading journal: %w", err)
+	}
+	var journal Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("error parsing journal %s: %w", dir, err)
+	}
+	journal.dir = dir
+
+	patch, err := os.ReadFile(filepath.Join(dir, UndoPatchFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading undo patch: %w", err)
+	}
+	patches := splitPatch(string(patch))
+	for i := range journal.Files {
+		journal.Files[i].Patch = patches[journal.Files[i].Path]
+	}
+
+	return &journal, nil
+}
+
+// splitPatch splits a patch into the diffs of its files, keyed by the path in the --- header
+func splitPatch(patch string) map[string]string {
+	patches := make(map[string]string)
+	path := ""
+	for _, line := range unidiff.SplitLines(patch) {
+		if strings.HasPrefix(line, "--- a/") {
+			path = strings.TrimSuffix(strings.TrimPrefix(line, "--- a/"), "\n")
+		}
+		patches[path] += line
+	}
+	return patches
+}
+
+// Undo restores the files of the journal and returns the number of restored
+// files. Nothing is restored when a file changed since the migration.
+func (j *Journal) Undo() (int, error) {
+	type restore struct {
+		entry   JournalEntry
+		content string
+	}
+
+	var restores []restore
+	var changed []string
+	for _, entry := range j.Files {
+		content, err := os.ReadFile(entry.File)
+		if err != nil {
+			return 0, fmt.Errorf("error reading %s: %w", entry.File, err)
+		}
+		switch ContentHash(content) {
+		case entry.OriginalHash:
+			// Already restored
+			continue
+		case entry.MigratedHash:
+		default:
+			changed = append(changed, entry.File)
+			continue
+		}
+
+		original, err := unidiff.Apply(string(content), entry.Patch)
+		if err != nil {
+			return 0, fmt.Errorf("error applying undo patch to %s: %w", entry.File, err)
+		}
+		if ContentHash([]byte(original)) != entry.OriginalHash {
+			return 0, fmt.Errorf("undo patch of %s does not restore the original content", entry.File)
+		}
+		restores = append(restores, restore{entry, original})
+	}
+
+	if len(changed) > 0 {
+		return 0, fmt.Errorf("files changed since run %s, nothing was restored:\n  %s", j.RunID, strings.Join(changed, "\n  "))
+	}
+
+	for _, restore := range restores {
+		if err := WriteFileAtomic(restore.entry.File, []byte(restore.content), restore.entry.Mode); err != nil {
+			return 0, fmt.Errorf("error restoring %s: %w", restore.entry.File, err)
+		}
+		if err := os.Chmod(restore.entry.File, restore.entry.Mode); err != nil {
+			return 0, fmt.Errorf("error restoring mode of %s: %w", restore.entry.File, err)
+		}
+	}
+
+	return len(restores), nil
+}
+
+// Remove deletes the journal directory after the run was undone
+func (j *Journal) Remove() error {
+	if err := os.RemoveAll(j.dir); err != nil {
+		return fmt.Errorf("error removing journal: %w", err)
+	}
+	return nil
+}
+
+// ContentHash returns the hex SHA-256 of content
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
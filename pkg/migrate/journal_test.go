@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFiles(t, root, map[string]string{
+		".git/HEAD":   "ref: refs/heads/main\n",
+		"a.html.twig": "<div class=\"ml-2\">A</div>\n",
+	})
+	file := filepath.Join(root, "a.html.twig")
+
+	result, err := File(t.Context(), file, getBootstrapMigrations(), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal := NewJournal(root, BootstrapPackName)
+	if err := journal.Record(result, "a.html.twig"); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(result); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := JournalRuns(root)
+	if err != nil || len(runs) != 1 || runs[0] != journal.RunID {
+		t.Fatalf("Expected run %s, got %v (%v)", journal.RunID, runs, err)
+	}
+	if _, err := LoadJournal(root, "20000101-000000"); err == nil {
+		t.Error("Expected an error for an unknown run")
+	}
+
+	loaded, err := LoadJournal(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored, err := loaded.Undo(); err != nil || restored != 1 {
+		t.Fatalf("Expected one restored file, got %d (%v)", restored, err)
+	}
+	if content, err := os.ReadFile(file); err != nil || string(content) != result.Original {
+		t.Errorf("Expected the original content after undo, got %q (%v)", content, err)
+	}
+
+	if err := loaded.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if runs, err := JournalRuns(root); err != nil || len(runs) != 0 {
+		t.Errorf("Expected no runs after removing the journal, got %v (%v)", runs, err)
+	}
+}
+
+func TestJournalRunsOrder(t *testing.T) {
+	root := t.TempDir()
+	for _, run := range []string{"20250801-142530-10", "20250801-142530", "20250801-142530-9", "20250731-090000"} {
+		if err := os.MkdirAll(filepath.Join(root, ".wswcli", JournalsDir, run), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := JournalRuns(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"20250731-090000", "20250801-142530", "20250801-142530-9", "20250801-142530-10"}
+	if !reflect.DeepEqual(runs, expected) {
+		t.Errorf("Expected runs %v, got %v", expected, runs)
+	}
+}
@@ -0,0 +1,267 @@
+// Package migrate rewrites HTML and Twig templates, SCSS stylesheets and
+// JavaScript and TypeScript sources with the rules of versioned migration packs
+// such as Bootstrap 4 to 5.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Rule is a single rewrite rule of a migration pack
+type Rule struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+	Todo        string // Note rendered as a TODO comment after the replacement
+	Description string
+	Category    string
+	Scope       string    // ScopeClass (default), ScopeAttribute or ScopeFile
+	RuleSet     string    // RuleSetMarkup (default), RuleSetSCSS or RuleSetScript
+	Transform   Transform // Structural rewrite used instead of Pattern for ScopeStructure
+	Source      string    // "built-in" or the rule file the rule was loaded from
+	Examples    []Example // Checked by the tests and "rules verify"
+}
+
+// Pack is a named, versioned set of migration rules run by wswcli migrate
+type Pack struct {
+	Name        string // Name on the command line, e.g. bootstrap-4-to-5
+	Title       string // Name in output, e.g. Bootstrap 4 to 5
+	Description string
+	Aliases     []string
+	Rules       func() []Rule
+	Checks      func() []Rule // Additional rules applied with --check, optional
+}
+
+// BootstrapPackName is the pack run by bs-4-to-5
+const BootstrapPackName = "bootstrap-4-to-5"
+
+// packs are the packs wswcli migrate can run
+var packs = []Pack{
+	{
+		Name:        BootstrapPackName,
+		Title:       "Bootstrap 4 to 5",
+		Description: "Bootstrap 4 classes, components, Sass and data attributes to Bootstrap 5",
+		Aliases:     []string{"bs-4-to-5"},
+		Rules:       getBootstrapMigrations,
+		Checks:      getBootstrap4Removals,
+	},
+	{
+		Name:        "bootstrap-5.0-to-5.3",
+		Title:       "Bootstrap 5.0 to 5.3",
+		Description: "Deprecated color scheme classes to data-bs-theme, text-muted and opacity utilities",
+		Rules:       getBootstrap53Migrations,
+	},
+	{
+		Name:        "shopware-6.4-to-6.5",
+		Title:       "Shopware 6.4 to 6.5",
+		Description: "Storefront Twig and JavaScript changes of Shopware 6.5 (CSRF, HttpClient, jQuery, feature flags)",
+		Rules:       getShopware65Migrations,
+	},
+	{
+		Name:        "fontawesome-5-to-6",
+		Title:       "Font Awesome 5 to 6",
+		Description: "Font Awesome 5 style prefixes and renamed icons to Font Awesome 6",
+		Rules:       getFontAwesome6Migrations,
+	},
+}
+
+// Packs returns the available migration packs
+func Packs() []Pack {
+	return append([]Pack(nil), packs...)
+}
+
+// FindPack returns the pack with the given name or alias
+func FindPack(name string) (Pack, error) {
+	var names []string
+	for _, pack := range packs {
+		if pack.Name == name || containsString(pack.Aliases, name) {
+			return pack, nil
+		}
+		names = append(names, pack.Name)
+	}
+	return Pack{}, inputError("unknown migration pack: %s (available: %s)", name, strings.Join(names, ", "))
+}
+
+// FindFiles finds all files with a migrated file type below rootPath, see
+// FileTypes. Dependency, build and hidden directories are skipped, symlinks are
+// only followed to files inside rootPath. Skipped entries are logged to log,
+// which may be nil.
+func FindFiles(ctx context.Context, rootPath string, recursive bool, log *slog.Logger) ([]string, error) {
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	var files []string
+	seen := make(map[string]bool)
+
+	resolvedRoot, err := filepath.EvalSymlinks(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if resolvedRoot, err = filepath.Abs(resolvedRoot); err != nil {
+		return nil, err
+	}
+
+	walkFunc := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Skip directories we don't want to process
+		if info.IsDir() {
+			name := info.Name()
+			// Skip if not recursive and it's not the root path
+			if !recursive && path != rootPath {
+				return filepath.SkipDir
+			}
+			// Skip common directories, but not the root path we're scanning (e.g. ".")
+			if path != rootPath && (strings.HasPrefix(name, ".") ||
+				name == "node_modules" ||
+				name == "vendor" ||
+				name == "var" ||
+				name == "cache" ||
+				name == "build" ||
+				name == "dist") {
+				log.Log(ctx, levelTrace, fmt.Sprintf("Skipping directory %s", path))
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if _, ok := FileTypeFor(path); !ok {
+			return nil
+		}
+
+		// Symlinked files are migrated through their target, so the link stays a
+		// link, and only when the target is inside the project. Broken links are
+		// kept and fail when they are read.
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := filepath.EvalSymlinks(path); err == nil {
+				target, _ = filepath.Abs(target)
+				rel, err := filepath.Rel(resolvedRoot, target)
+				if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					log.Warn(fmt.Sprintf("Skipping %s: symlink points outside the project", path))
+					return nil
+				}
+				if targetInfo, err := os.Stat(target); err != nil || !targetInfo.Mode().IsRegular() {
+					return nil
+				}
+				path = filepath.Join(rootPath, rel)
+			}
+		}
+
+		if !seen[filepath.Clean(path)] {
+			seen[filepath.Clean(path)] = true
+			files = append(files, path)
+		}
+		return nil
+	}
+
+	err = filepath.Walk(rootPath, walkFunc)
+	return files, err
+}
+
+// Result is the result of migrating a single file
+type Result struct {
+	File     string
+	Original string
+	Migrated string
+	Matches  []Match
+}
+
+// File applies the migrations to the content of a single file and returns the
+// original and migrated content without writing the file. The byte order mark,
+// line endings and final newline of the file are kept. The file type of
+// options is taken from filename.
+func File(ctx context.Context, filename string, migrations []Rule, options Options) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{File: filename}, err
+	}
+
+	// Read file content
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return Result{File: filename}, err
+	}
+
+	text, encoding, err := decodeMigrationFile(content)
+	if err != nil {
+		return Result{File: filename}, err
+	}
+
+	options.FileType, _ = FileTypeFor(filename)
+	modifiedContent, matches := Content(text, migrations, options)
+	return encodedMigration(filename, content, encoding, modifiedContent, matches), nil
+}
+
+// WriteFile writes the migrated content of result back to its file if changes
+// were made, keeping the permissions of the file
+func WriteFile(result Result) error {
+	if len(result.Matches) == 0 {
+		return nil
+	}
+	if err := WriteFileAtomic(result.File, []byte(result.Migrated), 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it
+// over path, so readers never see a partially written file. An existing file
+// keeps its permissions, new files get perm.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// levelTrace is the log level of skipped directories, below debug
+const levelTrace = slog.LevelDebug - 4
+
+// InputError is returned for invalid input such as unknown packs, rules or
+// categories and malformed rule files, as opposed to failures reading or
+// writing files
+type InputError struct {
+	Err error
+}
+
+func (e *InputError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
+// inputError returns an InputError with a formatted message
+func inputError(format string, args ...interface{}) error {
+	return &InputError{Err: fmt.Errorf(format, args...)}
+}
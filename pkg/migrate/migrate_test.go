@@ -0,0 +1,214 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeProjectFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for filePath, content := range files {
+		fullPath := filepath.Join(root, filePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindFiles(t *testing.T) {
+	// Create temporary directory structure
+	tempDir := t.TempDir()
+
+	// Create test files
+	testFiles := []string{
+		"template.html",
+		"template.html.twig",
+		"template.twig",
+		"subdir/another.html",
+		"subdir/another.html.twig",
+		"subdir/nested/deep.twig",
+		"main.js",
+		"styles/base.scss",
+		"src/plugin/plugin.ts",
+		"regular.txt",               // Should be ignored
+		"app.min.js",                // Should be ignored (minified)
+		"src/types.d.ts",            // Should be ignored (type declarations)
+		"dist/storefront/app.js",    // Should be ignored (dist)
+		"node_modules/ignored.html", // Should be ignored (node_modules)
+		".hidden/ignored.html.twig", // Should be ignored (hidden dir)
+		"vendor/ignored.twig",       // Should be ignored (vendor)
+		"var/cache/ignored.html",    // Should be ignored (var)
+	}
+
+	for _, file := range testFiles {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Test FindFiles with recursive=true
+	found, err := FindFiles(context.Background(), tempDir, true, nil)
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+
+	// Should find 6 template files (.html, .html.twig, .twig), 1 SCSS and 2 script files
+	expectedCount := 9
+	if len(found) != expectedCount {
+		t.Errorf("Expected %d files, got %d: %v", expectedCount, len(found), found)
+	}
+
+	// Check that all found files have correct extensions
+	validExtensions := []string{".html", ".html.twig", ".twig", ".scss", ".js", ".ts"}
+	for _, file := range found {
+		hasValidExtension := false
+		for _, ext := range validExtensions {
+			if strings.HasSuffix(strings.ToLower(file), ext) {
+				hasValidExtension = true
+				break
+			}
+		}
+		if !hasValidExtension {
+			t.Errorf("Found file doesn't have valid extension: %s", file)
+		}
+	}
+
+	// Test FindFiles with recursive=false
+	found, err = FindFiles(context.Background(), tempDir, false, nil)
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+
+	// Should find only root level files: 3 templates and main.js
+	expectedCount = 4
+	if len(found) != expectedCount {
+		t.Errorf("Expected %d files with recursive=false, got %d: %v", expectedCount, len(found), found)
+	}
+}
+
+func TestFindPack(t *testing.T) {
+	for _, name := range []string{"bootstrap-4-to-5", "bs-4-to-5", "fontawesome-5-to-6"} {
+		pack, err := FindPack(name)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", name, err)
+		}
+		if pack.Name != name && !containsString(pack.Aliases, name) {
+			t.Errorf("Expected pack %s, got %s", name, pack.Name)
+		}
+	}
+
+	_, err := FindPack("bootstrap-3-to-4")
+	var inputErr *InputError
+	if !errors.As(err, &inputErr) {
+		t.Fatalf("Expected input error for unknown pack, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "shopware-6.4-to-6.5") {
+		t.Errorf("Expected available packs in error, got %v", err)
+	}
+}
+
+func TestPackRules(t *testing.T) {
+	for _, pack := range Packs() {
+		t.Run(pack.Name, func(t *testing.T) {
+			names := make(map[string]bool)
+			for _, rule := range pack.Rules() {
+				if names[rule.Name] {
+					t.Errorf("Duplicate rule name %s", rule.Name)
+				}
+				names[rule.Name] = true
+				if rule.Pattern == nil && rule.Transform == nil {
+					t.Errorf("Rule %s has neither a pattern nor a transform", rule.Name)
+				}
+				if rule.Category == "" {
+					t.Errorf("Rule %s has no category", rule.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestPacks(t *testing.T) {
+	tests := []struct {
+		pack     string
+		file     string
+		input    string
+		expected string
+	}{
+		{
+			pack:     "bootstrap-5.0-to-5.3",
+			file:     "page.html.twig",
+			input:    `<nav class="navbar navbar-dark bg-dark"><p class="text-muted text-white-50">x</p></nav>`,
+			expected: `<nav data-bs-theme="dark" class="navbar bg-dark"><p class="text-body-secondary text-white text-opacity-50">x</p></nav>`,
+		},
+		{
+			pack:     "bootstrap-5.0-to-5.3",
+			file:     "page.html.twig",
+			input:    `<button class="btn-close btn-close-white"></button>`,
+			expected: `<button data-bs-theme="dark" class="btn-close"></button>`,
+		},
+		{
+			// An element with its own theme keeps the class and gets a TODO
+			pack:     "bootstrap-5.0-to-5.3",
+			file:     "page.html.twig",
+			input:    `<ul class="dropdown-menu dropdown-menu-dark" data-bs-theme="light"></ul>`,
+			expected: `<ul class="dropdown-menu dropdown-menu-dark {# TODO: Deprecated in Bootstrap 5.3, set data-bs-theme on the element: https://getbootstrap.com/docs/5.3/customize/color-modes/ #}" data-bs-theme="light"></ul>`,
+		},
+		{
+			pack:     "fontawesome-5-to-6",
+			file:     "icons.html.twig",
+			input:    `<i class="fas fa-times"></i><i class="far fa-times-circle"></i><i class="fab fa-github"></i>`,
+			expected: `<i class="fa-solid fa-xmark"></i><i class="fa-regular fa-circle-xmark"></i><i class="fa-brands fa-github"></i>`,
+		},
+		{
+			pack:     "fontawesome-5-to-6",
+			file:     "icons.scss",
+			input:    ".icon::before { font-family: 'Font Awesome 5 Free'; }\n",
+			expected: ".icon::before { font-family: 'Font Awesome 6 Free'; }\n",
+		},
+		{
+			pack:     "shopware-6.4-to-6.5",
+			file:     "login.html.twig",
+			input:    "<form>\n    {{ sw_csrf('frontend.account.login') }}\n    <button>Login</button>\n</form>\n",
+			expected: "<form>\n    <button>Login</button>\n</form>\n",
+		},
+		{
+			pack:     "shopware-6.4-to-6.5",
+			file:     "plugin.js",
+			input:    "import $ from 'jquery';\nconst client = new HttpClient(window.accessKey, window.contextToken);\n",
+			expected: "import $ from 'jquery'; /* TODO: jQuery was removed from the Storefront in Shopware 6.5, use plain JavaScript */\nconst client = new HttpClient();\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pack+"/"+tt.file, func(t *testing.T) {
+			pack, err := FindPack(tt.pack)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fileType, ok := FileTypeFor(tt.file)
+			if !ok {
+				t.Fatalf("No file type for %s", tt.file)
+			}
+
+			migrated, _ := Content(tt.input, pack.Rules(), Options{FileType: fileType})
+			if migrated != tt.expected {
+				t.Errorf("Unexpected migration:\n%s\nExpected:\n%s", migrated, tt.expected)
+			}
+			// Packs are idempotent like bs-4-to-5
+			if again, matches := Content(migrated, pack.Rules(), Options{FileType: fileType}); again != migrated {
+				t.Errorf("Second run changed the content (%d matches):\n%s", len(matches), again)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package migrate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// protectedElements are elements whose body is not markup: scripts and styles,
+// and code samples and text shown as written
+var protectedElements = []string{"script", "style", "pre", "code", "textarea"}
+
+var (
+	verbatimTagRegex    = regexp.MustCompile(`^\{%-?\s*(?:verbatim|raw)\s*-?%\}$`)
+	endVerbatimTagRegex = regexp.MustCompile(`\{%-?\s*end(?:verbatim|raw)\s*-?%\}`)
+	migrateMarkerRegex  = regexp.MustCompile(`(?:\{#-?|<!--|/\*|//)\s*wswcli-migrate-(off|on)\b`)
+	scriptTypeRegex     = regexp.MustCompile(`(?i)\stype\s*=\s*["']?([^"'\s>]*)`)
+)
+
+// protectedRegions holds the parts of a file migrations leave alone
+type protectedRegions struct {
+	Ranges  [][2]int // Byte ranges no rule rewrites, sorted by start
+	Scripts [][2]int // Bodies of inline JavaScript, only class calls and script rules rewrite them
+}
+
+// findProtected returns the protected parts of content. In markup these are
+// comments, {% verbatim %} blocks and the bodies of script, style, pre, code and
+// textarea elements; in every file type the parts between wswcli-migrate-off and
+// wswcli-migrate-on markers. Inline JavaScript is returned as Scripts.
+func findProtected(content string, options Options) protectedRegions {
+	var protected protectedRegions
+	if options.FileType.RuleSet == RuleSetMarkup {
+		for i := 0; i < len(content); {
+			switch {
+			case strings.HasPrefix(content[i:], "{#"):
+				end := skipPast(content, i+2, "#}")
+				protected.Ranges = append(protected.Ranges, [2]int{i, end})
+				i = end
+			case strings.HasPrefix(content[i:], "<!--"):
+				end := skipPast(content, i+4, "-->")
+				protected.Ranges = append(protected.Ranges, [2]int{i, end})
+				i = end
+			case strings.HasPrefix(content[i:], "{{"), strings.HasPrefix(content[i:], "{%"):
+				end := twigConstructEnd(content, i)
+				if verbatimTagRegex.MatchString(content[i:end]) {
+					blockEnd := len(content)
+					if closing := endVerbatimTagRegex.FindStringIndex(content[end:]); closing != nil {
+						blockEnd = end + closing[1]
+					}
+					protected.Ranges = append(protected.Ranges, [2]int{i, blockEnd})
+					end = blockEnd
+				}
+				i = end
+			case content[i] == '<' && i+1 < len(content) && isASCIILetter(content[i+1]):
+				i = protectElementBody(content, i, &protected)
+			default:
+				i++
+			}
+		}
+	}
+
+	off := -1
+	for _, marker := range migrateMarkerRegex.FindAllStringSubmatchIndex(content, -1) {
+		switch state := content[marker[2]:marker[3]]; {
+		case state == "off" && off < 0:
+			off = marker[0]
+		case state == "on" && off >= 0:
+			protected.Ranges = append(protected.Ranges, [2]int{off, marker[1]})
+			off = -1
+		}
+	}
+	if off >= 0 {
+		protected.Ranges = append(protected.Ranges, [2]int{off, len(content)})
+	}
+
+	sort.Slice(protected.Ranges, func(i, j int) bool { return protected.Ranges[i][0] < protected.Ranges[j][0] })
+	return protected
+}
+
+// protectElementBody adds the body of the element starting at start when it is a
+// protected element and returns the offset to continue at
+func protectElementBody(content string, start int, protected *protectedRegions) int {
+	nameEnd := start + 1
+	for nameEnd < len(content) && isTagNameChar(content[nameEnd]) {
+		nameEnd++
+	}
+	name := strings.ToLower(content[start+1 : nameEnd])
+	openEnd := openTagEnd(content, nameEnd)
+	if !containsString(protectedElements, name) || strings.HasSuffix(content[start:openEnd], "/>") {
+		return openEnd
+	}
+
+	bodyEnd := len(content)
+	if closing := strings.Index(strings.ToLower(content[openEnd:]), "</"+name); closing >= 0 {
+		bodyEnd = openEnd + closing
+	}
+	body := [2]int{openEnd, bodyEnd}
+	if name == "script" && isJavaScriptTag(content[start:openEnd]) {
+		protected.Scripts = append(protected.Scripts, body)
+	} else {
+		protected.Ranges = append(protected.Ranges, body)
+	}
+	return bodyEnd
+}
+
+// openTagEnd returns the offset after the > closing the tag whose attributes start at start
+func openTagEnd(content string, start int) int {
+	for i := start; i < len(content); {
+		switch c := content[i]; {
+		case c == '>':
+			return i + 1
+		case c == '"' || c == '\'':
+			i = attributeValueEnd(content, i+1, c) + 1
+		case strings.HasPrefix(content[i:], "{#"):
+			i = skipPast(content, i+2, "#}")
+		case strings.HasPrefix(content[i:], "{{"), strings.HasPrefix(content[i:], "{%"):
+			i = twigConstructEnd(content, i)
+		default:
+			i++
+		}
+	}
+	return len(content)
+}
+
+// isJavaScriptTag reports whether a script open tag holds JavaScript rather than
+// data such as JSON or client-side templates
+func isJavaScriptTag(tag string) bool {
+	match := scriptTypeRegex.FindStringSubmatch(tag)
+	if match == nil {
+		return true
+	}
+	scriptType := strings.ToLower(match[1])
+	return scriptType == "" || scriptType == "module" || strings.HasSuffix(scriptType, "javascript") || strings.HasSuffix(scriptType, "ecmascript")
+}
+
+// maskProtected masks the parts of region inside ranges so no rule matches them
+func maskProtected(region contentRegion, ranges [][2]int) contentRegion {
+	end := region.Start + len(region.Text)
+	var text []byte
+	for _, r := range ranges {
+		from, to := max(r[0], region.Start), min(r[1], end)
+		if from >= to {
+			continue
+		}
+		if text == nil {
+			text = []byte(region.Text)
+		}
+		for k := from; k < to; k++ {
+			text[k-region.Start] = maskSplit
+		}
+	}
+	if text == nil {
+		return region
+	}
+	region.Text = string(text)
+	return region
+}
+
+// inRanges reports whether offset lies inside one of ranges
+func inRanges(offset int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
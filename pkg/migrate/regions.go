@@ -0,0 +1,672 @@
+package migrate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Scopes in which a migration rule is applied
+const (
+	ScopeClass     = "class"     // Class lists: class attributes, Twig class strings and addClass-style calls
+	ScopeAttribute = "attribute" // Attribute names of HTML tags, e.g. data-toggle
+	ScopeFile      = "file"      // The whole file content
+	ScopeStructure = "structure" // Elements rewritten by a structural transform, built-in rules only
+)
+
+// Placeholder bytes for masked Twig and HTML constructs inside class regions.
+// Both keep the region the same length as the original content so offsets stay valid.
+const (
+	maskJoin  = '\x00' // {{ }} output, glued to the surrounding token (e.g. col-{{ size }})
+	maskSplit = '\x01' // {% %} tags and comments, separating the surrounding tokens
+)
+
+// contentRegion is a part of a file a migration rule may rewrite
+type contentRegion struct {
+	Start  int    // Byte offset of the region in the file content
+	Text   string // Region content with masked constructs, same length as the original
+	TagEnd int    // Offset after the > of the HTML tag holding the region, 0 outside of tags
+}
+
+// Match is a single rewrite made by a migration rule
+type Match struct {
+	Rule        string
+	Start       int // Byte offset in the content the rule was applied to
+	End         int
+	Original    string
+	Replacement string
+	Todo        string // TODO comment inserted with the replacement
+	Position    int    // Byte offset of the replacement in the migrated content
+}
+
+// Decider is called for every match before it is applied. content is the
+// file content with all earlier accepted changes and match refers to it. It returns
+// the replacement to use and whether the match is applied.
+type Decider func(content string, match Match) (string, bool)
+
+// Options controls how migration rules find their matches
+type Options struct {
+	TwigInterpolation bool     // Also rewrite string literals inside {{ }} in class attributes
+	ProcessScripts    bool     // Also apply script rules to inline <script> blocks of markup files
+	FileType          FileType // Selects the rule set and comment syntax, Twig when unset
+	Decide            Decider  // Optional review of each match, nil applies all matches
+}
+
+// markupRegions holds the regions of a file per scope
+type markupRegions struct {
+	Class     []contentRegion
+	Attribute []contentRegion
+}
+
+var (
+	classCallRegex    = regexp.MustCompile(`(?:\b(?:addClass|removeClass|toggleClass|hasClass)|\bclassList\.(?:add|remove|toggle|contains|replace))\s*\(`)
+	twigClassKeyRegex = regexp.MustCompile(`(?i)['"]?[\w-]*class(?:es|name)?['"]?\s*:\s*`)
+)
+
+// Content applies all migrations in order and returns the new content
+// with every rewrite that was made
+func Content(content string, migrations []Rule, options Options) (string, []Match) {
+	if options.FileType.Name == "" {
+		options.FileType = fileTypes[0]
+	}
+
+	var all []Match
+	for _, migration := range migrations {
+		if !ruleApplies(migration, options) {
+			continue
+		}
+		var matches []Match
+		content, matches = applyMigration(content, migration, options)
+		for i := range all {
+			all[i].Position = shiftOffset(all[i].Position, matches)
+		}
+		all = append(all, matches...)
+	}
+	return content, all
+}
+
+// shiftOffset maps an offset in the content before matches were applied to the
+// content after, offsets inside a replaced match move to its start
+func shiftOffset(offset int, matches []Match) int {
+	shifted := offset
+	for _, match := range matches {
+		switch {
+		case match.End <= offset:
+			shifted += len(match.Replacement) - (match.End - match.Start)
+		case match.Start < offset:
+			shifted -= offset - match.Start
+		}
+	}
+	return shifted
+}
+
+// applyMigration applies a single migration rule within its scope
+func applyMigration(content string, migration Rule, options Options) (string, []Match) {
+	// Script rules applied to a markup file only rewrite its inline scripts
+	inlineScripts := RuleSetOf(migration) != options.FileType.RuleSet
+	syntax := options.FileType.Comment
+	if inlineScripts {
+		syntax = ruleSetFileType(migration).Comment
+	}
+
+	// {# #} comments in replacements use the comment syntax of the file
+	replacement := migration.Replacement
+	if strings.Contains(replacement, "{#") && strings.Contains(replacement, "#}") {
+		replacement = syntax.Render(replacement)
+	}
+
+	// The TODO comment marks a match as migrated, comments left by earlier runs are collapsed to one
+	var matches []Match
+	comment := ""
+	plain := replacement
+	if migration.Todo != "" {
+		comment = syntax.Render("{# TODO: " + migration.Todo + " #}")
+		replacement += " " + strings.ReplaceAll(comment, "$", "$$")
+		matches = duplicateComments(content, comment, migration.Name)
+	}
+
+	scope := ScopeOf(migration)
+	// HTML comments cannot go inside a tag, their TODO is inserted after the tag
+	todoAfterTag := comment != "" && syntax == htmlComment && (scope == ScopeClass || scope == ScopeAttribute)
+	tagTodos := make(map[int]bool) // Tag ends with an inserted TODO
+	tagOf := make(map[int]int)     // Start of a rewrite inside a tag to the offset after the tag
+	protected := findProtected(content, options)
+
+	if migration.Transform != nil {
+		for _, match := range migration.Transform(content, parseElements(content)) {
+			if !inRanges(match.Start, protected.Ranges) {
+				matches = append(matches, match)
+			}
+		}
+	}
+
+	var regions []contentRegion
+	masked := protected.Ranges
+	switch scope {
+	case ScopeFile:
+		if inlineScripts {
+			for _, script := range protected.Scripts {
+				region := fileRegion(content[script[0]:script[1]], RuleSetScript)
+				regions = append(regions, contentRegion{Start: script[0], Text: region.Text})
+			}
+			break
+		}
+		regions = []contentRegion{fileRegion(content, options.FileType.RuleSet)}
+		// Inline scripts are left to class calls and, with ProcessScripts, the script rules
+		masked = append(masked[:len(masked):len(masked)], protected.Scripts...)
+	case ScopeStructure:
+		// Matches come from the transform
+	case ScopeAttribute:
+		regions = scanMarkup(content, options.TwigInterpolation).Attribute
+	default:
+		regions = scanMarkup(content, options.TwigInterpolation).Class
+	}
+
+	for _, region := range regions {
+		region = maskProtected(region, masked)
+		for _, loc := range migration.Pattern.FindAllStringSubmatchIndex(region.Text, -1) {
+			start, end := loc[0], loc[1]
+			if start == end || !matchInScope(scope, region.Text, start, end) {
+				continue
+			}
+			match := Match{
+				Rule:        migration.Name,
+				Start:       region.Start + start,
+				End:         region.Start + end,
+				Original:    content[region.Start+start : region.Start+end],
+				Replacement: string(migration.Pattern.ExpandString(nil, replacement, region.Text, loc)),
+			}
+			if todoAfterTag {
+				// Without a tag to place it after, the TODO is left out
+				match.Replacement = string(migration.Pattern.ExpandString(nil, plain, region.Text, loc))
+				// A tag already followed by the TODO comment was migrated before
+				if region.TagEnd > 0 && !tagTodos[region.TagEnd] && !strings.HasPrefix(strings.TrimLeft(content[region.TagEnd:], " \t\r\n"), comment) {
+					tagTodos[region.TagEnd] = true
+					matches = append(matches, Match{Rule: migration.Name, Start: region.TagEnd, End: region.TagEnd, Replacement: comment})
+				}
+				if match.Replacement == match.Original {
+					continue
+				}
+				tagOf[match.Start] = region.TagEnd
+				matches = append(matches, match)
+				continue
+			}
+			// A match already followed by its TODO comment was migrated before
+			if match.Replacement != "" && strings.HasPrefix(content[match.Start:], match.Replacement) ||
+				comment != "" && strings.HasPrefix(strings.TrimLeft(content[match.End:], " \t\r\n"), comment) {
+				continue
+			}
+			matches = append(matches, match)
+		}
+	}
+
+	if len(matches) == 0 {
+		return content, nil
+	}
+
+	// Regions may overlap when a literal is found twice, keep the first rewrite
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	kept := matches[:0]
+	for _, match := range matches {
+		if len(kept) > 0 && match.Start < kept[len(kept)-1].End {
+			continue
+		}
+		kept = append(kept, match)
+	}
+
+	var builder strings.Builder
+	var applied []Match
+	reviewed := make(map[int]bool) // Tags with a rewrite, and whether one was accepted
+	last := 0
+	for _, match := range kept {
+		builder.WriteString(content[last:match.Start])
+
+		if accepted, ok := reviewed[match.Start]; ok && match.Start == match.End && tagTodos[match.Start] {
+			// The TODO after a tag follows the rewrites inside the tag
+			if !accepted {
+				last = match.End
+				continue
+			}
+		} else if options.Decide != nil {
+			// Show the decider the content with all changes accepted so far
+			current := match
+			current.Start = builder.Len()
+			current.End = current.Start + len(match.Original)
+			replacement, accept := options.Decide(builder.String()+content[match.Start:], current)
+			if tagEnd, ok := tagOf[match.Start]; ok {
+				reviewed[tagEnd] = reviewed[tagEnd] || accept
+			}
+			if !accept {
+				builder.WriteString(match.Original)
+				last = match.End
+				continue
+			}
+			match.Replacement = replacement
+		}
+
+		match.Position = builder.Len()
+		if comment != "" && strings.Contains(match.Replacement, comment) {
+			match.Todo = comment
+		}
+		builder.WriteString(match.Replacement)
+		applied = append(applied, match)
+		last = match.End
+	}
+	builder.WriteString(content[last:])
+
+	return builder.String(), applied
+}
+
+// duplicateComments returns matches removing repetitions of comment that are
+// only separated by whitespace, keeping the first one
+func duplicateComments(content, comment, rule string) []Match {
+	var matches []Match
+	pos := 0
+	for {
+		index := strings.Index(content[pos:], comment)
+		if index < 0 {
+			return matches
+		}
+		start := pos + index + len(comment)
+		end := start
+		for {
+			next := end
+			for next < len(content) && isSpace(content[next]) {
+				next++
+			}
+			if !strings.HasPrefix(content[next:], comment) {
+				break
+			}
+			end = next + len(comment)
+		}
+		if end > start {
+			matches = append(matches, Match{Rule: rule, Start: start, End: end, Original: content[start:end]})
+		}
+		pos = end
+	}
+}
+
+// matchInScope reports whether a match inside a region respects the token
+// boundaries of the scope
+func matchInScope(scope, text string, start, end int) bool {
+	switch scope {
+	case ScopeFile:
+		// Text inside comments, including TODO comments of earlier runs, is left alone
+		return !strings.ContainsRune(text[start:end], maskSplit)
+	case ScopeAttribute:
+		// Attribute names match as a whole or as a prefix, e.g. data-slide-to
+		return start == 0 && (end == len(text) || text[end] == '-')
+	}
+
+	if strings.ContainsAny(text[start:end], string([]byte{maskJoin, maskSplit})) {
+		return false
+	}
+	if start > 0 && !isClassSeparator(text[start-1]) {
+		return false
+	}
+	if end < len(text) && !isClassSeparator(text[end]) {
+		return false
+	}
+	return true
+}
+
+// isClassSeparator reports whether c separates two tokens of a class list
+func isClassSeparator(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == maskSplit
+}
+
+// scanMarkup finds class list and attribute name regions in HTML and Twig content
+func scanMarkup(content string, interpolation bool) markupRegions {
+	var regions markupRegions
+
+	for i := 0; i < len(content); {
+		switch {
+		case strings.HasPrefix(content[i:], "{#"):
+			i = skipPast(content, i+2, "#}")
+		case strings.HasPrefix(content[i:], "{{"), strings.HasPrefix(content[i:], "{%"):
+			end := twigConstructEnd(content, i)
+			regions.Class = append(regions.Class, twigClassLiterals(content, i, end)...)
+			i = end
+		case strings.HasPrefix(content[i:], "<!--"):
+			i = skipPast(content, i+4, "-->")
+		case content[i] == '<' && i+1 < len(content) && isASCIILetter(content[i+1]):
+			i = scanTag(content, i, interpolation, &regions)
+		default:
+			i++
+		}
+	}
+
+	regions.Class = append(regions.Class, classCallRegions(content)...)
+	return regions
+}
+
+// scanTag collects attribute names and class values of the HTML tag starting at
+// start and returns the offset after the tag. The bodies of script and style
+// elements are skipped.
+func scanTag(content string, start int, interpolation bool, regions *markupRegions) int {
+	classes, attributes := len(regions.Class), len(regions.Attribute)
+	i := start + 1
+	for i < len(content) && isTagNameChar(content[i]) {
+		i++
+	}
+	tagName := strings.ToLower(content[start+1 : i])
+
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '>':
+			i++
+			for k := classes; k < len(regions.Class); k++ {
+				regions.Class[k].TagEnd = i
+			}
+			for k := attributes; k < len(regions.Attribute); k++ {
+				regions.Attribute[k].TagEnd = i
+			}
+			if tagName == "script" || tagName == "style" {
+				closing := strings.Index(strings.ToLower(content[i:]), "</"+tagName)
+				if closing < 0 {
+					return len(content)
+				}
+				return i + closing
+			}
+			return i
+		case strings.HasPrefix(content[i:], "{#"):
+			i = skipPast(content, i+2, "#}")
+		case strings.HasPrefix(content[i:], "{{"), strings.HasPrefix(content[i:], "{%"):
+			end := twigConstructEnd(content, i)
+			regions.Class = append(regions.Class, twigClassLiterals(content, i, end)...)
+			i = end
+		case isSpace(c) || c == '/':
+			i++
+		default:
+			nameStart := i
+			for i < len(content) && !isSpace(content[i]) && !strings.ContainsRune("=>/", rune(content[i])) && !isTwigStart(content, i) {
+				i++
+			}
+			if i == nameStart {
+				i++
+				continue
+			}
+			name := content[nameStart:i]
+			regions.Attribute = append(regions.Attribute, contentRegion{Start: nameStart, Text: name})
+
+			k := i
+			for k < len(content) && isSpace(content[k]) {
+				k++
+			}
+			if k >= len(content) || content[k] != '=' {
+				continue
+			}
+			k++
+			for k < len(content) && isSpace(content[k]) {
+				k++
+			}
+			if k >= len(content) {
+				return k
+			}
+
+			quote := content[k]
+			if quote != '"' && quote != '\'' {
+				// Unquoted values are left alone
+				for k < len(content) && !isSpace(content[k]) && content[k] != '>' {
+					k++
+				}
+				i = k
+				continue
+			}
+
+			valueEnd := attributeValueEnd(content, k+1, quote)
+			if strings.EqualFold(name, "class") {
+				regions.Class = append(regions.Class, classValueRegions(content, k+1, valueEnd, interpolation)...)
+			}
+			i = valueEnd + 1
+		}
+	}
+
+	return len(content)
+}
+
+// attributeValueEnd returns the offset of the quote closing an attribute value,
+// treating Twig constructs inside the value as units
+func attributeValueEnd(content string, start int, quote byte) int {
+	for i := start; i < len(content); {
+		switch {
+		case content[i] == quote:
+			return i
+		case strings.HasPrefix(content[i:], "{#"):
+			i = skipPast(content, i+2, "#}")
+		case strings.HasPrefix(content[i:], "{{"), strings.HasPrefix(content[i:], "{%"):
+			i = twigConstructEnd(content, i)
+		default:
+			i++
+		}
+	}
+	return len(content)
+}
+
+// fileRegion returns the whole content as a region with comments masked. Markup
+// masks Twig and HTML comments, SCSS and scripts mask /* */ and // comments.
+func fileRegion(content, ruleSet string) contentRegion {
+	text := []byte(content)
+	markup := ruleSet == RuleSetMarkup
+	for i := 0; i < len(content); {
+		next := i + 1
+		switch {
+		case markup && strings.HasPrefix(content[i:], "{#"):
+			next = skipPast(content, i+2, "#}")
+		case markup && strings.HasPrefix(content[i:], "<!--"):
+			next = skipPast(content, i+4, "-->")
+		case !markup && (content[i] == '\'' || content[i] == '"' || content[i] == '`'):
+			// Strings are kept, but may contain comment markers such as in URLs
+			i = stringLiteralEnd(content, i+1, content[i]) + 1
+			continue
+		case !markup && strings.HasPrefix(content[i:], "/*"):
+			next = skipPast(content, i+2, "*/")
+		case !markup && strings.HasPrefix(content[i:], "//"):
+			next = skipPast(content, i+2, "\n")
+		default:
+			i = next
+			continue
+		}
+		for k := i; k < next; k++ {
+			text[k] = maskSplit
+		}
+		i = next
+	}
+	return contentRegion{Start: 0, Text: string(text)}
+}
+
+// classValueRegions returns the class attribute value between start and end with
+// Twig constructs and comments masked. With interpolation enabled, string
+// literals inside {{ }} are returned as regions of their own.
+func classValueRegions(content string, start, end int, interpolation bool) []contentRegion {
+	text := []byte(content[start:end])
+	var nested []contentRegion
+
+	mask := func(from, to int, placeholder byte) {
+		for k := from; k < to && k < end; k++ {
+			text[k-start] = placeholder
+		}
+	}
+
+	for i := start; i < end; {
+		switch {
+		case strings.HasPrefix(content[i:], "{#"):
+			next := skipPast(content, i+2, "#}")
+			mask(i, next, maskSplit)
+			i = next
+		case strings.HasPrefix(content[i:], "<!--"):
+			next := skipPast(content, i+4, "-->")
+			mask(i, next, maskSplit)
+			i = next
+		case strings.HasPrefix(content[i:], "{%"):
+			next := twigConstructEnd(content, i)
+			mask(i, next, maskSplit)
+			i = next
+		case strings.HasPrefix(content[i:], "{{"):
+			next := twigConstructEnd(content, i)
+			mask(i, next, maskJoin)
+			if interpolation {
+				for _, literal := range twigStringLiterals(content, i+2, next) {
+					nested = append(nested, contentRegion{Start: literal[0], Text: content[literal[0]:literal[1]]})
+				}
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+
+	return append([]contentRegion{{Start: start, Text: string(text)}}, nested...)
+}
+
+// twigClassLiterals returns the string literals of a Twig tag that hold classes:
+// every literal of {% set <name>class... = ... %} and the values of hash keys
+// like class: '...' or additionalClass: '...'
+func twigClassLiterals(content string, start, end int) []contentRegion {
+	var regions []contentRegion
+	literals := twigStringLiterals(content, start+2, end)
+
+	if strings.HasPrefix(content[start:], "{%") {
+		fields := strings.Fields(strings.TrimLeft(content[start+2:end], "-~ \t\r\n"))
+		if len(fields) > 1 && fields[0] == "set" {
+			variable := strings.ToLower(strings.SplitN(fields[1], "=", 2)[0])
+			if strings.Contains(variable, "class") {
+				for _, literal := range literals {
+					regions = append(regions, contentRegion{Start: literal[0], Text: content[literal[0]:literal[1]]})
+				}
+				return regions
+			}
+		}
+	}
+
+	for _, key := range twigClassKeyRegex.FindAllStringIndex(content[start:end], -1) {
+		valueStart := start + key[1]
+		for _, literal := range literals {
+			if literal[0] == valueStart+1 {
+				regions = append(regions, contentRegion{Start: literal[0], Text: content[literal[0]:literal[1]]})
+			}
+		}
+	}
+
+	return regions
+}
+
+// classCallRegions returns the string arguments of addClass-style calls
+// (jQuery addClass/removeClass/toggleClass/hasClass and classList methods)
+func classCallRegions(content string) []contentRegion {
+	var regions []contentRegion
+
+	for _, call := range classCallRegex.FindAllStringIndex(content, -1) {
+		i := call[1]
+		for {
+			for i < len(content) && isSpace(content[i]) {
+				i++
+			}
+			if i >= len(content) || (content[i] != '\'' && content[i] != '"' && content[i] != '`') {
+				break
+			}
+			literalEnd := stringLiteralEnd(content, i+1, content[i])
+			regions = append(regions, contentRegion{Start: i + 1, Text: content[i+1 : literalEnd]})
+			i = literalEnd + 1
+			for i < len(content) && isSpace(content[i]) {
+				i++
+			}
+			if i >= len(content) || content[i] != ',' {
+				break
+			}
+			i++
+		}
+	}
+
+	return regions
+}
+
+// twigStringLiterals returns the content ranges of all quoted strings between start and end
+func twigStringLiterals(content string, start, end int) [][2]int {
+	var literals [][2]int
+	for i := start; i < end; i++ {
+		if content[i] != '\'' && content[i] != '"' {
+			continue
+		}
+		literalEnd := stringLiteralEnd(content, i+1, content[i])
+		if literalEnd > end {
+			break
+		}
+		literals = append(literals, [2]int{i + 1, literalEnd})
+		i = literalEnd
+	}
+	return literals
+}
+
+// stringLiteralEnd returns the offset of the quote closing a string literal
+func stringLiteralEnd(content string, start int, quote byte) int {
+	for i := start; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(content)
+}
+
+// twigConstructEnd returns the offset after the {{ }} or {% %} construct at start,
+// ignoring closing delimiters inside string literals
+func twigConstructEnd(content string, start int) int {
+	closing := "}}"
+	if strings.HasPrefix(content[start:], "{%") {
+		closing = "%}"
+	}
+
+	for i := start + 2; i < len(content); i++ {
+		switch {
+		case content[i] == '\'' || content[i] == '"':
+			i = stringLiteralEnd(content, i+1, content[i])
+		case strings.HasPrefix(content[i:], closing):
+			return i + 2
+		}
+	}
+	return len(content)
+}
+
+// skipPast returns the offset after the next occurrence of marker, or the end of content
+func skipPast(content string, start int, marker string) int {
+	if start > len(content) {
+		return len(content)
+	}
+	index := strings.Index(content[start:], marker)
+	if index < 0 {
+		return len(content)
+	}
+	return start + index + len(marker)
+}
+
+// isTwigStart reports whether a Twig construct starts at offset i
+func isTwigStart(content string, i int) bool {
+	return strings.HasPrefix(content[i:], "{{") || strings.HasPrefix(content[i:], "{%") || strings.HasPrefix(content[i:], "{#")
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isTagNameChar(c byte) bool {
+	return isASCIILetter(c) || (c >= '0' && c <= '9') || c == '-' || c == ':'
+}
+
+// lineAt returns the 1-based line number of the given byte offset
+func lineAt(content string, offset int) int {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(content) {
+		offset = len(content)
+	}
+	return strings.Count(content[:offset], "\n") + 1
+}
@@ -0,0 +1,400 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+func TestContentScopes(t *testing.T) {
+	migrations := getBootstrapMigrations()
+
+	testCases := []struct {
+		name          string
+		input         string
+		expected      string
+		interpolation bool
+	}{
+		{
+			name:     "Single quoted class attribute",
+			input:    `<div class='text-left ml-2'>`,
+			expected: `<div class='text-start ms-2'>`,
+		},
+		{
+			name:     "Prose is left alone",
+			input:    `<p>Use text-left for ml-2 spacing</p>`,
+			expected: `<p>Use text-left for ml-2 spacing</p>`,
+		},
+		{
+			name:     "Inline style is left alone",
+			input:    `<div style="border-left: 1px solid" class="border-left">`,
+			expected: `<div style="border-left: 1px solid" class="border-start">`,
+		},
+		{
+			name:     "JavaScript variable is left alone",
+			input:    "<script>var spacing = 'ml-2'; $(el).addClass('ml-2 text-right');</script>",
+			expected: "<script>var spacing = 'ml-2'; $(el).addClass('ms-2 text-end');</script>",
+		},
+		{
+			name:     "classList calls",
+			input:    `<button onclick="this.classList.add('float-left', 'btn-block')">`,
+			expected: `<button onclick="this.classList.add('float-start', 'd-grid')">`,
+		},
+		{
+			name:     "Twig set with class variable",
+			input:    `{% set classes = 'form-group ' ~ (inline ? 'float-left') %}{% set label = 'form-group' %}`,
+			expected: `{% set classes = 'mb-3 ' ~ (inline ? 'float-start') %}{% set label = 'form-group' %}`,
+		},
+		{
+			name:     "Twig hash class key",
+			input:    `{% sw_include '@Storefront/x.html.twig' with { additionalClass: 'text-right', title: 'text-right' } %}`,
+			expected: `{% sw_include '@Storefront/x.html.twig' with { additionalClass: 'text-end', title: 'text-right' } %}`,
+		},
+		{
+			name:     "Twig translation key is left alone",
+			input:    `<label>{{ 'form-group.label'|trans }}</label>`,
+			expected: `<label>{{ 'form-group.label'|trans }}</label>`,
+		},
+		{
+			name:     "Token boundaries",
+			input:    `<button class="close close-icon btn-close">`,
+			expected: `<button class="btn-close close-icon btn-close">`,
+		},
+		{
+			name:     "Dynamic class suffix is left alone",
+			input:    `<div class="text-left{{ suffix }} ml-{{ size }} mr-2">`,
+			expected: `<div class="text-left{{ suffix }} ml-{{ size }} me-2">`,
+		},
+		{
+			name:     "No match across Twig tags",
+			input:    `<div class="custom-control {% if checkbox %}custom-checkbox{% endif %}">`,
+			expected: `<div class="custom-control {% if checkbox %}custom-checkbox{% endif %}">`,
+		},
+		{
+			name:     "Twig tags separate tokens",
+			input:    `<div class="{% if right %}text-right{% else %}text-left{% endif %}">`,
+			expected: `<div class="{% if right %}text-end{% else %}text-start{% endif %}">`,
+		},
+		{
+			name:     "Interpolation is left alone by default",
+			input:    `<div class="{{ active ? 'text-left' : 'text-right' }} ml-1">`,
+			expected: `<div class="{{ active ? 'text-left' : 'text-right' }} ms-1">`,
+		},
+		{
+			name:          "Interpolation mode",
+			input:         `<div class="{{ active ? 'text-left' : 'text-right' }} ml-1">`,
+			expected:      `<div class="{{ active ? 'text-start' : 'text-end' }} ms-1">`,
+			interpolation: true,
+		},
+		{
+			name:     "Data attributes only as attribute names",
+			input:    `<button data-toggle="modal" data-slide-to="1" title="data-toggle">`,
+			expected: `<button data-bs-toggle="modal" data-bs-slide-to="1" title="data-toggle">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, _ := Content(tc.input, migrations, Options{TwigInterpolation: tc.interpolation})
+			if result != tc.expected {
+				t.Errorf("Migration failed:\nInput:    %s\nExpected: %s\nActual:   %s", tc.input, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestContentProtectedRegions(t *testing.T) {
+	migrations := getBootstrapMigrations()
+
+	testCases := []struct {
+		name           string
+		file           string
+		input          string
+		expected       string
+		processScripts bool
+	}{
+		{
+			name:     "Verbatim block",
+			input:    "{% verbatim %}<div class=\"ml-2\">&times;</div>{% endverbatim %}<p class=\"ml-2\">",
+			expected: "{% verbatim %}<div class=\"ml-2\">&times;</div>{% endverbatim %}<p class=\"ms-2\">",
+		},
+		{
+			name:     "Code sample",
+			input:    "<pre class=\"pl-3\"><code><div class=\"media\"><button class=\"close\" data-dismiss=\"modal\">&times;</button></div></code></pre>",
+			expected: "<pre class=\"ps-3\"><code><div class=\"media\"><button class=\"close\" data-dismiss=\"modal\">&times;</button></div></code></pre>",
+		},
+		{
+			name:     "JSON script",
+			input:    `<script type="application/json">{"class": "ml-2", "init": "$('.modal').modal();"}</script>`,
+			expected: `<script type="application/json">{"class": "ml-2", "init": "$('.modal').modal();"}</script>`,
+		},
+		{
+			name:     "Inline script without --process-scripts",
+			input:    "<script>$('.tooltip').tooltip(); el.setAttribute('data-toggle', 'x');</script>",
+			expected: "<script>$('.tooltip').tooltip(); el.setAttribute('data-toggle', 'x');</script>",
+		},
+		{
+			name:           "Inline script with --process-scripts",
+			input:          "<script>$(el).tooltip(); el.setAttribute('data-toggle', 'x'); $(el).addClass('ml-1');</script>",
+			expected:       "<script>$(el).tooltip(); /* TODO: jQuery plugins were removed, use bootstrap.<Component>.getOrCreateInstance(): https://getbootstrap.com/docs/5.2/getting-started/javascript/#usage */ el.setAttribute('data-bs-toggle', 'x'); $(el).addClass('ms-1');</script>",
+			processScripts: true,
+		},
+		{
+			name:     "Migrate-off markers",
+			input:    "<p class=\"ml-2\">{# wswcli-migrate-off #}<p class=\"ml-2\" data-toggle=\"x\">{# wswcli-migrate-on #}<p class=\"ml-2\">",
+			expected: "<p class=\"ms-2\">{# wswcli-migrate-off #}<p class=\"ml-2\" data-toggle=\"x\">{# wswcli-migrate-on #}<p class=\"ms-2\">",
+		},
+		{
+			name:     "Migrate-off without migrate-on",
+			file:     "page.html",
+			input:    "<p class=\"ml-2\"><!-- wswcli-migrate-off --><p class=\"ml-2\">",
+			expected: "<p class=\"ms-2\"><!-- wswcli-migrate-off --><p class=\"ml-2\">",
+		},
+		{
+			name:     "Migrate-off markers in SCSS",
+			file:     "theme.scss",
+			input:    "/* wswcli-migrate-off */\n$custom-select-bg: $white;\n/* wswcli-migrate-on */\n$custom-select-bg: $white;\n",
+			expected: "/* wswcli-migrate-off */\n$custom-select-bg: $white;\n/* wswcli-migrate-on */\n$form-select-bg: $white;\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := Options{ProcessScripts: tc.processScripts}
+			if tc.file != "" {
+				options.FileType, _ = FileTypeFor(tc.file)
+			}
+			result, _ := Content(tc.input, migrations, options)
+			if result != tc.expected {
+				t.Errorf("Migration failed:\nInput:    %s\nExpected: %s\nActual:   %s", tc.input, tc.expected, result)
+			}
+			if again, _ := Content(result, migrations, options); again != result {
+				t.Errorf("Second run changed the content:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestContentMatches(t *testing.T) {
+	content := `<div class="ml-2">ml-2</div>`
+	result, matches := Content(content, getBootstrapMigrations(), Options{})
+
+	if result != `<div class="ms-2">ml-2</div>` {
+		t.Errorf("Unexpected result: %s", result)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].Rule != "Margin Left" || matches[0].Start != 12 || matches[0].Original != "ml-2" || matches[0].Replacement != "ms-2" {
+		t.Errorf("Unexpected match: %+v", matches[0])
+	}
+}
+
+func TestMigrationIdempotent(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "bs4to5", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("No fixtures found in testdata/bs4to5")
+	}
+
+	migrations := getBootstrapMigrations()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fileType, ok := FileTypeFor(file)
+		if !ok {
+			t.Fatalf("Unsupported fixture: %s", file)
+		}
+		for _, interpolation := range []bool{false, true} {
+			options := Options{
+				TwigInterpolation: interpolation,
+				FileType:          fileType,
+			}
+			once, _ := Content(string(content), migrations, options)
+			twice, matches := Content(once, migrations, options)
+
+			if twice != once {
+				t.Errorf("%s (interpolation %v): second run changed the content:\n%s", file, interpolation, unidiff.Unified("once", "twice", once, twice, 1))
+			}
+			if len(matches) > 0 {
+				t.Errorf("%s (interpolation %v): second run found %d matches: %+v", file, interpolation, len(matches), matches)
+			}
+		}
+	}
+}
+
+func TestMigrationCollapsesDuplicateTodos(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "bs4to5", "migrated-twice.html.twig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, _ := Content(string(content), getBootstrapMigrations(), Options{})
+
+	for _, todo := range []string{
+		"{# TODO: Remove manual separators",
+		"{# TODO: Use SVG icon instead",
+		"{# TODO: Replace &times; with SVG icon",
+	} {
+		if count := strings.Count(migrated, todo); count != 1 {
+			t.Errorf("Expected one %q comment, got %d:\n%s", todo, count, migrated)
+		}
+	}
+	if !strings.Contains(migrated, `<li class="breadcrumb-item {# TODO: Remove manual separators - Now via CSS: https://getbootstrap.com/docs/5.2/components/breadcrumb/#changing-the-separator #}">`) {
+		t.Errorf("Expected the first TODO comment to be kept:\n%s", migrated)
+	}
+}
+
+func TestContentFileTypes(t *testing.T) {
+	tests := []struct {
+		file           string
+		expected       []string
+		kept           []string
+		absent         []string
+		processScripts bool
+	}{
+		{
+			file: "theme.scss",
+			expected: []string{
+				"$form-select-bg: $white;",
+				"$form-range-thumb-bg: $primary;",
+				"$custom-control-indicator-size: 1.25rem; /* TODO: Custom control variables",
+				"color: color-contrast($primary);",
+				"@include media-breakpoint-down(md) /* TODO: media-breakpoint-down() now excludes",
+				"@include media-breakpoint-down(xl) /* TODO",
+				"color: $color-contrast-dark;",
+			},
+			kept: []string{
+				"// Theme overrides, media-breakpoint-down(md) in comments is left alone",
+				`url("https://example.com/img.png")`,
+			},
+		},
+		{
+			file: "plugin.js",
+			expected: []string{
+				`querySelectorAll('[data-bs-toggle="offcanvas"], [data-bs-dismiss]')`,
+				"getAttribute('data-bs-target')",
+				"$(this.el).tooltip({ placement: 'left' }); /* TODO: jQuery plugins were removed",
+				"$(this.el).data('bs-toggle');",
+			},
+			kept: []string{
+				`* Opens the offcanvas via [data-toggle="offcanvas"] triggers`,
+				"'https://example.com/data-toggle'; // not a selector",
+				"window.alert('done');",
+			},
+		},
+		{
+			file: "plugin.ts",
+			expected: []string{
+				`getAttribute("data-bs-target")`,
+				"`[data-bs-ride=carousel]`",
+				"$(carousel).carousel('cycle'); /* TODO",
+			},
+		},
+		{
+			// Markup rules do not apply to scripts and vice versa
+			file:     "page.html",
+			expected: []string{"<!-- TODO: Remove manual separators", "$('#menu').addClass('float-start ms-1');"},
+			absent:   []string{"/* TODO"},
+		},
+		{
+			// With --process-scripts inline scripts get the script rules and their comment syntax
+			file:           "page.html",
+			expected:       []string{"$('.tooltip').tooltip(); /* TODO: jQuery plugins were removed", "$('#menu').addClass('float-start ms-1');"},
+			absent:         []string{"<!-- TODO: jQuery"},
+			processScripts: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			file := filepath.Join("testdata", "bs4to5", tt.file)
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fileType, ok := FileTypeFor(file)
+			if !ok {
+				t.Fatalf("No file type for %s", file)
+			}
+
+			migrated, _ := Content(string(content), getBootstrapMigrations(), Options{FileType: fileType, ProcessScripts: tt.processScripts})
+			for _, expected := range append(tt.expected, tt.kept...) {
+				if !strings.Contains(migrated, expected) {
+					t.Errorf("Expected %q in migrated content:\n%s", expected, migrated)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(migrated, absent) {
+					t.Errorf("Unexpected %q in migrated content:\n%s", absent, migrated)
+				}
+			}
+		})
+	}
+}
+
+func TestContentHTMLTodoAfterTag(t *testing.T) {
+	html, _ := FileTypeFor("page.html")
+	marginRule := Rule{Name: "Margin Auto", Pattern: regexp.MustCompile(`ml-auto`), Replacement: "ms-auto", Todo: "Check the direction"}
+	var mediaRule Rule
+	for _, migration := range getBootstrapMigrations() {
+		if migration.Name == "Media Object" {
+			mediaRule = migration
+		}
+	}
+	migrations := []Rule{marginRule, mediaRule}
+
+	content := "<div class=\"media ml-auto\" id=\"box\">\n  <span class=\"ml-auto\">x</span>\n</div>\n"
+	migrated, matches := Content(content, migrations, Options{FileType: html})
+
+	// The class attributes only hold class tokens, the TODOs follow the open tags
+	elements := parseElements(migrated)
+	if len(elements) != 2 {
+		t.Fatalf("Expected 2 elements in:\n%s", migrated)
+	}
+	for i, expected := range [][]string{{"media", "ms-auto"}, {"ms-auto"}} {
+		element := elements[i]
+		if classes := strings.Fields(migrated[element.ClassStart:element.ClassEnd]); !reflect.DeepEqual(classes, expected) {
+			t.Errorf("Expected classes %v, got %v in:\n%s", expected, classes, migrated)
+		}
+		if !strings.HasPrefix(migrated[element.OpenEnd:], "<!-- TODO: ") {
+			t.Errorf("Expected a TODO after the open tag of %s in:\n%s", element.Name, migrated)
+		}
+	}
+	if !strings.Contains(migrated, "<!-- TODO: Check the direction -->") || strings.Contains(migrated, "<!--  ") {
+		t.Errorf("Expected TODO comments without extra padding in:\n%s", migrated)
+	}
+
+	todos := 0
+	for _, match := range matches {
+		if match.Todo != "" {
+			todos++
+			if !strings.HasPrefix(migrated[match.Position:], match.Todo) {
+				t.Errorf("Expected the TODO of %s at its position in:\n%s", match.Rule, migrated)
+			}
+		}
+	}
+	if todos != 3 {
+		t.Errorf("Expected 3 TODO comments, got %d", todos)
+	}
+
+	// A second run changes nothing
+	if again, matches := Content(migrated, migrations, Options{FileType: html}); again != migrated || len(matches) != 0 {
+		t.Errorf("Expected no changes on the second run, got %d:\n%s", len(matches), again)
+	}
+
+	// A rejected rewrite leaves no TODO behind
+	reject := func(content string, match Match) (string, bool) { return match.Replacement, false }
+	if rejected, _ := Content(content, []Rule{marginRule}, Options{FileType: html, Decide: reject}); rejected != content {
+		t.Errorf("Expected no changes when all rewrites are rejected, got:\n%s", rejected)
+	}
+}
@@ -0,0 +1,342 @@
+package migrate
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Report summarizes a migration run per file and per rule
+type Report struct {
+	Pack    string        `json:"pack"`
+	Root    string        `json:"root"`
+	DryRun  bool          `json:"dry_run"`
+	Check   bool          `json:"check,omitempty"` // Written by --check, files list their findings
+	Summary ReportSummary `json:"summary"`
+	Rules   []RuleReport  `json:"rules"`
+	Files   []FileReport  `json:"files"`
+
+	migrations []Rule
+}
+
+// ReportSummary holds the totals of a run
+type ReportSummary struct {
+	Files        int `json:"files"`
+	ChangedFiles int `json:"changed_files"`
+	FailedFiles  int `json:"failed_files"`
+	Changes      int `json:"changes"`            // Automatic changes, without TODO comments
+	Todos        int `json:"todos"`              // TODO comments inserted
+	Findings     int `json:"findings,omitempty"` // Occurrences found by --check
+}
+
+// RuleReport holds the counts of a single rule
+type RuleReport struct {
+	Rule     string `json:"rule"`
+	Category string `json:"category"`
+	Changes  int    `json:"changes"`
+	Todos    int    `json:"todos"`
+	Files    int    `json:"files"`
+}
+
+// FileReport holds the counts and TODO locations of a single file
+type FileReport struct {
+	File     string         `json:"file"`
+	Changes  int            `json:"changes"`
+	Todos    int            `json:"todos"`
+	Rules    []RuleCount    `json:"rules,omitempty"`
+	TodoAt   []TodoLocation `json:"todo_locations,omitempty"`
+	Findings []Finding      `json:"findings,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// RuleCount holds the counts of a rule within a file
+type RuleCount struct {
+	Rule    string `json:"rule"`
+	Changes int    `json:"changes"`
+	Todos   int    `json:"todos"`
+}
+
+// TodoLocation is the position of an inserted TODO comment in the migrated file
+type TodoLocation struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Rule   string `json:"rule"`
+	Note   string `json:"note"`
+}
+
+// NewReport starts a report for a run of the migrations of pack over root
+func NewReport(root, pack string, dryRun bool, migrations []Rule) *Report {
+	return &Report{Pack: pack, Root: root, DryRun: dryRun, migrations: migrations}
+}
+
+// AddFile records the result of migrating a file
+func (r *Report) AddFile(result Result) {
+	entry := FileReport{File: reportPath(r.Root, result.File)}
+	counts := make(map[string]*RuleCount)
+	for _, match := range result.Matches {
+		count, ok := counts[match.Rule]
+		if !ok {
+			count = &RuleCount{Rule: match.Rule}
+			counts[match.Rule] = count
+		}
+		if match.Todo == "" {
+			entry.Changes++
+			count.Changes++
+			continue
+		}
+		entry.Todos++
+		count.Todos++
+
+		offset := match.Position + strings.Index(match.Replacement, match.Todo)
+		entry.TodoAt = append(entry.TodoAt, TodoLocation{
+			Line:   lineAt(result.Migrated, offset),
+			Column: offset - strings.LastIndex(result.Migrated[:offset], "\n"),
+			Rule:   match.Rule,
+		})
+	}
+	sort.SliceStable(entry.TodoAt, func(i, j int) bool {
+		a, b := entry.TodoAt[i], entry.TodoAt[j]
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	// Rules are listed in the order they are applied
+	for _, migration := range r.migrations {
+		count, ok := counts[migration.Name]
+		if !ok {
+			continue
+		}
+		entry.Rules = append(entry.Rules, *count)
+		for i := range entry.TodoAt {
+			if entry.TodoAt[i].Rule == migration.Name {
+				entry.TodoAt[i].Note = migration.Todo
+			}
+		}
+
+		rule := r.ruleFor(migration)
+		rule.Changes += count.Changes
+		rule.Todos += count.Todos
+		rule.Files++
+	}
+
+	r.Summary.Files++
+	r.Summary.Changes += entry.Changes
+	r.Summary.Todos += entry.Todos
+	if len(result.Matches) > 0 {
+		r.Summary.ChangedFiles++
+	}
+	r.Files = append(r.Files, entry)
+}
+
+// AddCheckedFile records the result of checking a file with its findings
+func (r *Report) AddCheckedFile(result Result, findings []Finding) {
+	r.AddFile(result)
+	r.Files[len(r.Files)-1].Findings = findings
+	r.Summary.Findings += len(findings)
+}
+
+// AddError records a file that could not be migrated
+func (r *Report) AddError(file string, err error) {
+	r.Summary.Files++
+	r.Summary.FailedFiles++
+	r.Files = append(r.Files, FileReport{File: reportPath(r.Root, file), Error: err.Error()})
+}
+
+// RuleCategory returns the category of the named rule
+func (r *Report) RuleCategory(rule string) string {
+	if index := ruleIndex(r.migrations, rule); index < len(r.migrations) {
+		return r.migrations[index].Category
+	}
+	return ""
+}
+
+// ruleFor returns the report of a rule, keeping the rules in the order they are applied
+func (r *Report) ruleFor(migration Rule) *RuleReport {
+	index := len(r.Rules)
+	for i := range r.Rules {
+		if r.Rules[i].Rule == migration.Name {
+			return &r.Rules[i]
+		}
+		if index == len(r.Rules) && ruleIndex(r.migrations, r.Rules[i].Rule) > ruleIndex(r.migrations, migration.Name) {
+			index = i
+		}
+	}
+	r.Rules = append(r.Rules, RuleReport{})
+	copy(r.Rules[index+1:], r.Rules[index:])
+	r.Rules[index] = RuleReport{Rule: migration.Name, Category: migration.Category}
+	return &r.Rules[index]
+}
+
+// ruleIndex returns the position of rule in migrations
+func ruleIndex(migrations []Rule, rule string) int {
+	for i, migration := range migrations {
+		if migration.Name == rule {
+			return i
+		}
+	}
+	return len(migrations)
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	if r.Rules == nil {
+		r.Rules = []RuleReport{}
+	}
+	if r.Files == nil {
+		r.Files = []FileReport{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// reportHTML renders the report as a standalone HTML page
+var reportHTML = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Migration Report: {{.Pack}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #212529; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #dee2e6; padding: .3em .6em; text-align: left; vertical-align: top; }
+th { background: #f8f9fa; }
+td.count { text-align: right; }
+.error { color: #dc3545; }
+.todo { color: #fd7e14; }
+</style>
+</head>
+<body>
+<h1>Migration Report: {{.Pack}}</h1>
+<p>{{.Root}}{{if .Check}} (check){{else if .DryRun}} (dry run){{end}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Files</th><td class="count">{{.Summary.Files}}</td></tr>
+<tr><th>Changed files</th><td class="count">{{.Summary.ChangedFiles}}</td></tr>
+<tr><th>Failed files</th><td class="count">{{.Summary.FailedFiles}}</td></tr>
+<tr><th>Automatic changes</th><td class="count">{{.Summary.Changes}}</td></tr>
+<tr><th>TODO comments</th><td class="count">{{.Summary.Todos}}</td></tr>
+{{- if .Check}}
+<tr><th>Findings</th><td class="count">{{.Summary.Findings}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Rules</h2>
+<table>
+<tr><th>Rule</th><th>Category</th><th>Changes</th><th>TODOs</th><th>Files</th></tr>
+{{- range .Rules}}
+<tr><td>{{.Rule}}</td><td>{{.Category}}</td><td class="count">{{.Changes}}</td><td class="count">{{.Todos}}</td><td class="count">{{.Files}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Changes</th><th>TODOs</th><th>Details</th></tr>
+{{- range .Files}}
+{{- if or .Error .Changes .Todos}}
+<tr><td>{{.File}}</td><td class="count">{{.Changes}}</td><td class="count">{{.Todos}}</td><td>
+{{- if .Error}}<span class="error">{{.Error}}</span>{{end}}
+{{- range .TodoAt}}<div class="todo">{{.Line}}:{{.Column}} {{.Rule}}: {{.Note}}</div>{{end}}
+{{- range .Findings}}<div>{{.Line}}:{{.Column}} {{.Rule}}: {{.Message}} <code>{{.Match}}</code></div>{{end}}
+</td></tr>
+{{- end}}
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportHTML.Execute(w, r)
+}
+
+// junitTestSuite is the JUnit XML document of a report
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a single file of a JUnit report
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+// junitMessage is a failure or error of a JUnit test case
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one test case per file.
+// Files with TODO comments, or with findings in a check, fail, files that could
+// not be migrated are errors.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{Name: r.Pack, Tests: len(r.Files), Time: "0"}
+	for _, file := range r.Files {
+		testCase := junitTestCase{ClassName: r.Pack, Name: file.File, Time: "0"}
+		switch {
+		case file.Error != "":
+			suite.Errors++
+			testCase.Error = &junitMessage{Message: "File could not be migrated", Type: "MigrationError", Text: file.Error}
+		case r.Check && len(file.Findings) > 0:
+			suite.Failures++
+			var details []string
+			for _, finding := range file.Findings {
+				details = append(details, fmt.Sprintf("%s:%d:%d %s: %s [%s]", file.File, finding.Line, finding.Column, finding.Rule, finding.Message, finding.Match))
+			}
+			testCase.Failure = &junitMessage{
+				Message: fmt.Sprintf("%d occurrences need migration", len(file.Findings)),
+				Type:    "MigrationFinding",
+				Text:    strings.Join(details, "\n"),
+			}
+		case !r.Check && file.Todos > 0:
+			suite.Failures++
+			var details []string
+			for _, todo := range file.TodoAt {
+				details = append(details, fmt.Sprintf("%s:%d:%d %s: %s", file.File, todo.Line, todo.Column, todo.Rule, todo.Note))
+			}
+			testCase.Failure = &junitMessage{
+				Message: fmt.Sprintf("%d TODO comments need manual migration", file.Todos),
+				Type:    "MigrationTodo",
+				Text:    strings.Join(details, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// reportPath returns file relative to root with forward slashes
+func reportPath(root, file string) string {
+	relPath, err := filepath.Rel(root, file)
+	if err != nil {
+		relPath = file
+	}
+	return filepath.ToSlash(relPath)
+}
@@ -0,0 +1,54 @@
+package migrate
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReportWriters(t *testing.T) {
+	report := NewReport(".", BootstrapPackName, true, getBootstrapMigrations())
+	report.AddFile(Result{
+		File:     "templates/a.html.twig",
+		Original: `<div class="media">`,
+		Migrated: `<div class="media {# TODO: Media object removed #}">`,
+		Matches:  []Match{{Rule: "Media Object", Start: 12, End: 17, Original: "media", Replacement: "media {# TODO: Media object removed #}", Todo: "{# TODO: Media object removed #}", Position: 12}},
+	})
+	report.AddError("templates/<b>.html", os.ErrPermission)
+
+	expected := ReportSummary{Files: 2, ChangedFiles: 1, FailedFiles: 1, Todos: 1}
+	if report.Summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, report.Summary)
+	}
+	if category := report.RuleCategory("Media Object"); category == "" {
+		t.Error("Expected a category for the Media Object rule")
+	}
+
+	var html strings.Builder
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"<td>Media Object</td>", "1:19 Media Object", "templates/&lt;b&gt;.html", "(dry run)"} {
+		if !strings.Contains(html.String(), expected) {
+			t.Errorf("Expected %q in HTML report:\n%s", expected, html.String())
+		}
+	}
+
+	var junit strings.Builder
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`tests="2" failures="1" errors="1"`, `name="templates/a.html.twig"`, "templates/a.html.twig:1:19 Media Object", `name="templates/&lt;b&gt;.html"`} {
+		if !strings.Contains(junit.String(), expected) {
+			t.Errorf("Expected %q in JUnit report:\n%s", expected, junit.String())
+		}
+	}
+
+	var json strings.Builder
+	if err := NewReport(".", BootstrapPackName, false, nil).WriteJSON(&json); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(json.String(), `"rules": []`) || !strings.Contains(json.String(), `"files": []`) {
+		t.Errorf("Expected empty lists in JSON report:\n%s", json.String())
+	}
+}
@@ -0,0 +1,254 @@
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltinRuleSource is the source of the rules shipped with wswcli
+const BuiltinRuleSource = "built-in"
+
+// RuleFile is the content of a YAML or JSON rule file
+type RuleFile struct {
+	Rules []RuleSpec `yaml:"rules" json:"rules"`
+}
+
+// RuleSpec describes a rule in a rule file. A rule with the name of an
+// existing rule overrides the fields it sets, a new rule needs a pattern or class.
+type RuleSpec struct {
+	Name        string `yaml:"name" json:"name"`
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Class       string `yaml:"class" json:"class"` // Literal class token, shorthand for a quoted pattern
+	Replacement string `yaml:"replacement" json:"replacement"`
+	Description string `yaml:"description" json:"description"`
+	Category    string `yaml:"category" json:"category"`
+	Scope       string `yaml:"scope" json:"scope"`
+	RuleSet     string `yaml:"rule_set" json:"rule_set"` // markup (default for new rules), scss or script
+	Todo        string `yaml:"todo" json:"todo"`
+	Disabled    bool   `yaml:"disabled" json:"disabled"`
+
+	Examples []Example `yaml:"examples" json:"examples"`
+}
+
+// Selection holds the rule files and filters for a migration run
+type Selection struct {
+	Files []string
+	Only  []string // Categories to keep, all when empty
+	Skip  []string // Rule names to drop
+	Pack  string   // Migration pack of the built-in rules, bootstrap-4-to-5 when empty
+	Check bool     // Also load the check rules of the pack
+}
+
+// EffectiveRules is the result of loading and filtering rules
+type EffectiveRules struct {
+	Rules    []Rule // Effective rules in order
+	Disabled []Rule // Rules disabled by a rule file
+	Skipped  []Rule // Rules removed by --only or --skip
+}
+
+// LoadRules merges the rule files over the built-in rules and applies
+// the category and name filters
+func LoadRules(selection Selection) (EffectiveRules, error) {
+	packName := selection.Pack
+	if packName == "" {
+		packName = BootstrapPackName
+	}
+	pack, err := FindPack(packName)
+	if err != nil {
+		return EffectiveRules{}, err
+	}
+
+	rules := pack.Rules()
+	if selection.Check && pack.Checks != nil {
+		rules = append(rules, pack.Checks()...)
+	}
+	for i := range rules {
+		rules[i].Source = BuiltinRuleSource
+	}
+	disabled := make(map[string]bool)
+
+	for _, file := range selection.Files {
+		specs, err := readRuleFile(file)
+		if err != nil {
+			return EffectiveRules{}, err
+		}
+		for _, spec := range specs {
+			rules, err = mergeRuleSpec(rules, spec, file)
+			if err != nil {
+				return EffectiveRules{}, inputError("invalid rule %q in %s: %v", spec.Name, file, err)
+			}
+			disabled[spec.Name] = spec.Disabled
+		}
+	}
+
+	categories := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		categories[rule.Category] = true
+		names[rule.Name] = true
+	}
+	for _, category := range selection.Only {
+		if !categories[category] {
+			return EffectiveRules{}, inputError("unknown rule category: %s", category)
+		}
+	}
+	for _, name := range selection.Skip {
+		if !names[name] {
+			return EffectiveRules{}, inputError("unknown rule: %s", name)
+		}
+	}
+
+	var ruleSet EffectiveRules
+	for _, rule := range rules {
+		switch {
+		case disabled[rule.Name]:
+			ruleSet.Disabled = append(ruleSet.Disabled, rule)
+		case len(selection.Only) > 0 && !containsString(selection.Only, rule.Category),
+			containsString(selection.Skip, rule.Name):
+			ruleSet.Skipped = append(ruleSet.Skipped, rule)
+		default:
+			ruleSet.Rules = append(ruleSet.Rules, rule)
+		}
+	}
+
+	return ruleSet, nil
+}
+
+// readRuleFile reads the rules of a YAML (.yaml, .yml) or JSON (.json) file
+func readRuleFile(file string) ([]RuleSpec, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rule file: %w", err)
+	}
+
+	var ruleFile RuleFile
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		err = json.Unmarshal(data, &ruleFile)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &ruleFile)
+	default:
+		return nil, inputError("unsupported rule file format: %s (use .yaml, .yml or .json)", file)
+	}
+	if err != nil {
+		return nil, inputError("error parsing rule file %s: %v", file, err)
+	}
+
+	return ruleFile.Rules, nil
+}
+
+// mergeRuleSpec overrides an existing rule with the fields set in spec or
+// appends it as a new rule
+func mergeRuleSpec(rules []Rule, spec RuleSpec, source string) ([]Rule, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if spec.Pattern != "" && spec.Class != "" {
+		return nil, fmt.Errorf("pattern and class are mutually exclusive")
+	}
+	switch spec.Scope {
+	case "", ScopeClass, ScopeAttribute, ScopeFile:
+	default:
+		return nil, fmt.Errorf("unknown scope %q (use class, attribute or file)", spec.Scope)
+	}
+	switch spec.RuleSet {
+	case "", RuleSetMarkup, RuleSetSCSS, RuleSetScript:
+	default:
+		return nil, fmt.Errorf("unknown rule set %q (use markup, scss or script)", spec.RuleSet)
+	}
+
+	var pattern *regexp.Regexp
+	switch {
+	case spec.Pattern != "":
+		compiled, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		pattern = compiled
+	case spec.Class != "":
+		pattern = regexp.MustCompile(regexp.QuoteMeta(spec.Class))
+	}
+
+	index := -1
+	for i, rule := range rules {
+		if rule.Name == spec.Name {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		if spec.Disabled {
+			return nil, fmt.Errorf("cannot disable a rule that does not exist")
+		}
+		if pattern == nil {
+			return nil, fmt.Errorf("pattern or class is required for new rules")
+		}
+		if spec.Replacement == "" && spec.Todo == "" {
+			return nil, fmt.Errorf("replacement or todo is required for new rules")
+		}
+		// Rules that only add a TODO keep the matched text
+		rules = append(rules, Rule{Name: spec.Name, Replacement: "$0", Category: "custom"})
+		index = len(rules) - 1
+	}
+
+	rule := &rules[index]
+	if rule.Transform != nil && (pattern != nil || spec.Replacement != "" || spec.Scope != "" || spec.Todo != "" || spec.RuleSet != "") {
+		return nil, fmt.Errorf("structural rules only support disabled, description and category")
+	}
+	if pattern != nil {
+		rule.Pattern = pattern
+	}
+	if spec.Replacement != "" {
+		rule.Replacement = spec.Replacement
+	}
+	if spec.Description != "" {
+		rule.Description = spec.Description
+	}
+	if spec.Category != "" {
+		rule.Category = spec.Category
+	}
+	if spec.Scope != "" {
+		rule.Scope = spec.Scope
+	}
+	if spec.Todo != "" {
+		rule.Todo = spec.Todo
+	}
+	if spec.RuleSet != "" {
+		rule.RuleSet = spec.RuleSet
+	}
+	if RuleSetOf(*rule) != RuleSetMarkup && rule.Scope != "" && rule.Scope != ScopeFile {
+		return nil, fmt.Errorf("scope %q is only supported for markup rules, %s rules apply to the whole file", rule.Scope, rule.RuleSet)
+	}
+	if rule.Pattern != nil {
+		if err := checkCaptureGroups(rule.Pattern, rule.Replacement); err != nil {
+			return nil, err
+		}
+	}
+	// Examples of a built-in rule no longer apply once its behavior is overridden
+	switch {
+	case len(spec.Examples) > 0:
+		rule.Examples = spec.Examples
+	case pattern != nil || spec.Replacement != "" || spec.Scope != "" || spec.Todo != "" || spec.RuleSet != "":
+		rule.Examples = nil
+	}
+	rule.Source = source
+
+	return rules, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
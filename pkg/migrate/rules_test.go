@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	tempDir := t.TempDir()
+	writeProjectFiles(t, tempDir, map[string]string{
+		"team.yaml": `rules:
+  - name: Margin Left
+    description: Team override
+  - name: Card Deck
+    disabled: true
+  - name: Legacy Panel
+    class: panel-default
+    replacement: card
+    category: components
+  - name: Hidden Utility
+    pattern: 'hidden-(xs|sm)'
+    todo: Use d-none with breakpoints
+`,
+		"extra.json": `{"rules": [{"name": "Legacy Panel", "replacement": "card border"}]}`,
+	})
+
+	ruleSet, err := LoadRules(Selection{
+		Files: []string{filepath.Join(tempDir, "team.yaml"), filepath.Join(tempDir, "extra.json")},
+	})
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	rules := make(map[string]Rule)
+	for _, rule := range ruleSet.Rules {
+		rules[rule.Name] = rule
+	}
+
+	marginLeft := rules["Margin Left"]
+	if marginLeft.Description != "Team override" || marginLeft.Replacement != "ms-$1" || marginLeft.Category != "spacing" {
+		t.Errorf("Override should only change the description: %+v", marginLeft)
+	}
+	if !strings.HasSuffix(marginLeft.Source, "team.yaml") {
+		t.Errorf("Expected source team.yaml, got %s", marginLeft.Source)
+	}
+	if rules["Margin Right"].Source != BuiltinRuleSource {
+		t.Errorf("Expected built-in source, got %s", rules["Margin Right"].Source)
+	}
+
+	if _, ok := rules["Card Deck"]; ok || len(ruleSet.Disabled) != 1 || ruleSet.Disabled[0].Name != "Card Deck" {
+		t.Errorf("Card Deck should be disabled, disabled rules: %+v", ruleSet.Disabled)
+	}
+
+	panel, ok := rules["Legacy Panel"]
+	if !ok || panel.Replacement != "card border" || panel.Category != "components" || !strings.HasSuffix(panel.Source, "extra.json") {
+		t.Errorf("Unexpected merged rule: %+v", panel)
+	}
+
+	content, matches := Content(`<div class="panel-default hidden-xs">`, ruleSet.Rules, Options{})
+	if content != `<div class="card border hidden-xs {# TODO: Use d-none with breakpoints #}">` || len(matches) != 2 {
+		t.Errorf("Unexpected migration with custom rules: %s (%d matches)", content, len(matches))
+	}
+}
+
+func TestLoadRulesSelection(t *testing.T) {
+	ruleSet, err := LoadRules(Selection{Only: []string{"badges"}, Skip: []string{"Badge Pill"}})
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	for _, rule := range ruleSet.Rules {
+		if rule.Category != "badges" || rule.Name == "Badge Pill" {
+			t.Errorf("Unexpected rule in selection: %s (%s)", rule.Name, rule.Category)
+		}
+	}
+	if len(ruleSet.Rules) != 8 {
+		t.Errorf("Expected 8 badge rules, got %d", len(ruleSet.Rules))
+	}
+
+	if _, err := LoadRules(Selection{Only: []string{"typo"}}); !isInputError(err) {
+		t.Errorf("Expected input error for unknown category, got %v", err)
+	}
+	if _, err := LoadRules(Selection{Skip: []string{"Typo"}}); !isInputError(err) {
+		t.Errorf("Expected input error for unknown rule, got %v", err)
+	}
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	tempDir := t.TempDir()
+	writeProjectFiles(t, tempDir, map[string]string{
+		"pattern.yaml":   "rules:\n  - name: Broken\n    pattern: '('\n    replacement: x\n",
+		"missing.yaml":   "rules:\n  - name: New Rule\n    replacement: x\n",
+		"scope.yaml":     "rules:\n  - name: Margin Left\n    scope: everywhere\n",
+		"ruleset.yaml":   "rules:\n  - name: Margin Left\n    rule_set: less\n",
+		"scss.yaml":      "rules:\n  - name: Old Mixin\n    pattern: old-mixin\n    replacement: new-mixin\n    rule_set: scss\n    scope: class\n",
+		"structure.yaml": "rules:\n  - name: Media Object Structure\n    class: media\n",
+		"group.yaml":     "rules:\n  - name: Tooltip\n    pattern: 'bs-tooltip-[left|right]'\n    replacement: bs-tooltip-$1\n",
+		"rules.txt":      "rules: []\n",
+	})
+
+	for _, file := range []string{"pattern.yaml", "missing.yaml", "scope.yaml", "ruleset.yaml", "scss.yaml", "structure.yaml", "group.yaml", "rules.txt"} {
+		_, err := LoadRules(Selection{Files: []string{filepath.Join(tempDir, file)}})
+		if !isInputError(err) {
+			t.Errorf("%s: expected input error, got %v", file, err)
+		}
+	}
+
+	_, err := LoadRules(Selection{Files: []string{filepath.Join(tempDir, "missing-file.yaml")}})
+	if err == nil || isInputError(err) {
+		t.Errorf("Expected I/O error for a missing rule file, got %v", err)
+	}
+}
+
+// isInputError reports whether err is an InputError
+func isInputError(err error) bool {
+	var inputErr *InputError
+	return errors.As(err, &inputErr)
+}
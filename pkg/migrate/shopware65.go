@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"regexp"
+)
+
+// getShopware65Migrations returns the rules of the shopware-6.4-to-6.5 pack for
+// Storefront templates and JavaScript. Bootstrap 5 itself is migrated by the
+// bootstrap-4-to-5 pack.
+func getShopware65Migrations() []Rule {
+	return []Rule{
+		{
+			Name:        "CSRF Token Function",
+			Pattern:     regexp.MustCompile(`[ \t]*\{\{-?\s*sw_csrf\([^}]*\)\s*-?\}\}[ \t]*\n?`),
+			Replacement: "",
+			Description: "sw_csrf() removed, the Storefront no longer uses CSRF tokens",
+			Category:    "twig",
+			Scope:       ScopeFile,
+			Examples: []Example{
+				{Before: "<form>\n    {{ sw_csrf('frontend.account.login') }}\n</form>", After: "<form>\n</form>"},
+			},
+		},
+		{
+			Name:        "Feature Flag Condition",
+			Pattern:     regexp.MustCompile(`\{%-?\s*(?:if|elseif)\s+(?:not\s+)?feature\(\s*['"]v6\.5\.0\.0['"]\s*\)\s*-?%\}`),
+			Replacement: "$0",
+			Todo:        "The v6.5.0.0 feature flag is always active in Shopware 6.5, remove the condition: https://developer.shopware.com/docs/resources/references/upgrades/core/6.5.html",
+			Description: "feature('v6.5.0.0') conditions are obsolete",
+			Category:    "twig",
+			Scope:       ScopeFile,
+			Examples: []Example{
+				{Before: "{% if feature('v6.5.0.0') %}", After: "{% if feature('v6.5.0.0') %} {# TODO #}"},
+			},
+		},
+		{
+			Name:        "HttpClient Arguments",
+			Pattern:     regexp.MustCompile(`new HttpClient\(\s*[^)\s][^)]*\)`),
+			Replacement: "new HttpClient()",
+			Description: "new HttpClient(accessKey, contextToken) → new HttpClient()",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "const client = new HttpClient(window.accessKey, window.contextToken);", After: "const client = new HttpClient();"},
+			},
+		},
+		{
+			Name:        "Store API Client",
+			Pattern:     regexp.MustCompile(`\bStoreApiClient\b[^;\n]*;?`),
+			Replacement: "$0",
+			Todo:        "StoreApiClient was removed in Shopware 6.5, use HttpClient: https://developer.shopware.com/docs/resources/references/upgrades/core/6.5.html",
+			Description: "StoreApiClient removed",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "const client = new StoreApiClient();", After: "const client = new StoreApiClient(); {# TODO #}"},
+			},
+		},
+		{
+			Name:        "CSRF JavaScript",
+			Pattern:     regexp.MustCompile(`\bwindow\.csrf\b[^;\n]*;?`),
+			Replacement: "$0",
+			Todo:        "window.csrf was removed in Shopware 6.5 together with the CSRF tokens",
+			Description: "window.csrf removed",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "const token = window.csrf.enabled;", After: "const token = window.csrf.enabled; {# TODO #}"},
+			},
+		},
+		{
+			Name:        "jQuery Import",
+			Pattern:     regexp.MustCompile(`import\s+\$\s+from\s+['"]jquery['"];?`),
+			Replacement: "$0",
+			Todo:        "jQuery was removed from the Storefront in Shopware 6.5, use plain JavaScript",
+			Description: "jQuery removed from the Storefront",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "import $ from 'jquery';", After: "import $ from 'jquery'; {# TODO #}"},
+			},
+		},
+		{
+			Name:        "Feature Flag Check",
+			Pattern:     regexp.MustCompile(`Feature\.isActive\(\s*['"]v6\.5\.0\.0['"]\s*\)`),
+			Replacement: "$0",
+			Todo:        "The v6.5.0.0 feature flag is always active in Shopware 6.5, remove the check",
+			Description: "Feature.isActive('v6.5.0.0') checks are obsolete",
+			Category:    "javascript",
+			RuleSet:     RuleSetScript,
+			Examples: []Example{
+				{Before: "if (Feature.isActive('v6.5.0.0')) {", After: "if (Feature.isActive('v6.5.0.0') {# TODO #}) {"},
+			},
+		},
+	}
+}
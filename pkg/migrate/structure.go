@@ -0,0 +1,433 @@
+package migrate
+
+import (
+	"sort"
+	"strings"
+)
+
+// Transform finds structural rewrites in markup content. It returns no
+// match for elements it cannot rewrite safely, so the class rules can add a TODO.
+type Transform func(content string, elements []*htmlElement) []Match
+
+// htmlElement is an element of a parsed HTML or Twig template
+type htmlElement struct {
+	Name       string
+	Start      int // Offset of the open tag
+	OpenEnd    int // Offset after the open tag
+	CloseStart int // Offset of the close tag, -1 when the element is not closed
+	End        int // Offset after the close tag, OpenEnd for void elements
+	ClassAttr  int // Offset of the class attribute name, -1 without class attribute
+	ClassStart int // Offset of the class attribute value
+	ClassEnd   int
+	AttrCount  int // Number of attributes including class
+	Parent     *htmlElement
+	Children   []*htmlElement
+}
+
+// textEdit replaces the content between Start and End with Text
+type textEdit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// voidElements have no content and no close tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// twigBlockTags are Twig tags closed by an end tag, e.g. {% if %} ... {% endif %}
+var twigBlockTags = map[string]bool{
+	"if": true, "for": true, "block": true, "embed": true, "macro": true, "apply": true,
+	"autoescape": true, "filter": true, "spaceless": true, "verbatim": true, "sandbox": true,
+	"with": true, "cache": true,
+}
+
+// parseElements returns the elements of content in document order. Unclosed and
+// misnested elements are kept with CloseStart -1.
+func parseElements(content string) []*htmlElement {
+	var elements []*htmlElement
+	var stack []*htmlElement
+
+	for i := 0; i < len(content); {
+		switch {
+		case strings.HasPrefix(content[i:], "{#"):
+			i = skipPast(content, i+2, "#}")
+		case strings.HasPrefix(content[i:], "{{"), strings.HasPrefix(content[i:], "{%"):
+			i = twigConstructEnd(content, i)
+		case strings.HasPrefix(content[i:], "<!--"):
+			i = skipPast(content, i+4, "-->")
+		case strings.HasPrefix(content[i:], "</") && i+2 < len(content) && isASCIILetter(content[i+2]):
+			nameEnd := i + 2
+			for nameEnd < len(content) && isTagNameChar(content[nameEnd]) {
+				nameEnd++
+			}
+			name := strings.ToLower(content[i+2 : nameEnd])
+			end := skipPast(content, nameEnd, ">")
+			for k := len(stack) - 1; k >= 0; k-- {
+				if stack[k].Name == name {
+					stack[k].CloseStart = i
+					stack[k].End = end
+					stack = stack[:k]
+					break
+				}
+			}
+			i = end
+		case content[i] == '<' && i+1 < len(content) && isASCIILetter(content[i+1]):
+			element, next := parseOpenTag(content, i)
+			if len(stack) > 0 {
+				element.Parent = stack[len(stack)-1]
+				element.Parent.Children = append(element.Parent.Children, element)
+			}
+			elements = append(elements, element)
+			if element.End < 0 {
+				stack = append(stack, element)
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+
+	return elements
+}
+
+// parseOpenTag parses the open tag starting at start and returns the offset to
+// continue at, which is after the body for script and style elements
+func parseOpenTag(content string, start int) (*htmlElement, int) {
+	var regions markupRegions
+	end := scanTag(content, start, false, &regions)
+
+	nameEnd := start + 1
+	for nameEnd < len(content) && isTagNameChar(content[nameEnd]) {
+		nameEnd++
+	}
+	element := &htmlElement{
+		Name:       strings.ToLower(content[start+1 : nameEnd]),
+		Start:      start,
+		OpenEnd:    end,
+		CloseStart: -1,
+		End:        -1,
+		ClassAttr:  -1,
+		ClassStart: -1,
+		AttrCount:  len(regions.Attribute),
+	}
+	if end <= len(content) && end > start && (voidElements[element.Name] || strings.HasSuffix(content[start:end], "/>")) {
+		element.CloseStart = end
+		element.End = end
+	}
+	if element.Name == "script" || element.Name == "style" {
+		// scanTag skips the body, the open tag ends at the first >
+		element.OpenEnd = skipPast(content, start, ">")
+	}
+
+	for _, attribute := range regions.Attribute {
+		if !strings.EqualFold(attribute.Text, "class") {
+			continue
+		}
+		k := attribute.Start + len(attribute.Text)
+		for k < end && isSpace(content[k]) {
+			k++
+		}
+		if k >= end || content[k] != '=' {
+			break
+		}
+		k++
+		for k < end && isSpace(content[k]) {
+			k++
+		}
+		if k < end && (content[k] == '"' || content[k] == '\'') {
+			element.ClassAttr = attribute.Start
+			element.ClassStart = k + 1
+			element.ClassEnd = attributeValueEnd(content, k+1, content[k])
+		}
+		break
+	}
+
+	return element, end
+}
+
+// Closed reports whether the element has a matching close tag
+func (e *htmlElement) Closed() bool {
+	return e.CloseStart >= 0
+}
+
+// classTokens returns the static class tokens with their offsets. Tokens glued
+// to {{ }} output are dynamic and left out.
+func (e *htmlElement) classTokens(content string) []contentRegion {
+	if e.ClassAttr < 0 {
+		return nil
+	}
+	text := classValueRegions(content, e.ClassStart, e.ClassEnd, false)[0].Text
+
+	var tokens []contentRegion
+	for i := 0; i < len(text); {
+		if isClassSeparator(text[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && !isClassSeparator(text[i]) {
+			i++
+		}
+		if !strings.ContainsRune(text[start:i], maskJoin) {
+			tokens = append(tokens, contentRegion{Start: e.ClassStart + start, Text: text[start:i]})
+		}
+	}
+	return tokens
+}
+
+// HasClass reports whether the element has the static class token name
+func (e *htmlElement) HasClass(content, name string) bool {
+	for _, token := range e.classTokens(content) {
+		if token.Text == name {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceClass returns the edit replacing the class token name, if present
+func (e *htmlElement) replaceClass(content, name, replacement string) []textEdit {
+	for _, token := range e.classTokens(content) {
+		if token.Text == name {
+			return []textEdit{{Start: token.Start, End: token.Start + len(name), Text: replacement}}
+		}
+	}
+	return nil
+}
+
+// removeClass returns the edit removing the class token name with one adjacent
+// space, or the whole class attribute when it is the only token
+func (e *htmlElement) removeClass(content, name string) []textEdit {
+	if e.ClassAttr >= 0 && strings.TrimSpace(content[e.ClassStart:e.ClassEnd]) == name {
+		start := e.ClassAttr
+		for start > e.Start && isSpace(content[start-1]) {
+			start--
+		}
+		return []textEdit{{Start: start, End: e.ClassEnd + 1}}
+	}
+	for _, token := range e.classTokens(content) {
+		if token.Text != name {
+			continue
+		}
+		start, end := token.Start, token.Start+len(name)
+		if end < e.ClassEnd && isSpace(content[end]) {
+			end++
+		} else if start > e.ClassStart && isSpace(content[start-1]) {
+			start--
+		}
+		return []textEdit{{Start: start, End: end}}
+	}
+	return nil
+}
+
+// addClass returns the edit appending the class token name
+func (e *htmlElement) addClass(content, name string) []textEdit {
+	switch {
+	case e.ClassAttr < 0:
+		nameEnd := e.Start + 1 + len(e.Name)
+		return []textEdit{{Start: nameEnd, End: nameEnd, Text: ` class="` + name + `"`}}
+	case strings.TrimSpace(content[e.ClassStart:e.ClassEnd]) == "":
+		return []textEdit{{Start: e.ClassStart, End: e.ClassEnd, Text: name}}
+	default:
+		return []textEdit{{Start: e.ClassEnd, End: e.ClassEnd, Text: " " + name}}
+	}
+}
+
+// applyEdits returns content between start and end with the edits applied
+func applyEdits(content string, start, end int, edits []textEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Start < edits[j].Start })
+
+	var builder strings.Builder
+	last := start
+	for _, edit := range edits {
+		builder.WriteString(content[last:edit.Start])
+		builder.WriteString(edit.Text)
+		last = edit.End
+	}
+	builder.WriteString(content[last:end])
+	return builder.String()
+}
+
+// twigBalanced reports whether the Twig block tags between start and end are
+// closed within the range, so the range can be moved or unwrapped safely
+func twigBalanced(content string, start, end int) bool {
+	var stack []string
+	for i := start; i < end; {
+		switch {
+		case strings.HasPrefix(content[i:], "{#"):
+			i = skipPast(content, i+2, "#}")
+		case strings.HasPrefix(content[i:], "{{"):
+			i = twigConstructEnd(content, i)
+		case strings.HasPrefix(content[i:], "{%"):
+			tagEnd := twigConstructEnd(content, i)
+			fields := strings.Fields(strings.Trim(content[i+2:tagEnd-2], "-~ \t\r\n"))
+			i = tagEnd
+			if len(fields) == 0 {
+				continue
+			}
+			name := fields[0]
+			switch {
+			case name == "else" || name == "elseif":
+				if len(stack) == 0 {
+					return false
+				}
+			case strings.HasPrefix(name, "end"):
+				if len(stack) == 0 || stack[len(stack)-1] != strings.TrimPrefix(name, "end") {
+					return false
+				}
+				stack = stack[:len(stack)-1]
+			case name == "set":
+				// {% set x %}...{% endset %} captures, {% set x = ... %} does not
+				if !strings.Contains(strings.Join(fields[1:], " "), "=") {
+					stack = append(stack, name)
+				}
+			case twigBlockTags[name] || strings.Contains(content, "end"+name):
+				stack = append(stack, name)
+			}
+		default:
+			i++
+		}
+	}
+	return len(stack) == 0
+}
+
+// lineIndent returns the whitespace before offset on its line, or "" when other
+// text precedes it
+func lineIndent(content string, offset int) string {
+	lineStart := strings.LastIndex(content[:offset], "\n") + 1
+	indent := content[lineStart:offset]
+	if strings.TrimLeft(indent, " \t") != "" {
+		return ""
+	}
+	return indent
+}
+
+// unwrapElement returns the content of element re-indented to the position of the element
+func unwrapElement(content string, element *htmlElement) string {
+	inner := content[element.OpenEnd:element.CloseStart]
+	trimmed := strings.TrimSpace(inner)
+	leading := len(inner) - len(strings.TrimLeft(inner, " \t\r\n"))
+	if !strings.Contains(inner[:leading], "\n") {
+		// Content starts on the line of the open tag
+		return trimmed
+	}
+
+	childIndent := lineIndent(content, element.OpenEnd+leading)
+	indent := lineIndent(content, element.Start)
+
+	lines := strings.Split(trimmed, "\n")
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], childIndent) {
+			lines[i] = indent + strings.TrimPrefix(lines[i], childIndent)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// structureMatch returns a match replacing the content between start and end
+func structureMatch(rule, content string, start, end int, replacement string) Match {
+	return Match{Rule: rule, Start: start, End: end, Original: content[start:end], Replacement: replacement}
+}
+
+// unwrapInputGroupAddons unwraps .input-group-prepend and .input-group-append
+// into their parent .input-group
+func unwrapInputGroupAddons(rule string) Transform {
+	return func(content string, elements []*htmlElement) []Match {
+		var matches []Match
+		for _, element := range elements {
+			if !element.HasClass(content, "input-group-prepend") && !element.HasClass(content, "input-group-append") {
+				continue
+			}
+			// Other classes or attributes of the wrapper would be lost
+			if element.Parent == nil || !element.Parent.HasClass(content, "input-group") ||
+				!element.Closed() || element.AttrCount != 1 || len(element.classTokens(content)) != 1 ||
+				strings.Contains(content[element.Start:element.OpenEnd], "{") ||
+				strings.TrimSpace(content[element.OpenEnd:element.CloseStart]) == "" ||
+				!twigBalanced(content, element.OpenEnd, element.CloseStart) {
+				continue
+			}
+			matches = append(matches, structureMatch(rule, content, element.Start, element.End, unwrapElement(content, element)))
+		}
+		return matches
+	}
+}
+
+// rewriteCustomFile turns the .custom-file widget into a label followed by a
+// .form-control file input
+func rewriteCustomFile(rule string) Transform {
+	return func(content string, elements []*htmlElement) []Match {
+		var matches []Match
+		for _, element := range elements {
+			if !element.HasClass(content, "custom-file") || !element.Closed() ||
+				!twigBalanced(content, element.OpenEnd, element.CloseStart) {
+				continue
+			}
+
+			var inputs, labels []*htmlElement
+			for _, child := range element.Children {
+				switch {
+				case child.Name == "input" && child.HasClass(content, "custom-file-input"):
+					inputs = append(inputs, child)
+				case child.Name == "label" && child.HasClass(content, "custom-file-label"):
+					labels = append(labels, child)
+				}
+			}
+			if len(inputs) != 1 || len(labels) != 1 || !labels[0].Closed() {
+				continue
+			}
+			input, label := inputs[0], labels[0]
+
+			edits := element.removeClass(content, "custom-file")
+			if input.Start < label.Start {
+				// The label goes before the input, so both must be movable
+				if !twigBalanced(content, label.OpenEnd, label.CloseStart) || !twigBalanced(content, input.End, label.Start) {
+					continue
+				}
+				labelText := applyEdits(content, label.Start, label.End, label.replaceClass(content, "custom-file-label", "form-label"))
+				inputText := applyEdits(content, input.Start, input.End, input.replaceClass(content, "custom-file-input", "form-control"))
+				edits = append(edits,
+					textEdit{Start: input.Start, End: input.End, Text: labelText},
+					textEdit{Start: label.Start, End: label.End, Text: inputText})
+			} else {
+				edits = append(edits, label.replaceClass(content, "custom-file-label", "form-label")...)
+				edits = append(edits, input.replaceClass(content, "custom-file-input", "form-control")...)
+			}
+
+			matches = append(matches, structureMatch(rule, content, element.Start, element.End, applyEdits(content, element.Start, element.End, edits)))
+		}
+		return matches
+	}
+}
+
+// rewriteMediaObjects turns .media into .d-flex and marks the children next to
+// .media-body with .flex-shrink-0
+func rewriteMediaObjects(rule string) Transform {
+	return func(content string, elements []*htmlElement) []Match {
+		edits := make(map[*htmlElement][]textEdit)
+		for _, element := range elements {
+			if !element.HasClass(content, "media") || !element.Closed() ||
+				!twigBalanced(content, element.OpenEnd, element.CloseStart) {
+				continue
+			}
+			edits[element] = append(edits[element], element.replaceClass(content, "media", "d-flex")...)
+			for _, child := range element.Children {
+				if !child.HasClass(content, "media-body") && !child.HasClass(content, "flex-grow-1") && !child.HasClass(content, "flex-shrink-0") {
+					edits[child] = append(edits[child], child.addClass(content, "flex-shrink-0")...)
+				}
+			}
+		}
+
+		// One match per open tag keeps nested media objects apart
+		var matches []Match
+		for _, element := range elements {
+			if len(edits[element]) > 0 {
+				matches = append(matches, structureMatch(rule, content, element.Start, element.OpenEnd,
+					applyEdits(content, element.Start, element.OpenEnd, edits[element])))
+			}
+		}
+		return matches
+	}
+}
@@ -0,0 +1,58 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+func TestStructureRewrites(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "bs4to5", "structure.html.twig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile(filepath.Join("testdata", "bs4to5", "structure.migrated.html.twig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, matches := Content(string(content), getBootstrapMigrations(), Options{})
+	if migrated != string(expected) {
+		t.Errorf("Unexpected structural rewrite:\n%s", unidiff.Unified("expected", "migrated", string(expected), migrated, 3))
+	}
+
+	rules := make(map[string]int)
+	for _, match := range matches {
+		rules[match.Rule]++
+	}
+	// Two addon wrappers in the first group, one in the second, the third is unsafe
+	for rule, count := range map[string]int{"Input Group Addons": 3, "Custom File Structure": 1, "Media Object Structure": 2, "Input Group Structure": 1, "Custom File": 1} {
+		if rules[rule] != count {
+			t.Errorf("Expected %d matches for %s, got %d", count, rule, rules[rule])
+		}
+	}
+}
+
+func TestTwigBalanced(t *testing.T) {
+	tests := []struct {
+		content  string
+		balanced bool
+	}{
+		{"<span>{{ value }}</span>", true},
+		{"{% if a %}<b>x</b>{% elseif b %}y{% else %}z{% endif %}", true},
+		{"{% for item in items %}{{ item }}{% endfor %}", true},
+		{"{% set label %}x{% endset %}{% set y = 1 %}", true},
+		{"{% if a %}<b>x</b>", false},
+		{"x{% endif %}", false},
+		{"{% else %}", false},
+		{"{% block a %}{% endif %}", false},
+	}
+
+	for _, tt := range tests {
+		if got := twigBalanced(tt.content, 0, len(tt.content)); got != tt.balanced {
+			t.Errorf("twigBalanced(%q) = %v, expected %v", tt.content, got, tt.balanced)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+import Plugin from 'src/plugin-system/plugin.class';
+
+/**
+ * Opens the offcanvas via [data-toggle="offcanvas"] triggers
+ */
+export default class OffcanvasTogglePlugin extends Plugin {
+    init() {
+        this._triggers = document.querySelectorAll('[data-toggle="offcanvas"], [data-dismiss]');
+        this._target = this.el.getAttribute('data-target');
+        this._url = 'https://example.com/data-toggle'; // not a selector
+
+        $(this.el).tooltip({ placement: 'left' });
+        $(this.el).data('toggle');
+        window.alert('done');
+    }
+}
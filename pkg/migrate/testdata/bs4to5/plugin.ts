@@ -0,0 +1,6 @@
+export function openModal(element: HTMLElement): void {
+    const target = element.getAttribute("data-target") ?? '';
+    document.querySelectorAll<HTMLElement>(`[data-ride=carousel]`).forEach((carousel) => {
+        $(carousel).carousel('cycle');
+    });
+}
@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Example is a before/after pair a rule must reproduce. In After,
+// {# TODO #} stands for the TODO comment of the rule.
+type Example struct {
+	Before string `yaml:"before" json:"before"`
+	After  string `yaml:"after" json:"after"`
+}
+
+// exampleTodo is the placeholder for the TODO comment of a rule in examples
+const exampleTodo = "{# TODO #}"
+
+// replacementReference matches $$, ${name} and $name in a replacement, see regexp.Expand
+var replacementReference = regexp.MustCompile(`\$(?:\$|\{([^}]*)\}|([A-Za-z0-9_]+))`)
+
+// checkCaptureGroups returns an error when replacement references a group pattern does not have
+func checkCaptureGroups(pattern *regexp.Regexp, replacement string) error {
+	for _, ref := range replacementReference.FindAllStringSubmatch(replacement, -1) {
+		if ref[0] == "$$" {
+			continue
+		}
+		name := ref[1] + ref[2]
+		digits := name[:len(name)-len(strings.TrimLeft(name, "0123456789"))]
+		if digits != "" && digits == name {
+			if number, _ := strconv.Atoi(digits); number > pattern.NumSubexp() {
+				return fmt.Errorf("replacement references group %s, the pattern has %d", ref[0], pattern.NumSubexp())
+			}
+			continue
+		}
+		if pattern.SubexpIndex(name) >= 0 {
+			continue
+		}
+		if digits != "" && ref[2] != "" {
+			return fmt.Errorf("replacement references unknown group %s, use ${%s} when text follows a group number", ref[0], digits)
+		}
+		return fmt.Errorf("replacement references unknown group %s", ref[0])
+	}
+	return nil
+}
+
+// VerifyRule checks the capture groups of a rule and runs it on its
+// examples. A rule must turn Before into After and leave After unchanged.
+func VerifyRule(rule Rule) []error {
+	var errs []error
+	if rule.Pattern != nil {
+		if err := checkCaptureGroups(rule.Pattern, rule.Replacement); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	options := Options{FileType: ruleSetFileType(rule)}
+	comment := options.FileType.Comment.Render("{# TODO: " + rule.Todo + " #}")
+	for i, example := range rule.Examples {
+		expected := strings.ReplaceAll(example.After, exampleTodo, comment)
+		migrated, _ := Content(example.Before, []Rule{rule}, options)
+		if migrated != expected {
+			errs = append(errs, fmt.Errorf("example %d: %q became %q, expected %q", i+1, example.Before, migrated, expected))
+			continue
+		}
+		if again, _ := Content(migrated, []Rule{rule}, options); again != migrated {
+			errs = append(errs, fmt.Errorf("example %d: a second run changed %q to %q", i+1, migrated, again))
+		}
+	}
+	return errs
+}
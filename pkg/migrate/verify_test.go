@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinRuleExamples(t *testing.T) {
+	for _, pack := range Packs() {
+		rules := pack.Rules()
+		if pack.Checks != nil {
+			rules = append(rules, pack.Checks()...)
+		}
+		for _, rule := range rules {
+			t.Run(pack.Name+"/"+rule.Name, func(t *testing.T) {
+				if len(rule.Examples) == 0 {
+					t.Error("Built-in rules need at least one example")
+				}
+				for _, err := range VerifyRule(rule) {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestCheckCaptureGroups(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		replacement string
+		err         string
+	}{
+		{`bs-tooltip-[top|bottom|left|right]`, "bs-tooltip-$1", "group $1, the pattern has 0"},
+		{`ml-(\d+)`, "ms-$1", ""},
+		{`(['"])data-(toggle)(['"])`, "${1}data-bs-$2$3", ""},
+		{`(['"])data-(toggle)(['"])`, "$1data-bs-$2$3", "unknown group $1data, use ${1}"},
+		{`theme-color\((\w+)\)`, "$$$1", ""},
+		{`\$yiq`, "$$color-contrast", ""},
+		{`(?P<side>left|right)`, "${side}", ""},
+		{`(?P<side>left|right)`, "$sides", "unknown group $sides"},
+		{`(a)`, "${2}", "group ${2}, the pattern has 1"},
+	}
+
+	for _, tt := range tests {
+		err := checkCaptureGroups(regexp.MustCompile(tt.pattern), tt.replacement)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s → %s: unexpected error %v", tt.pattern, tt.replacement, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s → %s: expected error containing %q, got %v", tt.pattern, tt.replacement, tt.err, err)
+		}
+	}
+}
+
+func TestRuleFileExamples(t *testing.T) {
+	tempDir := t.TempDir()
+	writeProjectFiles(t, tempDir, map[string]string{
+		"rules.yaml": `rules:
+  - name: Margin Left
+    replacement: ms-$1 custom-spacing
+  - name: Text Left
+    class: text-lg-left
+    replacement: text-lg-start
+    examples:
+      - before: '<p class="text-lg-left">'
+        after: '<p class="text-lg-start">'
+`,
+	})
+
+	ruleSet, err := LoadRules(Selection{Files: []string{filepath.Join(tempDir, "rules.yaml")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range ruleSet.Rules {
+		switch rule.Name {
+		case "Margin Left":
+			// The built-in examples no longer apply to the overridden replacement
+			if len(rule.Examples) != 0 {
+				t.Errorf("Expected no examples for the overridden rule, got %+v", rule.Examples)
+			}
+		case "Text Left":
+			if len(rule.Examples) != 1 || len(VerifyRule(rule)) != 0 {
+				t.Errorf("Expected the example of the rule file to pass: %+v %v", rule.Examples, VerifyRule(rule))
+			}
+		}
+	}
+}
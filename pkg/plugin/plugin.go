@@ -0,0 +1,184 @@
+// Package plugin finds wswcli-<name> plugin executables of a project and on PATH
+// and describes the context they run in.
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+)
+
+// Prefix is the file name prefix of plugin executables, wswcli-<name>
+const Prefix = "wswcli-"
+
+// shortMarker starts the line of a plugin that holds its description
+const shortMarker = "wswcli-short:"
+
+// headerSize is the number of leading bytes searched for the description
+const headerSize = 4096
+
+// Sources of plugins
+const (
+	SourceProject = "project" // .wswcli/plugins/ of the project
+	SourcePath    = "path"    // A directory of PATH
+)
+
+// Plugin is an executable found by Find
+type Plugin struct {
+	Name   string
+	Path   string
+	Source string
+	Short  string
+}
+
+// Skipped is an executable that is not registered as a subcommand
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+// Context is the JSON document a plugin receives on stdin
+type Context struct {
+	Name        string         `json:"name"`
+	Version     string         `json:"version"`
+	ProjectRoot string         `json:"project_root"`
+	Args        []string       `json:"args"`
+	Config      *config.Config `json:"config"`
+}
+
+// NewContext returns the context of running p with args in the project in root.
+// Lists of cfg that are nil are passed as empty lists, so that plugins get []
+// instead of null.
+func NewContext(p Plugin, version, root string, args []string, cfg *config.Config) Context {
+	result := *cfg
+	for _, list := range []*[]string{&result.BS4to5.Rules, &result.BS4to5.Only, &result.BS4to5.Skip} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	return Context{
+		Name:        p.Name,
+		Version:     version,
+		ProjectRoot: root,
+		Args:        append([]string{}, args...),
+		Config:      &result,
+	}
+}
+
+// Dir returns the plugin directory of the project in root, plugins/ in its
+// state directory, see config.StatePath
+func Dir(root string) string {
+	return filepath.Join(config.StatePath(root), "plugins")
+}
+
+// Find returns the plugins in the plugin directory of the project in root and
+// in the directories of PATH, in this order. The first executable of a name
+// wins, later ones and names in builtin are returned as skipped.
+func Find(root string, builtin map[string]bool) ([]Plugin, []Skipped) {
+	dirs := []struct {
+		path   string
+		source string
+	}{{Dir(root), SourceProject}}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			// An empty entry is the current directory, which is not searched
+			continue
+		}
+		dirs = append(dirs, struct {
+			path   string
+			source string
+		}{dir, SourcePath})
+	}
+
+	var plugins []Plugin
+	var skipped []Skipped
+	found := make(map[string]string)
+	seenDirs := make(map[string]bool)
+	for _, dir := range dirs {
+		if seenDirs[filepath.Clean(dir.path)] {
+			continue
+		}
+		seenDirs[filepath.Clean(dir.path)] = true
+
+		entries, err := os.ReadDir(dir.path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir.path, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			switch {
+			case builtin[name]:
+				skipped = append(skipped, Skipped{path, fmt.Sprintf("a built-in command is named %s", name)})
+			case found[name] != "":
+				skipped = append(skipped, Skipped{path, fmt.Sprintf("overshadowed by %s", found[name])})
+			default:
+				found[name] = path
+				plugins = append(plugins, Plugin{Name: name, Path: path, Source: dir.source, Short: pluginShort(path)})
+			}
+		}
+	}
+	return plugins, skipped
+}
+
+// pluginName returns the command name of a plugin file name, wswcli-<name>
+// with the executable extension removed on Windows
+func pluginName(fileName string) (string, bool) {
+	if !strings.HasPrefix(fileName, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(fileName, Prefix)
+	if runtime.GOOS == "windows" {
+		extension := strings.ToLower(filepath.Ext(name))
+		if extension != ".exe" && extension != ".bat" && extension != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	if name == "" || strings.ContainsAny(name, " \t.") {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable reports whether path is a regular file that can be executed,
+// following symlinks
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	// Windows has no executable bit, pluginName checks the extension
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// pluginShort returns the text after the wswcli-short: marker in the first
+// bytes of the executable at path, or an empty string
+func pluginShort(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(file, headerSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, shortMarker); index >= 0 {
+			return strings.TrimSpace(line[index+len(shortMarker):])
+		}
+	}
+	return ""
+}
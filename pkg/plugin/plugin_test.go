@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/config"
+)
+
+// writePlugin writes an executable shell script plugin to dir
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+
+	root := t.TempDir()
+	projectDir := filepath.Join(root, config.Name, "plugins")
+	pathDir := filepath.Join(t.TempDir(), "bin")
+	t.Setenv("PATH", pathDir+string(os.PathListSeparator)+pathDir)
+
+	projectLint := writePlugin(t, projectDir, "wswcli-lint", "# wswcli-short: Check the company coding rules\n")
+	pathLint := writePlugin(t, pathDir, "wswcli-lint", "")
+	writePlugin(t, pathDir, "wswcli-deploy", "")
+	builtin := writePlugin(t, pathDir, "wswcli-builtin", "")
+	if err := os.WriteFile(filepath.Join(pathDir, "wswcli-notes"), []byte("not executable"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writePlugin(t, pathDir, "other-tool", "")
+
+	plugins, skipped := Find(root, map[string]bool{"builtin": true})
+	if len(plugins) != 2 {
+		t.Fatalf("Expected 2 plugins, got %+v", plugins)
+	}
+	if plugins[0].Name != "lint" || plugins[0].Path != projectLint || plugins[0].Source != SourceProject {
+		t.Errorf("Expected the project plugin to win, got %+v", plugins[0])
+	}
+	if plugins[0].Short != "Check the company coding rules" {
+		t.Errorf("Unexpected description %q", plugins[0].Short)
+	}
+	if plugins[1].Name != "deploy" || plugins[1].Source != SourcePath || plugins[1].Short != "" {
+		t.Errorf("Unexpected PATH plugin %+v", plugins[1])
+	}
+
+	reasons := make(map[string]string)
+	for _, skip := range skipped {
+		reasons[skip.Path] = skip.Reason
+	}
+	if len(reasons) != 2 {
+		t.Fatalf("Expected 2 skipped plugins, got %+v", skipped)
+	}
+	if !strings.Contains(reasons[pathLint], projectLint) {
+		t.Errorf("Expected %s to be overshadowed by the project plugin, got %q", pathLint, reasons[pathLint])
+	}
+	if !strings.Contains(reasons[builtin], "built-in") {
+		t.Errorf("Expected %s to conflict with a built-in command, got %q", builtin, reasons[builtin])
+	}
+}
+
+func TestNewContext(t *testing.T) {
+	cfg := &config.Config{}
+	context := NewContext(Plugin{Name: "lint"}, "1.0.0", "/project", nil, cfg)
+	if context.Config.BS4to5.Skip == nil || context.Args == nil {
+		t.Errorf("Expected empty lists instead of nil, got %+v", context)
+	}
+	if cfg.BS4to5.Skip != nil {
+		t.Error("Expected the configuration not to be modified")
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeGitHub writes GitHub Actions workflow commands, which show the findings
+// as annotations of the workflow run and the pull request
+func writeGitHub(w io.Writer, result *Result) error {
+	out := bufio.NewWriter(w)
+	for _, finding := range result.Findings {
+		command := "notice"
+		switch finding.Severity {
+		case SeverityError:
+			command = "error"
+		case SeverityWarning:
+			command = "warning"
+		}
+
+		var properties []string
+		if finding.File != "" {
+			properties = append(properties, "file="+escapeGitHubProperty(finding.File))
+			if finding.Line > 0 {
+				properties = append(properties, fmt.Sprintf("line=%d", finding.Line))
+			}
+			if finding.EndLine > 0 {
+				properties = append(properties, fmt.Sprintf("endLine=%d", finding.EndLine))
+			}
+			if finding.Column > 0 {
+				properties = append(properties, fmt.Sprintf("col=%d", finding.Column))
+			}
+		}
+		properties = append(properties, "title="+escapeGitHubProperty(finding.Rule))
+		fmt.Fprintf(out, "::%s %s::%s\n", command, strings.Join(properties, ","), escapeGitHubData(finding.Message))
+	}
+	return out.Flush()
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(text string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(text)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command
+func escapeGitHubProperty(text string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeGitHubData(text))
+}
+
+// gitlabIssue is an entry of a GitLab Code Quality report
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"` // info, minor, major, critical or blocker
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// writeGitLab writes a GitLab Code Quality report, shown in merge request widgets
+func writeGitLab(w io.Writer, result *Result) error {
+	issues := make([]gitlabIssue, 0, len(result.Findings))
+	for _, finding := range result.Findings {
+		severity := "info"
+		switch finding.Severity {
+		case SeverityError:
+			severity = "major"
+		case SeverityWarning:
+			severity = "minor"
+		}
+		issues = append(issues, gitlabIssue{
+			Description: finding.Message,
+			CheckName:   finding.Rule,
+			Fingerprint: finding.Fingerprint(),
+			Severity:    severity,
+			Location:    gitlabLocation{Path: finding.File, Lines: gitlabLines{Begin: max(finding.Line, 1)}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
@@ -0,0 +1,25 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// writeJSON writes the whole result as an indented JSON document
+func writeJSON(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// writeNDJSON writes one JSON object per finding and line, for streaming into log
+// pipelines
+func writeNDJSON(w io.Writer, result *Result) error {
+	encoder := json.NewEncoder(w)
+	for _, finding := range result.Findings {
+		if err := encoder.Encode(finding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package report holds the findings of a wswcli command and writes them in the
+// formats CI systems and editors understand.
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Severity levels of findings
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is a single problem a command found in a file
+type Finding struct {
+	Rule     string `json:"rule"`               // Check or rule that produced the finding
+	Category string `json:"category,omitempty"` // Group of the rule, e.g. the migration category
+	Severity string `json:"severity"`           // error, warning or info
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"` // Slash-separated, relative to the root
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	EndLine  int    `json:"end_line,omitempty"`
+	Snippet  string `json:"snippet,omitempty"` // Source at the location
+}
+
+// Fingerprint returns an ID of the finding that stays the same across runs as
+// long as the finding does not move
+func (f Finding) Fingerprint() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.Rule, f.File, fmt.Sprint(f.Line), fmt.Sprint(f.Column), f.Message}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Result is the outcome of a command run
+type Result struct {
+	Tool     string    `json:"tool"`
+	Version  string    `json:"version,omitempty"`
+	Command  string    `json:"command"`        // Command that produced the result, e.g. "twigblocks"
+	Root     string    `json:"root,omitempty"` // Directory the file paths are relative to
+	Files    []string  `json:"files"`          // Files that were checked, also those without findings
+	Findings []Finding `json:"findings"`
+}
+
+// New returns an empty result of command
+func New(command, version string) *Result {
+	return &Result{Tool: "wswcli", Version: version, Command: command, Files: []string{}, Findings: []Finding{}}
+}
+
+// Add adds findings to the result
+func (r *Result) Add(findings ...Finding) {
+	r.Findings = append(r.Findings, findings...)
+}
+
+// Sort orders the findings by file, position and rule and the files by name
+func (r *Result) Sort() {
+	sort.Strings(r.Files)
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Rule < b.Rule
+	})
+}
+
+// Count returns the number of findings with the given severity
+func (r *Result) Count(severity string) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// AllFiles returns the checked files and the files with findings, sorted
+func (r *Result) AllFiles() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, file := range r.Files {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	for _, finding := range r.Findings {
+		if finding.File != "" && !seen[finding.File] {
+			seen[finding.File] = true
+			files = append(files, finding.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// byFile returns the findings grouped by file
+func (r *Result) byFile() map[string][]Finding {
+	files := make(map[string][]Finding)
+	for _, finding := range r.Findings {
+		files[finding.File] = append(files[finding.File], finding)
+	}
+	return files
+}
+
+// Formatter writes a result in an output format
+type Formatter interface {
+	Format(w io.Writer, result *Result) error
+}
+
+// FormatterFunc adapts a function to a Formatter
+type FormatterFunc func(w io.Writer, result *Result) error
+
+// Format calls f(w, result)
+func (f FormatterFunc) Format(w io.Writer, result *Result) error {
+	return f(w, result)
+}
+
+// formatters maps the format names to their formatters
+var formatters = map[string]Formatter{
+	"text":       FormatterFunc(writeText),
+	"json":       FormatterFunc(writeJSON),
+	"ndjson":     FormatterFunc(writeNDJSON),
+	"junit":      FormatterFunc(writeJUnit),
+	"sarif":      FormatterFunc(writeSARIF),
+	"checkstyle": FormatterFunc(writeCheckstyle),
+	"markdown":   FormatterFunc(writeMarkdown),
+	"github":     FormatterFunc(writeGitHub),
+	"gitlab":     FormatterFunc(writeGitLab),
+}
+
+// Register adds a formatter under name, replacing a formatter of the same name
+func Register(name string, formatter Formatter) {
+	formatters[name] = formatter
+}
+
+// Lookup returns the formatter registered under name
+func Lookup(name string) (Formatter, error) {
+	formatter, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format: %s (use %s)", name, strings.Join(Names(), ", "))
+	}
+	return formatter, nil
+}
+
+// Names returns the names of the registered formatters, sorted
+func Names() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write writes result to w in the named format
+func Write(w io.Writer, format string, result *Result) error {
+	formatter, err := Lookup(format)
+	if err != nil {
+		return err
+	}
+	result.Sort()
+	return formatter.Format(w, result)
+}
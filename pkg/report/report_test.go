@@ -0,0 +1,175 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func sampleResult() *Result {
+	result := New("twigblocks", "1.2.3")
+	result.Files = []string{"templates/base.html.twig", "templates/page.html.twig"}
+	result.Add(
+		Finding{Rule: "duplicate-block", Severity: SeverityError, Message: "Duplicate block 'content'", File: "templates/page.html.twig", Line: 9, Column: 1},
+		Finding{Rule: "duplicate-block", Severity: SeverityError, Message: "Duplicate block 'content'", File: "templates/page.html.twig", Line: 3, Column: 1},
+		Finding{Rule: "override", Severity: SeverityWarning, Message: "Overrides 'footer', see: a, b | c", File: "templates/base.html.twig", Line: 12},
+		Finding{Rule: "todo", Severity: SeverityInfo, Message: "Review\nmanually", File: "templates/base.html.twig", Line: 20},
+	)
+	return result
+}
+
+func format(t *testing.T, name string, result *Result) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, name, result); err != nil {
+		t.Fatalf("Write %s: %v", name, err)
+	}
+	return buf.String()
+}
+
+func TestRegistry(t *testing.T) {
+	expected := []string{"checkstyle", "github", "gitlab", "json", "junit", "markdown", "ndjson", "sarif", "text"}
+	if names := strings.Join(Names(), ","); names != strings.Join(expected, ",") {
+		t.Errorf("Expected formats %v, got %s", expected, names)
+	}
+	if _, err := Lookup("yaml"); err == nil || !strings.Contains(err.Error(), "sarif") {
+		t.Errorf("Expected an error listing the formats, got %v", err)
+	}
+
+	Register("count", FormatterFunc(func(w io.Writer, result *Result) error {
+		_, err := io.WriteString(w, strings.Repeat("x", len(result.Findings)))
+		return err
+	}))
+	defer delete(formatters, "count")
+	if output := format(t, "count", sampleResult()); output != "xxxx" {
+		t.Errorf("Expected the registered formatter to be used, got %q", output)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	expected := `templates/base.html.twig:12: warning: Overrides 'footer', see: a, b | c [override]
+templates/base.html.twig:20: info: Review
+manually [todo]
+templates/page.html.twig:3:1: error: Duplicate block 'content' [duplicate-block]
+templates/page.html.twig:9:1: error: Duplicate block 'content' [duplicate-block]
+
+4 findings (2 errors, 1 warning, 1 info) in 2 files
+`
+	if output := format(t, "text", sampleResult()); output != expected {
+		t.Errorf("Unexpected text output:\n%s\nExpected:\n%s", output, expected)
+	}
+	if output := format(t, "text", New("twigblocks", "")); output != "No findings in 0 files\n" {
+		t.Errorf("Unexpected text output without findings: %q", output)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var decoded Result
+	if err := json.Unmarshal([]byte(format(t, "json", sampleResult())), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Command != "twigblocks" || len(decoded.Findings) != 4 || decoded.Findings[2].Line != 3 {
+		t.Errorf("Unexpected JSON result: %+v", decoded)
+	}
+
+	lines := strings.Split(strings.TrimSpace(format(t, "ndjson", sampleResult())), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 NDJSON lines, got %d", len(lines))
+	}
+	var finding Finding
+	if err := json.Unmarshal([]byte(lines[0]), &finding); err != nil || finding.Rule != "override" {
+		t.Errorf("Unexpected NDJSON line %q (%v)", lines[0], err)
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var suite junitTestSuite
+	if err := xml.Unmarshal([]byte(format(t, "junit", sampleResult())), &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Tests != 2 || suite.Failures != 2 {
+		t.Fatalf("Expected 2 tests with 2 failures, got %d with %d", suite.Tests, suite.Failures)
+	}
+	base := suite.TestCases[0]
+	if base.Name != "templates/base.html.twig" || !strings.Contains(base.Failure.Text, "Overrides 'footer'") || !strings.Contains(base.SystemOut, "[todo]") {
+		t.Errorf("Unexpected test case: %+v", base)
+	}
+}
+
+func TestWriteCheckstyle(t *testing.T) {
+	var report checkstyleReport
+	if err := xml.Unmarshal([]byte(format(t, "checkstyle", sampleResult())), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 2 || len(report.Files[1].Errors) != 2 {
+		t.Fatalf("Unexpected checkstyle report: %+v", report)
+	}
+	if source := report.Files[1].Errors[0].Source; source != "wswcli.twigblocks.duplicate-block" {
+		t.Errorf("Unexpected source %s", source)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var log sarifLog
+	if err := json.Unmarshal([]byte(format(t, "sarif", sampleResult())), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if log.Version != "2.1.0" || run.Tool.Driver.Version != "1.2.3" || len(run.Tool.Driver.Rules) != 3 || len(run.Results) != 4 {
+		t.Fatalf("Unexpected SARIF log: %+v", log)
+	}
+	todo := run.Results[1]
+	if todo.Level != "note" || run.Tool.Driver.Rules[todo.RuleIndex].ID != "todo" || todo.Locations[0].PhysicalLocation.Region.StartLine != 20 {
+		t.Errorf("Unexpected SARIF result: %+v", todo)
+	}
+	if run.Results[2].PartialFingerprints["wswcli/v1"] == run.Results[3].PartialFingerprints["wswcli/v1"] {
+		t.Error("Expected distinct fingerprints for findings at different lines")
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	output := format(t, "markdown", sampleResult())
+	for _, expected := range []string{
+		"## wswcli twigblocks\n\n4 findings (2 errors, 1 warning, 1 info) in 2 files\n",
+		"| warning | `templates/base.html.twig:12` | override | Overrides 'footer', see: a, b \\| c |\n",
+		"| info | `templates/base.html.twig:20` | todo | Review manually |\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in Markdown output:\n%s", expected, output)
+		}
+	}
+}
+
+func TestWriteGitHub(t *testing.T) {
+	lines := strings.Split(format(t, "github", sampleResult()), "\n")
+	expected := []string{
+		"::warning file=templates/base.html.twig,line=12,title=override::Overrides 'footer', see: a, b | c",
+		"::notice file=templates/base.html.twig,line=20,title=todo::Review%0Amanually",
+		"::error file=templates/page.html.twig,line=3,col=1,title=duplicate-block::Duplicate block 'content'",
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Errorf("Expected line %d to be\n%s\ngot\n%s", i+1, line, lines[i])
+		}
+	}
+}
+
+func TestWriteGitLab(t *testing.T) {
+	var issues []gitlabIssue
+	if err := json.Unmarshal([]byte(format(t, "gitlab", sampleResult())), &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 4 || issues[0].Severity != "minor" || issues[2].Severity != "major" || issues[1].Severity != "info" {
+		t.Fatalf("Unexpected Code Quality issues: %+v", issues)
+	}
+	if issues[2].Location.Path != "templates/page.html.twig" || issues[2].Location.Lines.Begin != 3 || len(issues[2].Fingerprint) != 64 {
+		t.Errorf("Unexpected issue: %+v", issues[2])
+	}
+
+	if output := format(t, "gitlab", New("twigblocks", "")); strings.TrimSpace(output) != "[]" {
+		t.Errorf("Expected an empty array without findings, got %q", output)
+	}
+}
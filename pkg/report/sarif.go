@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SARIF 2.1.0 documents, reduced to the properties wswcli fills
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+
+	sarifResult struct {
+		RuleID              string            `json:"ruleId"`
+		RuleIndex           int               `json:"ruleIndex"`
+		Level               string            `json:"level"`
+		Message             sarifMessage      `json:"message"`
+		Locations           []sarifLocation   `json:"locations,omitempty"`
+		PartialFingerprints map[string]string `json:"partialFingerprints"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+		EndLine     int `json:"endLine,omitempty"`
+	}
+)
+
+// writeSARIF writes a SARIF 2.1.0 log, e.g. for GitHub code scanning
+func writeSARIF(w io.Writer, result *Result) error {
+	driver := sarifDriver{
+		Name:           result.Tool,
+		Version:        result.Version,
+		InformationURI: "https://github.com/wimwenigerkind/wswcli",
+		Rules:          []sarifRule{},
+	}
+	run := sarifRun{Results: []sarifResult{}}
+	rules := make(map[string]int)
+
+	for _, finding := range result.Findings {
+		index, ok := rules[finding.Rule]
+		if !ok {
+			index = len(driver.Rules)
+			rules[finding.Rule] = index
+			description := finding.Rule
+			if finding.Category != "" {
+				description = finding.Category + ": " + finding.Rule
+			}
+			driver.Rules = append(driver.Rules, sarifRule{ID: finding.Rule, ShortDescription: sarifMessage{Text: description}})
+		}
+
+		entry := sarifResult{
+			RuleID:              finding.Rule,
+			RuleIndex:           index,
+			Level:               sarifLevel(finding.Severity),
+			Message:             sarifMessage{Text: finding.Message},
+			PartialFingerprints: map[string]string{"wswcli/v1": finding.Fingerprint()},
+		}
+		if finding.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: finding.File}}}
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line, StartColumn: finding.Column, EndLine: finding.EndLine}
+			}
+			entry.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, entry)
+	}
+	run.Tool.Driver = driver
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
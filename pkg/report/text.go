@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeText writes one finding per line as file:line:column: severity: message [rule]
+func writeText(w io.Writer, result *Result) error {
+	out := bufio.NewWriter(w)
+	for _, finding := range result.Findings {
+		fmt.Fprintf(out, "%s: %s: %s [%s]\n", location(finding), finding.Severity, finding.Message, finding.Rule)
+	}
+	if len(result.Findings) > 0 {
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out, summary(result))
+	return out.Flush()
+}
+
+// writeMarkdown writes a summary and a table of the findings, e.g. for pull
+// request comments or job summaries
+func writeMarkdown(w io.Writer, result *Result) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "## wswcli %s\n\n%s\n", result.Command, summary(result))
+	if len(result.Findings) > 0 {
+		fmt.Fprintln(out, "\n| Severity | Location | Rule | Message |")
+		fmt.Fprintln(out, "|----------|----------|------|---------|")
+		for _, finding := range result.Findings {
+			fmt.Fprintf(out, "| %s | `%s` | %s | %s |\n", finding.Severity, location(finding), markdownCell(finding.Rule), markdownCell(finding.Message))
+		}
+	}
+	return out.Flush()
+}
+
+// location formats the position of a finding as file:line:column, leaving out
+// what is not known
+func location(finding Finding) string {
+	loc := finding.File
+	if loc == "" {
+		loc = "-"
+	}
+	if finding.Line > 0 {
+		loc += fmt.Sprintf(":%d", finding.Line)
+		if finding.Column > 0 {
+			loc += fmt.Sprintf(":%d", finding.Column)
+		}
+	}
+	return loc
+}
+
+// summary returns a line with the number of findings per severity
+func summary(result *Result) string {
+	files := len(result.AllFiles())
+	if len(result.Findings) == 0 {
+		return fmt.Sprintf("No findings in %d %s", files, plural(files, "file", "files"))
+	}
+	var counts []string
+	for _, severity := range []string{SeverityError, SeverityWarning, SeverityInfo} {
+		if n := result.Count(severity); n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, plural(n, severity, severity+"s")))
+		}
+	}
+	n := len(result.Findings)
+	return fmt.Sprintf("%d %s (%s) in %d %s", n, plural(n, "finding", "findings"), strings.Join(counts, ", "), files, plural(files, "file", "files"))
+}
+
+// markdownCell escapes text for a cell of a Markdown table
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// plural returns one when n is 1 and other otherwise
+func plural(n int, one, other string) string {
+	if n == 1 {
+		return one
+	}
+	return other
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitTestSuite is the root element of a JUnit XML report
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a checked file
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitFailure lists the errors and warnings of a file
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes a JUnit XML report with a test case per file that fails on
+// errors and warnings. Info findings are written as output of the test case.
+func writeJUnit(w io.Writer, result *Result) error {
+	suite := junitTestSuite{Name: "wswcli " + result.Command, Time: "0"}
+	byFile := result.byFile()
+	files := result.AllFiles()
+	if len(byFile[""]) > 0 {
+		files = append([]string{""}, files...)
+	}
+
+	for _, file := range files {
+		testCase := junitTestCase{ClassName: result.Command, Name: file, Time: "0"}
+		if file == "" {
+			testCase.Name = result.Command
+		}
+		var failures, infos []string
+		for _, finding := range byFile[file] {
+			line := fmt.Sprintf("%s: %s: %s [%s]", location(finding), finding.Severity, finding.Message, finding.Rule)
+			if finding.Severity == SeverityInfo {
+				infos = append(infos, line)
+			} else {
+				failures = append(failures, line)
+			}
+		}
+		if len(failures) > 0 {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d %s", len(failures), plural(len(failures), "finding", "findings")),
+				Type:    result.Command,
+				Text:    strings.Join(failures, "\n"),
+			}
+			suite.Failures++
+		}
+		testCase.SystemOut = strings.Join(infos, "\n")
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	return writeXML(w, suite)
+}
+
+// checkstyleReport is the root element of a Checkstyle XML report
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+// checkstyleFile holds the findings of a file
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+// checkstyleError is a single finding
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// writeCheckstyle writes a Checkstyle XML report, read by many CI servers and
+// review tools
+func writeCheckstyle(w io.Writer, result *Result) error {
+	report := checkstyleReport{Version: "4.3"}
+	byFile := result.byFile()
+	for _, file := range result.AllFiles() {
+		entry := checkstyleFile{Name: file}
+		for _, finding := range byFile[file] {
+			entry.Errors = append(entry.Errors, checkstyleError{
+				Line:     finding.Line,
+				Column:   finding.Column,
+				Severity: finding.Severity,
+				Message:  finding.Message,
+				Source:   "wswcli." + result.Command + "." + finding.Rule,
+			})
+		}
+		report.Files = append(report.Files, entry)
+	}
+	return writeXML(w, report)
+}
+
+// writeXML writes v as an indented XML document
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
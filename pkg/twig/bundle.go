@@ -0,0 +1,110 @@
+package twig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pluginClass matches the base class of a Shopware plugin
+var pluginClass = regexp.MustCompile(`class\s+(\w+)\s+extends\s+(?:\\?Shopware\\Core\\Framework\\)?Plugin\b`)
+
+// appName matches the name in the meta section of an app manifest
+var appName = regexp.MustCompile(`(?s)<meta>.*?<name>\s*([^<]+?)\s*</name>`)
+
+// BundleResolver derives bundle names for templates and caches them per extension directory
+type BundleResolver struct {
+	root  string
+	cache map[string]string
+}
+
+// NewBundleResolver creates a resolver for templates below root
+func NewBundleResolver(root string) *BundleResolver {
+	return &BundleResolver{root: root, cache: make(map[string]string)}
+}
+
+// BundleName returns the plugin, app or package name a template belongs to.
+// For plugins the name is read from composer.json or the plugin base class,
+// for apps from manifest.xml. The directory name is used as a fallback.
+func (r *BundleResolver) BundleName(file string) string {
+	parts := strings.Split(relativeSlashPath(r.root, file), "/")
+
+	for i, part := range parts {
+		if part == "custom" && i+2 < len(parts) {
+			switch parts[i+1] {
+			case "plugins", "static-plugins", "apps":
+				dir := filepath.Join(r.root, filepath.FromSlash(strings.Join(parts[:i+3], "/")))
+				return r.extensionName(dir, parts[i+1] == "apps")
+			}
+		}
+		if part == "vendor" && i+2 < len(parts) {
+			return parts[i+1] + "/" + parts[i+2]
+		}
+	}
+
+	return "project"
+}
+
+// extensionName reads and caches the name of a plugin or app directory
+func (r *BundleResolver) extensionName(dir string, app bool) string {
+	if name, ok := r.cache[dir]; ok {
+		return name
+	}
+
+	var name string
+	if app {
+		name = readAppName(dir)
+	} else {
+		name = readPluginName(dir)
+	}
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+
+	r.cache[dir] = name
+	return name
+}
+
+// readPluginName reads the plugin class from composer.json and falls back to
+// searching the plugin base class in src/
+func readPluginName(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "composer.json")); err == nil {
+		var composer struct {
+			Extra struct {
+				PluginClass string `json:"shopware-plugin-class"`
+			} `json:"extra"`
+		}
+		if json.Unmarshal(data, &composer) == nil && composer.Extra.PluginClass != "" {
+			className := composer.Extra.PluginClass
+			return className[strings.LastIndex(className, `\`)+1:]
+		}
+	}
+
+	phpFiles, _ := filepath.Glob(filepath.Join(dir, "src", "*.php"))
+	for _, phpFile := range phpFiles {
+		data, err := os.ReadFile(phpFile)
+		if err != nil {
+			continue
+		}
+		if match := pluginClass.FindSubmatch(data); match != nil {
+			return string(match[1])
+		}
+	}
+
+	return ""
+}
+
+// readAppName reads the app name from manifest.xml
+func readAppName(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.xml"))
+	if err != nil {
+		return ""
+	}
+
+	if match := appName.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package twig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+// Override is a single plugin, static plugin or app overriding a storefront block
+type Override struct {
+	Bundle      string
+	File        string
+	Line        int
+	CallsParent bool
+}
+
+// Conflict groups all overrides of the same storefront template and block
+type Conflict struct {
+	Template  string
+	BlockName string
+	Severity  string
+	Overrides []Override
+}
+
+// extensionDirs are the directories below the project root that hold plugins and apps
+var extensionDirs = []string{
+	filepath.Join("custom", "plugins"),
+	filepath.Join("custom", "static-plugins"),
+	filepath.Join("custom", "apps"),
+}
+
+// FindExtensionFiles finds all *.html.twig files of the plugins, static plugins
+// and apps below custom/ of the Shopware project in root
+func FindExtensionFiles(ctx context.Context, root string) ([]string, error) {
+	var files []string
+	for _, dir := range extensionDirs {
+		extensionRoot := filepath.Join(root, dir)
+		if _, err := os.Stat(extensionRoot); os.IsNotExist(err) {
+			continue
+		}
+		found, err := FindFiles(ctx, extensionRoot)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// FindConflicts groups overrides of the same @Storefront template and block
+// across bundles. A group is a conflict when at least two bundles override the
+// block and at least one of them replaces it without calling parent().
+// Conflicts where several bundles replace the block are errors, others warnings.
+func FindConflicts(templates []Template) []Conflict {
+	groups := make(map[string]*Conflict)
+
+	for _, tpl := range templates {
+		if !strings.HasPrefix(tpl.Parent, storefrontNamespace) {
+			continue
+		}
+		target := strings.TrimPrefix(tpl.Parent, storefrontNamespace)
+
+		for _, block := range tpl.Blocks {
+			key := target + "#" + block.Name
+			group, ok := groups[key]
+			if !ok {
+				group = &Conflict{Template: tpl.Parent, BlockName: block.Name}
+				groups[key] = group
+			}
+			group.Overrides = append(group.Overrides, Override{
+				Bundle:      tpl.Bundle,
+				File:        tpl.File,
+				Line:        block.Line,
+				CallsParent: CallsParent(tpl.Content, block, tpl.Blocks),
+			})
+		}
+	}
+
+	var conflicts []Conflict
+	for _, group := range groups {
+		bundles := make(map[string]bool)
+		replacing := make(map[string]bool)
+		for _, override := range group.Overrides {
+			bundles[override.Bundle] = true
+			if !override.CallsParent {
+				replacing[override.Bundle] = true
+			}
+		}
+
+		if len(bundles) > 1 && len(replacing) > 0 {
+			group.Severity = report.SeverityWarning
+			if len(replacing) > 1 {
+				group.Severity = report.SeverityError
+			}
+			sort.Slice(group.Overrides, func(i, j int) bool {
+				return group.Overrides[i].Bundle < group.Overrides[j].Bundle
+			})
+			conflicts = append(conflicts, *group)
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Template == conflicts[j].Template {
+			return conflicts[i].BlockName < conflicts[j].BlockName
+		}
+		return conflicts[i].Template < conflicts[j].Template
+	})
+
+	return conflicts
+}
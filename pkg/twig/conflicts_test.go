@@ -0,0 +1,116 @@
+package twig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+// writeFiles writes files with their content below root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for filePath, content := range files {
+		fullPath := filepath.Join(root, filePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBundleResolver(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, map[string]string{
+		"custom/plugins/swag-paypal/composer.json": `{
+    "name": "swag/paypal",
+    "extra": {"shopware-plugin-class": "Swag\\PayPal\\SwagPayPal"}
+}`,
+		"custom/plugins/swag-paypal/src/Resources/views/a.html.twig": "",
+		"custom/static-plugins/my-theme/src/MyTheme.php": `<?php
+namespace Acme\MyTheme;
+
+use Shopware\Core\Framework\Plugin;
+
+class MyTheme extends Plugin implements ThemeInterface
+{
+}`,
+		"custom/static-plugins/my-theme/src/Resources/views/a.html.twig": "",
+		"custom/apps/acme-app/manifest.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<manifest>
+    <meta>
+        <name>AcmeApp</name>
+        <label>Acme</label>
+    </meta>
+</manifest>`,
+		"custom/apps/acme-app/Resources/views/a.html.twig":        "",
+		"custom/plugins/UnnamedPlugin/src/Resources/views/a.twig": "",
+	})
+
+	resolver := NewBundleResolver(root)
+
+	tests := map[string]string{
+		"custom/plugins/swag-paypal/src/Resources/views/a.html.twig":     "SwagPayPal",
+		"custom/static-plugins/my-theme/src/Resources/views/a.html.twig": "MyTheme",
+		"custom/apps/acme-app/Resources/views/a.html.twig":               "AcmeApp",
+		"custom/plugins/UnnamedPlugin/src/Resources/views/a.twig":        "UnnamedPlugin",
+		"templates/a.html.twig": "project",
+	}
+
+	for file, expected := range tests {
+		if name := resolver.BundleName(filepath.Join(root, file)); name != expected {
+			t.Errorf("BundleName(%s) = %s, expected %s", file, name, expected)
+		}
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	root := t.TempDir()
+
+	writeFiles(t, root, map[string]string{
+		"custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+{% block base_header %}<header>A</header>{% endblock %}
+{% block base_footer %}{{ parent() }}A{% endblock %}
+`,
+		"custom/static-plugins/PluginB/src/Resources/views/storefront/base.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+{% block base_header %}<header>B</header>{% endblock %}
+{% block base_footer %}{{ parent() }}B{% endblock %}
+`,
+		"custom/apps/ThemeC/Resources/views/storefront/page/index.html.twig": `{% sw_extends '@Storefront/storefront/page/index.html.twig' %}
+{% block page_content %}C{% endblock %}
+`,
+	})
+
+	files, err := FindExtensionFiles(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := LoadTemplates(context.Background(), root, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := FindConflicts(templates)
+
+	// base_footer calls parent() everywhere and page_content is only overridden once
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d: %+v", len(conflicts), conflicts)
+	}
+
+	conflict := conflicts[0]
+	if conflict.BlockName != "base_header" || conflict.Template != "@Storefront/storefront/base.html.twig" {
+		t.Errorf("Unexpected conflict: %s in %s", conflict.BlockName, conflict.Template)
+	}
+	if conflict.Severity != report.SeverityError {
+		t.Errorf("Expected severity error when both plugins replace the block, got %s", conflict.Severity)
+	}
+	if len(conflict.Overrides) != 2 || conflict.Overrides[0].Bundle != "PluginA" || conflict.Overrides[1].Bundle != "PluginB" {
+		t.Errorf("Unexpected overrides: %+v", conflict.Overrides)
+	}
+}
@@ -0,0 +1,268 @@
+package twig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/migrate"
+)
+
+// Chooser picks the definition to keep among differing definitions of a block
+// in file. It returns the index in blocks, or false to leave the block alone.
+type Chooser func(file, content, name string, blocks []Block) (int, bool)
+
+// Fix is the planned removal of redundant block definitions from a file
+type Fix struct {
+	Fixed   string // Content after the removals
+	Removed int    // Number of removed block definitions
+	Skipped int    // Number of duplicated blocks the chooser left alone
+}
+
+// FixOptions controls how FixDuplicates fixes files
+type FixOptions struct {
+	Root   string  // Scanned project path; files outside of it are never touched
+	DryRun bool    // Plan the fixes without writing files
+	Choose Chooser // Picks among differing definitions, nil skips them
+}
+
+// FileFix is the fix of a single file
+type FileFix struct {
+	File     string
+	Original string // Content before the fix
+	Outside  bool   // The file is outside of the root and was skipped
+	Fix
+}
+
+// FixResult summarizes the edits of a fix run
+type FixResult struct {
+	FilesChanged  int
+	BlocksRemoved int
+	Skipped       int
+}
+
+// byteRange is a half-open byte range [Start, End) in a file
+type byteRange struct {
+	Start int
+	End   int
+}
+
+// DuplicateFiles returns the files containing the duplicate groups, sorted
+func DuplicateFiles(duplicates []DuplicateGroup) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+	for _, group := range duplicates {
+		for _, block := range group.Files {
+			if !fileSet[block.File] {
+				fileSet[block.File] = true
+				files = append(files, block.File)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// FixDuplicates removes redundant block definitions from every file that
+// contains duplicates, see PlanFix. done is called with the fix of every file,
+// including skipped files and files without removals, before the next file is
+// fixed.
+func FixDuplicates(duplicates []DuplicateGroup, opts FixOptions, done func(FileFix)) (FixResult, error) {
+	var result FixResult
+
+	for _, file := range DuplicateFiles(duplicates) {
+		inside, err := WithinRoot(opts.Root, file)
+		if err != nil {
+			return result, err
+		}
+		if !inside {
+			result.Skipped++
+			done(FileFix{File: file, Outside: true})
+			continue
+		}
+
+		fix, err := fixFile(file, opts)
+		if err != nil {
+			return result, fmt.Errorf("error fixing %s: %w", file, err)
+		}
+		result.Skipped += fix.Skipped
+		if fix.Removed > 0 {
+			result.FilesChanged++
+			result.BlocksRemoved += fix.Removed
+		}
+		done(fix)
+	}
+
+	return result, nil
+}
+
+// fixFile plans the removals for a single file and applies them unless
+// opts.DryRun is set
+func fixFile(file string, opts FixOptions) (FileFix, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return FileFix{}, err
+	}
+
+	// PlanFix re-parses the content so that offsets match the current file
+	fix := FileFix{File: file, Original: string(raw)}
+	fix.Fix = PlanFix(file, fix.Original, opts.Choose)
+	if fix.Removed == 0 || opts.DryRun {
+		return fix, nil
+	}
+
+	// The file keeps its permissions and is never left half written
+	if err := migrate.WriteFileAtomic(file, []byte(fix.Fixed), 0644); err != nil {
+		return FileFix{}, fmt.Errorf("error writing file: %w", err)
+	}
+	return fix, nil
+}
+
+// PlanFix plans the removal of duplicate block definitions from the content of
+// file. Exact duplicates of an earlier definition are removed, keeping the
+// first one; differing definitions are resolved with choose. With a nil
+// chooser differing definitions are skipped.
+func PlanFix(file, content string, choose Chooser) Fix {
+	fix := Fix{Fixed: content}
+
+	var removals []byteRange
+	var kept []Block
+	for _, group := range FindDuplicates(ParseBlocks(file, content)) {
+		keep, remove, ok := resolveDuplicateGroup(file, content, group, choose)
+		if !ok {
+			fix.Skipped++
+			continue
+		}
+		kept = append(kept, keep)
+		for _, block := range remove {
+			removals = append(removals, lineAlignedRange(content, block.Start, block.End))
+		}
+	}
+
+	removals = mergeRanges(removals, kept)
+	for i := len(removals) - 1; i >= 0; i-- {
+		fix.Fixed = fix.Fixed[:removals[i].Start] + fix.Fixed[removals[i].End:]
+	}
+	fix.Removed = len(removals)
+
+	return fix
+}
+
+// resolveDuplicateGroup decides which definition of a duplicated block to keep.
+// Exact duplicates of an earlier definition are always removed. If differing
+// definitions remain, choose decides which one to keep.
+func resolveDuplicateGroup(file, content string, group DuplicateGroup, choose Chooser) (Block, []Block, bool) {
+	var distinct []Block
+	var remove []Block
+	seen := make(map[string]bool)
+
+	for _, block := range group.Files {
+		if seen[block.Hash] {
+			remove = append(remove, block)
+			continue
+		}
+		seen[block.Hash] = true
+		distinct = append(distinct, block)
+	}
+
+	if len(distinct) == 1 {
+		return distinct[0], remove, true
+	}
+	if choose == nil {
+		return Block{}, nil, false
+	}
+
+	choice, ok := choose(file, content, group.BlockName, distinct)
+	if !ok || choice < 0 || choice >= len(distinct) {
+		return Block{}, nil, false
+	}
+
+	for i, block := range distinct {
+		if i != choice {
+			remove = append(remove, block)
+		}
+	}
+	return distinct[choice], remove, true
+}
+
+// lineAlignedRange widens a block span to whole lines when the block is the
+// only content on its lines, so that removing it leaves no blank line behind
+func lineAlignedRange(content string, start, end int) byteRange {
+	lineStart := strings.LastIndexByte(content[:start], '\n') + 1
+	if strings.TrimSpace(content[lineStart:start]) != "" {
+		return byteRange{Start: start, End: end}
+	}
+
+	lineEnd := len(content)
+	if next := strings.IndexByte(content[end:], '\n'); next >= 0 {
+		lineEnd = end + next + 1
+	}
+	if strings.TrimSpace(content[end:lineEnd]) != "" {
+		return byteRange{Start: start, End: end}
+	}
+
+	return byteRange{Start: lineStart, End: lineEnd}
+}
+
+// mergeRanges sorts removals, drops ranges nested in other removals and
+// discards any removal that would also delete a block we decided to keep
+func mergeRanges(ranges []byteRange, kept []Block) []byteRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].Start == ranges[j].Start {
+			return ranges[i].End > ranges[j].End
+		}
+		return ranges[i].Start < ranges[j].Start
+	})
+
+	var merged []byteRange
+	for _, r := range ranges {
+		if len(merged) > 0 && r.Start < merged[len(merged)-1].End {
+			continue
+		}
+
+		containsKept := false
+		for _, block := range kept {
+			if block.Start >= r.Start && block.End <= r.End {
+				containsKept = true
+				break
+			}
+		}
+		if containsKept {
+			continue
+		}
+
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// WithinRoot reports whether path resolves to a location inside root.
+// Symlinks are resolved so that links pointing out of the project are rejected.
+func WithinRoot(root, path string) (bool, error) {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false, fmt.Errorf("error resolving project path: %w", err)
+	}
+	resolvedRoot, err = filepath.Abs(resolvedRoot)
+	if err != nil {
+		return false, err
+	}
+
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, fmt.Errorf("error resolving %s: %w", path, err)
+	}
+	resolvedPath, err = filepath.Abs(resolvedPath)
+	if err != nil {
+		return false, err
+	}
+
+	rel, err := filepath.Rel(resolvedRoot, resolvedPath)
+	if err != nil {
+		return false, nil
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
@@ -0,0 +1,99 @@
+package twig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineAlignedRange(t *testing.T) {
+	content := "a\n    {% block x %}{% endblock %}\nb {% block y %}{% endblock %} c\n"
+
+	start := strings.Index(content, "{% block x")
+	end := strings.Index(content, "\nb") // End of first endblock
+	r := lineAlignedRange(content, start, end)
+	if content[r.Start:r.End] != "    {% block x %}{% endblock %}\n" {
+		t.Errorf("Expected whole line to be removed, got %q", content[r.Start:r.End])
+	}
+
+	start = strings.Index(content, "{% block y")
+	end = strings.Index(content, " c\n")
+	r = lineAlignedRange(content, start, end)
+	if content[r.Start:r.End] != "{% block y %}{% endblock %}" {
+		t.Errorf("Expected only the block span for inline block, got %q", content[r.Start:r.End])
+	}
+}
+
+func TestPlanFix(t *testing.T) {
+	content := "{% block title %}A{% endblock %}\n{% block title %}A{% endblock %}\n{% block body %}B{% endblock %}\n{% block body %}C{% endblock %}\n"
+
+	// Exact duplicates are removed, differing definitions are skipped without a chooser
+	fix := PlanFix("page.html.twig", content, nil)
+	if fix.Removed != 1 || fix.Skipped != 1 {
+		t.Errorf("Expected 1 removed and 1 skipped block, got %+v", fix)
+	}
+	if fix.Fixed != "{% block title %}A{% endblock %}\n{% block body %}B{% endblock %}\n{% block body %}C{% endblock %}\n" {
+		t.Errorf("Unexpected fixed content:\n%s", fix.Fixed)
+	}
+
+	// The chooser picks the definition to keep
+	fix = PlanFix("page.html.twig", content, func(file, content, name string, blocks []Block) (int, bool) {
+		if name != "body" || len(blocks) != 2 {
+			t.Errorf("Unexpected choice for %s: %+v", name, blocks)
+		}
+		return 1, true
+	})
+	if fix.Fixed != "{% block title %}A{% endblock %}\n{% block body %}C{% endblock %}\n" {
+		t.Errorf("Expected the chosen definition to remain, got:\n%s", fix.Fixed)
+	}
+}
+
+func TestFixDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "page.html.twig")
+	content := "{% block title %}A{% endblock %}\n{% block title %}A{% endblock %}\n"
+	if err := os.WriteFile(file, []byte(content), 0640); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := ExtractBlocks(context.Background(), []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicates := FindDuplicates(blocks)
+
+	// A dry run reports the fix without writing it
+	var fixes []FileFix
+	result, err := FixDuplicates(duplicates, FixOptions{Root: dir, DryRun: true}, func(fix FileFix) { fixes = append(fixes, fix) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FilesChanged != 1 || result.BlocksRemoved != 1 || len(fixes) != 1 || fixes[0].Original != content {
+		t.Errorf("Unexpected dry run %+v with fixes %+v", result, fixes)
+	}
+	if after, _ := os.ReadFile(file); string(after) != content {
+		t.Error("File content should not change in a dry run")
+	}
+
+	// The fix is written with the permissions of the file
+	if _, err := FixDuplicates(duplicates, FixOptions{Root: dir}, func(FileFix) {}); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.ReadFile(file); string(after) != "{% block title %}A{% endblock %}\n" {
+		t.Errorf("Unexpected content after fix:\n%s", after)
+	}
+	if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("Expected file mode 0640 to be preserved, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	// Files outside of the root are skipped
+	fixes = nil
+	result, err = FixDuplicates(duplicates, FixOptions{Root: t.TempDir()}, func(fix FileFix) { fixes = append(fixes, fix) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Skipped != 1 || len(fixes) != 1 || !fixes[0].Outside {
+		t.Errorf("Expected the file outside of the root to be skipped, got %+v with fixes %+v", result, fixes)
+	}
+}
@@ -0,0 +1,58 @@
+package twig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
+	"github.com/wimwenigerkind/wswcli/pkg/bitbucket"
+)
+
+// Insights returns the Code Insights report of the duplicate blocks found in
+// filesScanned files of the project in root, with one annotation per block
+// definition. The report fails when failed is set.
+func Insights(duplicates []DuplicateGroup, root string, filesScanned int, failed bool) bitbucket.Report {
+	report := bitbucket.Report{
+		Title:      "Twig block duplicates",
+		Details:    "Duplicate block definitions in *.html.twig files break template inheritance.",
+		ReportType: "BUG",
+		Reporter:   "wswcli",
+		Result:     "PASSED",
+		Data: []bitbucket.Data{
+			{Title: "Files scanned", Type: "NUMBER", Value: filesScanned},
+			{Title: "Duplicate block groups", Type: "NUMBER", Value: len(duplicates)},
+		},
+	}
+	if failed {
+		report.Result = "FAILED"
+	}
+
+	for _, group := range duplicates {
+		for i, block := range group.Files {
+			relPath, err := filepath.Rel(root, block.File)
+			if err != nil {
+				relPath = block.File
+			}
+			relPath = filepath.ToSlash(relPath)
+			report.Annotations = append(report.Annotations, bitbucket.Annotation{
+				ExternalID: annotationID(relPath, group.BlockName, i),
+				Path:       relPath,
+				Line:       block.Line,
+				Summary:    fmt.Sprintf("Duplicate block '%s' (appears %d times in this file)", group.BlockName, group.Count),
+				Details:    block.Content,
+				Type:       "BUG",
+				Severity:   bitbucket.AnnotationSeverity(group.Severity),
+				Result:     "FAILED",
+			})
+		}
+	}
+	return report
+}
+
+// annotationID returns an external ID that stays the same across runs as long as
+// the nth definition of block in file is reported
+func annotationID(file, block string, n int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", file, block, n)))
+	return "twigblocks-" + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,39 @@
+package twig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+func TestInsights(t *testing.T) {
+	duplicates := []DuplicateGroup{{
+		BlockName: "content",
+		Count:     2,
+		Severity:  report.SeverityError,
+		Files: []Block{
+			{Name: "content", File: filepath.Join("project", "templates", "page.html.twig"), Line: 3},
+			{Name: "content", File: filepath.Join("project", "templates", "page.html.twig"), Line: 9},
+		},
+	}}
+
+	insights := Insights(duplicates, "project", 3, true)
+	if insights.Result != "FAILED" || len(insights.Annotations) != 2 {
+		t.Fatalf("Expected a failed report with 2 annotations, got %s with %d", insights.Result, len(insights.Annotations))
+	}
+	first := insights.Annotations[0]
+	if first.Path != "templates/page.html.twig" || first.Line != 3 || first.Severity != "HIGH" {
+		t.Errorf("Unexpected annotation: %+v", first)
+	}
+	if first.ExternalID == insights.Annotations[1].ExternalID {
+		t.Error("Expected distinct external IDs per definition")
+	}
+	if again := Insights(duplicates, "project", 3, true); again.Annotations[0].ExternalID != first.ExternalID {
+		t.Error("Expected external IDs to be stable across runs")
+	}
+
+	if insights := Insights(duplicates, "project", 3, false); insights.Result != "PASSED" {
+		t.Errorf("Expected a passed report, got %s", insights.Result)
+	}
+}
@@ -0,0 +1,77 @@
+package twig
+
+import "sort"
+
+// InventoryEntry describes a single block definition of the block inventory
+type InventoryEntry struct {
+	Template       string   `json:"template"`
+	Block          string   `json:"block"`
+	ParentTemplate string   `json:"parent_template,omitempty"`
+	Line           int      `json:"line"`
+	EndLine        int      `json:"end_line"`
+	Depth          int      `json:"depth"`
+	CallsParent    bool     `json:"calls_parent"`
+	Bundle         string   `json:"bundle"`
+	OverriddenBy   []string `json:"overridden_by"`
+}
+
+// BuildInventory creates one inventory entry per block definition, sorted by
+// template path relative to root and line
+func BuildInventory(root string, templates []Template) []InventoryEntry {
+	// Index which bundles override which block of which logical template
+	overrides := make(map[string][]string)
+	for _, tpl := range templates {
+		if tpl.Parent == "" {
+			continue
+		}
+		for _, block := range tpl.Blocks {
+			key := tpl.Logical + "#" + block.Name
+			overrides[key] = appendUnique(overrides[key], tpl.Bundle)
+		}
+	}
+
+	var entries []InventoryEntry
+	for _, tpl := range templates {
+		relPath := relativeSlashPath(root, tpl.File)
+		for _, block := range tpl.Blocks {
+			var overriddenBy []string
+			for _, bundle := range overrides[tpl.Logical+"#"+block.Name] {
+				if bundle != tpl.Bundle {
+					overriddenBy = append(overriddenBy, bundle)
+				}
+			}
+			sort.Strings(overriddenBy)
+
+			entries = append(entries, InventoryEntry{
+				Template:       relPath,
+				Block:          block.Name,
+				ParentTemplate: tpl.Parent,
+				Line:           block.Line,
+				EndLine:        block.EndLine,
+				Depth:          block.Depth,
+				CallsParent:    CallsParent(tpl.Content, block, tpl.Blocks),
+				Bundle:         tpl.Bundle,
+				OverriddenBy:   overriddenBy,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Template == entries[j].Template {
+			return entries[i].Line < entries[j].Line
+		}
+		return entries[i].Template < entries[j].Template
+	})
+
+	return entries
+}
+
+// appendUnique appends value unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
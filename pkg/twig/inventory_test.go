@@ -0,0 +1,97 @@
+package twig
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func createInventoryProject(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+
+{% block base_header %}
+    {{ parent() }}
+    {% block base_header_inner %}
+        <div>A</div>
+    {% endblock %}
+{% endblock %}
+`,
+		"custom/plugins/PluginB/src/Resources/views/storefront/base.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+
+{% block base_header %}
+    <header>B</header>
+{% endblock %}
+`,
+		"custom/apps/MyTheme/Resources/views/storefront/page/index.html.twig": `{% block page_content %}
+    {% block page_content_inner %}{{ parent() }}{% endblock %}
+{% endblock %}
+`,
+	}
+
+	writeFiles(t, tempDir, files)
+
+	return tempDir
+}
+
+func TestBuildBlockInventory(t *testing.T) {
+	root := createInventoryProject(t)
+
+	files, err := FindFiles(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadTemplates(context.Background(), root, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := BuildInventory(root, templates)
+	if len(entries) != 5 {
+		t.Fatalf("Expected 5 inventory entries, got %d: %+v", len(entries), entries)
+	}
+
+	find := func(template, block string) *InventoryEntry {
+		for i := range entries {
+			if strings.Contains(entries[i].Template, template) && entries[i].Block == block {
+				return &entries[i]
+			}
+		}
+		t.Fatalf("Entry %s#%s not found", template, block)
+		return nil
+	}
+
+	headerA := find("PluginA", "base_header")
+	if headerA.Bundle != "PluginA" {
+		t.Errorf("Expected bundle PluginA, got %s", headerA.Bundle)
+	}
+	if headerA.ParentTemplate != "@Storefront/storefront/base.html.twig" {
+		t.Errorf("Unexpected parent template: %s", headerA.ParentTemplate)
+	}
+	if !headerA.CallsParent {
+		t.Error("Expected base_header in PluginA to call parent()")
+	}
+	if headerA.Line != 3 || headerA.EndLine != 8 || headerA.Depth != 0 {
+		t.Errorf("Unexpected span for base_header: %d-%d depth %d", headerA.Line, headerA.EndLine, headerA.Depth)
+	}
+	if len(headerA.OverriddenBy) != 1 || headerA.OverriddenBy[0] != "PluginB" {
+		t.Errorf("Expected base_header to be overridden by PluginB, got %v", headerA.OverriddenBy)
+	}
+
+	innerA := find("PluginA", "base_header_inner")
+	if innerA.Depth != 1 || innerA.CallsParent {
+		t.Errorf("Expected nested block at depth 1 without parent(), got depth %d parent %v", innerA.Depth, innerA.CallsParent)
+	}
+
+	// parent() inside a nested block does not count for the outer block
+	pageContent := find("MyTheme", "page_content")
+	if pageContent.CallsParent {
+		t.Error("page_content should not report the nested parent() call")
+	}
+	if pageContent.Bundle != "MyTheme" {
+		t.Errorf("Expected bundle MyTheme, got %s", pageContent.Bundle)
+	}
+}
@@ -0,0 +1,103 @@
+package twig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Template holds the parsed information of a single template file
+type Template struct {
+	File    string
+	Logical string // Path below Resources/views, e.g. storefront/base.html.twig
+	Parent  string // Template named in the extends or sw_extends tag
+	Bundle  string // Plugin, app or package the template belongs to
+	Blocks  []Block
+	Content string
+}
+
+// parentCall matches a parent() call in a block body
+var parentCall = regexp.MustCompile(`\bparent\(\s*\)`)
+
+// quotedName matches the quoted template name of an extends tag
+var quotedName = regexp.MustCompile(`['"]([^'"]+)['"]`)
+
+// LoadTemplates parses the given files below root into templates
+func LoadTemplates(ctx context.Context, root string, files []string) ([]Template, error) {
+	bundles := NewBundleResolver(root)
+
+	var templates []Template
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+
+		templates = append(templates, Template{
+			File:    file,
+			Logical: LogicalPath(root, file),
+			Parent:  ParentTemplate(string(content)),
+			Bundle:  bundles.BundleName(file),
+			Blocks:  ParseBlocks(file, string(content)),
+			Content: string(content),
+		})
+	}
+
+	return templates, nil
+}
+
+// ParentTemplate returns the template named in the first extends or sw_extends tag
+func ParentTemplate(content string) string {
+	for _, tag := range ScanTags(content) {
+		fields := strings.Fields(tag.Body)
+		if len(fields) == 0 || (fields[0] != "extends" && fields[0] != "sw_extends") {
+			continue
+		}
+		if match := quotedName.FindStringSubmatch(tag.Body); match != nil {
+			return match[1]
+		}
+		return ""
+	}
+
+	return ""
+}
+
+// CallsParent reports whether parent() is called in the block's own body,
+// ignoring calls inside nested blocks. all are the blocks of the same content.
+func CallsParent(content string, block Block, all []Block) bool {
+	own := []byte(content[block.Start:block.End])
+	for _, nested := range all {
+		if nested.Start > block.Start && nested.End <= block.End && nested.Depth == block.Depth+1 {
+			for i := nested.Start - block.Start; i < nested.End-block.Start; i++ {
+				own[i] = ' '
+			}
+		}
+	}
+
+	return parentCall.Match(own)
+}
+
+// LogicalPath returns the template path as Twig namespaces see it, which is
+// the path below Resources/views when present
+func LogicalPath(root, file string) string {
+	slashPath := filepath.ToSlash(file)
+	if index := strings.LastIndex(slashPath, "/Resources/views/"); index >= 0 {
+		return slashPath[index+len("/Resources/views/"):]
+	}
+	return relativeSlashPath(root, file)
+}
+
+// relativeSlashPath returns file relative to root using forward slashes
+func relativeSlashPath(root, file string) string {
+	relPath, err := filepath.Rel(root, file)
+	if err != nil {
+		relPath = file
+	}
+	return filepath.ToSlash(relPath)
+}
@@ -0,0 +1,299 @@
+// Package twig parses block definitions of Twig templates and finds blocks that
+// are defined more than once in a template.
+package twig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+// Block represents a Twig block found in a file
+type Block struct {
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line"`
+	Depth   int    `json:"depth"`
+	Content string `json:"content"`
+	Hash    string `json:"hash"`
+	Start   int    `json:"-"` // Byte offset of the opening tag
+	End     int    `json:"-"` // Byte offset just after the matching endblock tag
+}
+
+// DuplicateGroup represents a group of duplicate blocks
+type DuplicateGroup struct {
+	BlockName string  `json:"block_name"`
+	Hash      string  `json:"hash"`
+	Count     int     `json:"count"`
+	Severity  string  `json:"severity"`
+	Files     []Block `json:"files"`
+}
+
+// blockTag matches Twig block definitions: {% block blockname %} or
+// {% block blockname with optional attributes %}
+var blockTag = regexp.MustCompile(`{%\s*block\s+([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:[^%]*)%}`)
+
+// FindFiles recursively finds all *.html.twig files in the given directory,
+// skipping hidden, dependency and build directories
+func FindFiles(ctx context.Context, rootPath string) ([]string, error) {
+	var twigFiles []string
+
+	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Skip hidden directories and common build/cache directories
+		if info.IsDir() {
+			name := info.Name()
+			// Skip hidden directories, but not if it's the root path we're scanning
+			if path != rootPath && (strings.HasPrefix(name, ".") ||
+				name == "node_modules" ||
+				name == "vendor" ||
+				name == "var" ||
+				name == "cache" ||
+				name == "build") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Check if file matches *.html.twig pattern
+		if strings.HasSuffix(strings.ToLower(path), ".html.twig") {
+			twigFiles = append(twigFiles, path)
+		}
+
+		return nil
+	})
+
+	return twigFiles, err
+}
+
+// ExtractBlocks extracts all Twig blocks from the given files
+func ExtractBlocks(ctx context.Context, files []string) ([]Block, error) {
+	var allBlocks []Block
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blocks, err := ReadBlocks(file)
+		if err != nil {
+			return nil, fmt.Errorf("error processing file %s: %w", file, err)
+		}
+		allBlocks = append(allBlocks, blocks...)
+	}
+
+	return allBlocks, nil
+}
+
+// ReadBlocks extracts Twig blocks from a single file
+func ReadBlocks(filename string) ([]Block, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseBlocks(filename, string(content)), nil
+}
+
+// Tag is a {% ... %} tag found in a template, with its byte offsets
+type Tag struct {
+	Start int
+	End   int
+	Body  string
+}
+
+// ScanTags returns all {% ... %} tags of a template in source order.
+// Comments ({# ... #}) and {% verbatim %} sections are skipped entirely.
+func ScanTags(content string) []Tag {
+	var tags []Tag
+	verbatim := false
+
+	for i := 0; i < len(content); {
+		next := strings.IndexByte(content[i:], '{')
+		if next < 0 || i+next+1 >= len(content) {
+			break
+		}
+		pos := i + next
+
+		switch content[pos+1] {
+		case '#':
+			end := strings.Index(content[pos+2:], "#}")
+			if end < 0 {
+				return tags
+			}
+			i = pos + 2 + end + 2
+		case '%':
+			end := strings.Index(content[pos+2:], "%}")
+			if end < 0 {
+				return tags
+			}
+			tagEnd := pos + 2 + end + 2
+			body := strings.Trim(content[pos+2:tagEnd-2], "-~ \t\r\n")
+			keyword := ""
+			if fields := strings.Fields(body); len(fields) > 0 {
+				keyword = fields[0]
+			}
+
+			switch {
+			case verbatim && keyword != "endverbatim":
+				// Everything inside verbatim is literal text
+			case keyword == "verbatim":
+				verbatim = true
+			case keyword == "endverbatim":
+				verbatim = false
+			default:
+				tags = append(tags, Tag{Start: pos, End: tagEnd, Body: body})
+			}
+			i = tagEnd
+		default:
+			i = pos + 1
+		}
+	}
+
+	return tags
+}
+
+// ParseBlocks extracts block definitions including their exact spans.
+// Each block spans from its opening tag to the end of its matching endblock tag.
+func ParseBlocks(filename, content string) []Block {
+	var blocks []Block
+	var stack []int
+
+	for _, tag := range ScanTags(content) {
+		fields := strings.Fields(tag.Body)
+
+		switch {
+		case len(fields) > 0 && fields[0] == "endblock":
+			if len(stack) == 0 {
+				continue
+			}
+			index := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			closeBlock(&blocks[index], content, tag.End)
+		case len(fields) > 0 && fields[0] == "block":
+			// Match against the normalized tag so whitespace control ({%- -%}) is supported
+			match := blockTag.FindStringSubmatch("{% " + tag.Body + " %}")
+			if len(match) < 2 {
+				continue
+			}
+
+			block := Block{
+				Name:    match[1],
+				File:    filename,
+				Line:    lineAt(content, tag.Start),
+				Content: strings.TrimSpace(lineContaining(content, tag.Start)),
+				Depth:   len(stack),
+				Start:   tag.Start,
+			}
+			blocks = append(blocks, block)
+
+			// Shortcut blocks ({% block title page.title %}) have no endblock
+			if len(fields) > 2 {
+				closeBlock(&blocks[len(blocks)-1], content, tag.End)
+				continue
+			}
+			stack = append(stack, len(blocks)-1)
+		}
+	}
+
+	// Unclosed blocks run to the end of the file
+	for _, index := range stack {
+		closeBlock(&blocks[index], content, len(content))
+	}
+
+	return blocks
+}
+
+// closeBlock records the end of a block span and hashes its full source
+func closeBlock(block *Block, content string, end int) {
+	block.End = end
+	block.EndLine = lineAt(content, end-1)
+	block.Hash = ContentHash(content[block.Start:end])
+}
+
+// lineAt returns the 1-based line number of the given byte offset
+func lineAt(content string, offset int) int {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(content) {
+		offset = len(content)
+	}
+	return strings.Count(content[:offset], "\n") + 1
+}
+
+// lineContaining returns the full line around the given byte offset
+func lineContaining(content string, offset int) string {
+	start := strings.LastIndexByte(content[:offset], '\n') + 1
+	end := strings.IndexByte(content[offset:], '\n')
+	if end < 0 {
+		return content[start:]
+	}
+	return content[start : offset+end]
+}
+
+// ContentHash generates a hash of the block content for duplicate detection
+func ContentHash(content string) string {
+	// Normalize whitespace so that indentation changes do not affect the hash
+	normalized := strings.Join(strings.Fields(content), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FindDuplicates identifies blocks with the same name within the same file
+func FindDuplicates(blocks []Block) []DuplicateGroup {
+	// Group blocks by file first, then by block name within each file
+	fileGroups := make(map[string][]Block)
+	for _, block := range blocks {
+		fileGroups[block.File] = append(fileGroups[block.File], block)
+	}
+
+	var duplicates []DuplicateGroup
+
+	// Check each file for duplicate block names
+	for fileName, fileBlocks := range fileGroups {
+		// Group blocks by name within this file
+		blockGroups := make(map[string][]Block)
+		for _, block := range fileBlocks {
+			blockGroups[block.Name] = append(blockGroups[block.Name], block)
+		}
+
+		// Check for duplicates within this file
+		for blockName, groupBlocks := range blockGroups {
+			if len(groupBlocks) > 1 {
+				// Multiple blocks with same name in same file - this is a problem
+				duplicates = append(duplicates, DuplicateGroup{
+					BlockName: blockName,
+					Hash:      fmt.Sprintf("file-%s", fileName),
+					Count:     len(groupBlocks),
+					Severity:  report.SeverityError,
+					Files:     groupBlocks,
+				})
+			}
+		}
+	}
+
+	// Sort duplicates by block name for consistent output
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].BlockName == duplicates[j].BlockName {
+			return duplicates[i].Files[0].File < duplicates[j].Files[0].File
+		}
+		return duplicates[i].BlockName < duplicates[j].BlockName
+	})
+
+	return duplicates
+}
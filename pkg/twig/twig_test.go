@@ -0,0 +1,319 @@
+package twig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindFiles(t *testing.T) {
+	// Create temporary directory structure
+	tempDir := t.TempDir()
+
+	// Create test files
+	testFiles := []string{
+		"template.html.twig",
+		"subdir/another.html.twig",
+		"subdir/nested/deep.html.twig",
+		"regular.html",                   // Should be ignored
+		"template.twig",                  // Should be ignored (not .html.twig)
+		"node_modules/ignored.html.twig", // Should be ignored (node_modules)
+		".hidden/ignored.html.twig",      // Should be ignored (hidden dir)
+	}
+
+	for _, file := range testFiles {
+		fullPath := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("test content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Test findTwigFiles
+	found, err := FindFiles(context.Background(), tempDir)
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+
+	// Should find exactly 3 .html.twig files (excluding ignored ones)
+	if len(found) != 3 {
+		t.Errorf("Expected 3 files, got %d: %v", len(found), found)
+	}
+
+	// Check that all found files end with .html.twig
+	for _, file := range found {
+		if !strings.HasSuffix(file, ".html.twig") {
+			t.Errorf("Found file doesn't end with .html.twig: %s", file)
+		}
+	}
+}
+
+func TestReadBlocks(t *testing.T) {
+	// Create temporary file with Twig blocks
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "test.html.twig")
+
+	content := `
+{% extends "base.html.twig" %}
+
+{% block title %}Page Title{% endblock %}
+
+{% block content %}
+    <div class="content">
+        {% block inner_content %}
+            Default inner content
+        {% endblock %}
+    </div>
+{% endblock %}
+
+{% block sidebar %}
+    Sidebar content
+{% endblock %}
+
+{# This is a comment with {% block fake_block %} - should be ignored #}
+`
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Test block extraction
+	blocks, err := ReadBlocks(testFile)
+	if err != nil {
+		t.Fatalf("ReadBlocks failed: %v", err)
+	}
+
+	// Should find 4 blocks: title, content, inner_content, sidebar
+	expectedBlocks := []string{"title", "content", "inner_content", "sidebar"}
+	if len(blocks) != len(expectedBlocks) {
+		t.Errorf("Expected %d blocks, got %d. Found blocks: %v", len(expectedBlocks), len(blocks), getBlockNames(blocks))
+	}
+
+	// Check block names
+	foundNames := make(map[string]bool)
+	for _, block := range blocks {
+		foundNames[block.Name] = true
+
+		// Verify file path is correct
+		if block.File != testFile {
+			t.Errorf("Block file path incorrect: expected %s, got %s", testFile, block.File)
+		}
+
+		// Verify line number is positive
+		if block.Line <= 0 {
+			t.Errorf("Block line number should be positive, got %d", block.Line)
+		}
+	}
+
+	for _, expected := range expectedBlocks {
+		if !foundNames[expected] {
+			t.Errorf("Expected block '%s' not found", expected)
+		}
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	// Create test blocks with duplicates within same file
+	blocks := []Block{
+		{Name: "content", File: "file1.twig", Line: 5, Content: "{% block content %}", Hash: "hash1"},
+		{Name: "content", File: "file1.twig", Line: 15, Content: "{% block content %}", Hash: "hash1"}, // Duplicate in same file
+		{Name: "sidebar", File: "file1.twig", Line: 10, Content: "{% block sidebar %}", Hash: "hash2"},
+		{Name: "title", File: "file2.twig", Line: 1, Content: "{% block title %}", Hash: "hash3"},
+		{Name: "content", File: "file2.twig", Line: 8, Content: "{% block content %}", Hash: "hash4"}, // Same name, different file - should NOT be duplicate
+		{Name: "footer", File: "file3.twig", Line: 5, Content: "{% block footer %}", Hash: "hash5"},
+		{Name: "footer", File: "file3.twig", Line: 10, Content: "{% block footer %}", Hash: "hash5"}, // Another duplicate in same file
+	}
+
+	duplicates := FindDuplicates(blocks)
+
+	// Should find duplicates only within same files
+	if len(duplicates) != 2 {
+		t.Errorf("Expected 2 duplicate groups (content in file1, footer in file3), got %d", len(duplicates))
+	}
+
+	// Check that content block duplicates are found in file1
+	var contentDuplicates *DuplicateGroup
+	var footerDuplicates *DuplicateGroup
+	for i := range duplicates {
+		if duplicates[i].BlockName == "content" {
+			contentDuplicates = &duplicates[i]
+		}
+		if duplicates[i].BlockName == "footer" {
+			footerDuplicates = &duplicates[i]
+		}
+	}
+
+	if contentDuplicates == nil {
+		t.Error("Expected to find content block duplicates in file1")
+	} else {
+		if contentDuplicates.Count != 2 {
+			t.Errorf("Expected 2 duplicate content blocks in file1, got %d", contentDuplicates.Count)
+		}
+		// Verify all blocks are from the same file
+		for _, block := range contentDuplicates.Files {
+			if block.File != "file1.twig" {
+				t.Errorf("Expected all duplicate blocks to be from file1.twig, got %s", block.File)
+			}
+		}
+	}
+
+	if footerDuplicates == nil {
+		t.Error("Expected to find footer block duplicates in file3")
+	} else {
+		if footerDuplicates.Count != 2 {
+			t.Errorf("Expected 2 duplicate footer blocks in file3, got %d", footerDuplicates.Count)
+		}
+		// Verify all blocks are from the same file
+		for _, block := range footerDuplicates.Files {
+			if block.File != "file3.twig" {
+				t.Errorf("Expected all duplicate blocks to be from file3.twig, got %s", block.File)
+			}
+		}
+	}
+}
+
+func TestParseBlocksSpans(t *testing.T) {
+	content := `{% extends "base.html.twig" %}
+
+{% block content %}
+    {%- block inner -%}
+        Inner
+    {%- endblock -%}
+{% endblock %}
+
+{% block title page.title %}
+{% verbatim %}{% block fake %}{% endblock %}{% endverbatim %}
+`
+
+	blocks := ParseBlocks("test.html.twig", content)
+
+	if len(blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d: %v", len(blocks), getBlockNames(blocks))
+	}
+
+	expected := []struct {
+		name    string
+		line    int
+		endLine int
+		depth   int
+	}{
+		{"content", 3, 7, 0},
+		{"inner", 4, 6, 1},
+		{"title", 9, 9, 0},
+	}
+
+	for i, exp := range expected {
+		block := blocks[i]
+		if block.Name != exp.name || block.Line != exp.line || block.EndLine != exp.endLine || block.Depth != exp.depth {
+			t.Errorf("Block %d: expected %s lines %d-%d depth %d, got %s lines %d-%d depth %d",
+				i, exp.name, exp.line, exp.endLine, exp.depth, block.Name, block.Line, block.EndLine, block.Depth)
+		}
+	}
+
+	if source := content[blocks[0].Start:blocks[0].End]; !strings.HasPrefix(source, "{% block content %}") || !strings.HasSuffix(source, "{% endblock %}") {
+		t.Errorf("Unexpected span for content block: %q", source)
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	// Test that identical content produces same hash
+	content1 := "{% block content %}"
+	content2 := "{% block content %}"
+	content3 := "{% block content with class %}"
+
+	hash1 := ContentHash(content1)
+	hash2 := ContentHash(content2)
+	hash3 := ContentHash(content3)
+
+	if hash1 != hash2 {
+		t.Error("Identical content should produce same hash")
+	}
+
+	if hash1 == hash3 {
+		t.Error("Different content should produce different hash")
+	}
+}
+
+func TestExtractBlocksWithRealFiles(t *testing.T) {
+	// Create a temporary project structure with real Twig files
+	tempDir := t.TempDir()
+
+	// Create test files with duplicate blocks within same file
+	files := map[string]string{
+		"templates/base.html.twig": `
+{% block title %}Default Title{% endblock %}
+{% block content %}{% endblock %}
+`,
+		"templates/page.html.twig": `
+{% extends "base.html.twig" %}
+{% block title %}Page Title{% endblock %}
+{% block content %}Page content{% endblock %}
+`,
+		"templates/duplicate.html.twig": `
+{% block title %}Default Title{% endblock %}
+{% block title %}Duplicate Title{% endblock %}
+{% block sidebar %}Sidebar{% endblock %}
+`,
+	}
+
+	for filePath, content := range files {
+		fullPath := filepath.Join(tempDir, filePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Test the full workflow
+	twigFiles, err := FindFiles(context.Background(), tempDir)
+	if err != nil {
+		t.Fatalf("FindFiles failed: %v", err)
+	}
+
+	if len(twigFiles) != 3 {
+		t.Errorf("Expected 3 Twig files, got %d", len(twigFiles))
+	}
+
+	allBlocks, err := ExtractBlocks(context.Background(), twigFiles)
+	if err != nil {
+		t.Fatalf("ExtractBlocks failed: %v", err)
+	}
+
+	duplicates := FindDuplicates(allBlocks)
+
+	// Should find duplicates for 'title' block within duplicate.html.twig
+	if len(duplicates) != 1 {
+		t.Errorf("Expected 1 duplicate group, got %d", len(duplicates))
+	}
+
+	if len(duplicates) > 0 {
+		titleDuplicates := &duplicates[0]
+		if titleDuplicates.BlockName != "title" {
+			t.Errorf("Expected duplicate block name 'title', got '%s'", titleDuplicates.BlockName)
+		}
+		if titleDuplicates.Count != 2 {
+			t.Errorf("Expected 2 duplicate title blocks, got %d", titleDuplicates.Count)
+		}
+		// Verify all blocks are from the same file
+		for _, block := range titleDuplicates.Files {
+			if !strings.Contains(block.File, "duplicate.html.twig") {
+				t.Errorf("Expected all duplicate blocks to be from duplicate.html.twig, got %s", block.File)
+			}
+		}
+	}
+}
+
+// Helper function to get block names for debugging
+func getBlockNames(blocks []Block) []string {
+	var names []string
+	for _, block := range blocks {
+		names = append(names, block.Name)
+	}
+	return names
+}
@@ -0,0 +1,290 @@
+package twig
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+// Upgrade statuses of an overridden storefront block
+const (
+	UpgradeUnchanged = "unchanged"
+	UpgradeChanged   = "changed"
+	UpgradeRenamed   = "renamed"
+	UpgradeRemoved   = "removed"
+	UpgradeUnknown   = "unknown"
+)
+
+// renameSimilarityThreshold is the minimum similarity for a block to count as renamed
+const renameSimilarityThreshold = 0.6
+
+// storefrontNamespace is the Twig namespace prefix of Shopware storefront templates
+const storefrontNamespace = "@Storefront/"
+
+// BlockUpgrade describes how an overridden storefront block changed between two versions
+type BlockUpgrade struct {
+	Template     string
+	BlockName    string
+	Status       string
+	Severity     string
+	NewName      string
+	Similarity   float64
+	OverriddenIn []BlockLocation
+	Diff         string
+}
+
+// BlockLocation is a block in a project template, with the path relative to the scanned root
+type BlockLocation struct {
+	File string
+	Line int
+}
+
+// storefrontBlock is a block found in a storefront tree, with the template defining it
+type storefrontBlock struct {
+	Template string
+	Source   string
+}
+
+// StorefrontTree resolves and caches templates of a local storefront checkout
+type StorefrontTree struct {
+	root      string
+	templates map[string]*Template
+}
+
+// NewStorefrontTree creates a resolver for templates of a storefront directory
+func NewStorefrontTree(root string) *StorefrontTree {
+	return &StorefrontTree{root: root, templates: make(map[string]*Template)}
+}
+
+// CheckUpgrades compares every @Storefront block overridden by the templates
+// below root between the old and the new storefront tree
+func CheckUpgrades(root string, templates []Template, from, to *StorefrontTree) []BlockUpgrade {
+	overridden := make(map[string]*BlockUpgrade)
+
+	for _, tpl := range templates {
+		if !strings.HasPrefix(tpl.Parent, storefrontNamespace) {
+			continue
+		}
+		logical := strings.TrimPrefix(tpl.Parent, storefrontNamespace)
+
+		for _, block := range tpl.Blocks {
+			key := logical + "#" + block.Name
+			result, ok := overridden[key]
+			if !ok {
+				result = &BlockUpgrade{Template: tpl.Parent, BlockName: block.Name}
+				overridden[key] = result
+			}
+			location := BlockLocation{File: relativeSlashPath(root, tpl.File), Line: block.Line}
+			result.OverriddenIn = append(result.OverriddenIn, location)
+		}
+	}
+
+	var results []BlockUpgrade
+	for _, result := range overridden {
+		compareBlockVersions(result, from, to)
+		result.Severity = upgradeSeverity(result.Status)
+		results = append(results, *result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Template == results[j].Template {
+			return results[i].BlockName < results[j].BlockName
+		}
+		return results[i].Template < results[j].Template
+	})
+
+	return results
+}
+
+// compareBlockVersions fills in status and diff for a single overridden block
+func compareBlockVersions(result *BlockUpgrade, from, to *StorefrontTree) {
+	logical := strings.TrimPrefix(result.Template, storefrontNamespace)
+
+	oldBlock, ok := from.findBlock(logical, result.BlockName)
+	if !ok {
+		result.Status = UpgradeUnknown
+		return
+	}
+
+	if newBlock, ok := to.findBlock(logical, result.BlockName); ok {
+		if ContentHash(oldBlock.Source) == ContentHash(newBlock.Source) {
+			result.Status = UpgradeUnchanged
+			return
+		}
+		result.Status = UpgradeChanged
+		result.Diff = unidiff.Unified("a/"+oldBlock.Template, "b/"+newBlock.Template, withTrailingNewline(oldBlock.Source), withTrailingNewline(newBlock.Source), 3)
+		return
+	}
+
+	// Look for a block with a new name and a similar body in the new template chain
+	bestName, bestScore, bestBlock := "", 0.0, storefrontBlock{}
+	oldNames := from.blockNames(logical)
+	for name, candidate := range to.blocks(logical) {
+		if oldNames[name] {
+			continue
+		}
+		score := blockSimilarity(blockBody(oldBlock.Source), blockBody(candidate.Source))
+		if score > bestScore || (score == bestScore && name < bestName) {
+			bestName, bestScore, bestBlock = name, score, candidate
+		}
+	}
+
+	if bestScore >= renameSimilarityThreshold {
+		result.Status = UpgradeRenamed
+		result.NewName = bestName
+		result.Similarity = float64(int(bestScore*100)) / 100
+		result.Diff = unidiff.Unified("a/"+oldBlock.Template, "b/"+bestBlock.Template, withTrailingNewline(oldBlock.Source), withTrailingNewline(bestBlock.Source), 3)
+		return
+	}
+
+	result.Status = UpgradeRemoved
+}
+
+// upgradeSeverity maps an upgrade status to the severity of the finding
+func upgradeSeverity(status string) string {
+	switch status {
+	case UpgradeRemoved, UpgradeRenamed:
+		return report.SeverityError
+	case UpgradeChanged:
+		return report.SeverityWarning
+	default:
+		return report.SeverityInfo
+	}
+}
+
+// template loads a template by its logical path. The path is looked up
+// directly and below Resources/views and src/Resources/views.
+func (s *StorefrontTree) template(logical string) *Template {
+	if tpl, ok := s.templates[logical]; ok {
+		return tpl
+	}
+
+	var tpl *Template
+	for _, base := range []string{"", filepath.Join("Resources", "views"), filepath.Join("src", "Resources", "views")} {
+		file := filepath.Join(s.root, base, filepath.FromSlash(logical))
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		tpl = &Template{
+			File:    file,
+			Logical: logical,
+			Parent:  ParentTemplate(string(content)),
+			Blocks:  ParseBlocks(file, string(content)),
+			Content: string(content),
+		}
+		break
+	}
+
+	s.templates[logical] = tpl
+	return tpl
+}
+
+// chain returns the template and all storefront templates it extends
+func (s *StorefrontTree) chain(logical string) []*Template {
+	var chain []*Template
+	seen := make(map[string]bool)
+
+	for logical != "" && !seen[logical] {
+		seen[logical] = true
+		tpl := s.template(logical)
+		if tpl == nil {
+			break
+		}
+		chain = append(chain, tpl)
+
+		if !strings.HasPrefix(tpl.Parent, storefrontNamespace) {
+			break
+		}
+		logical = strings.TrimPrefix(tpl.Parent, storefrontNamespace)
+	}
+
+	return chain
+}
+
+// findBlock returns the closest definition of a block in the template's extends chain
+func (s *StorefrontTree) findBlock(logical, name string) (storefrontBlock, bool) {
+	for _, tpl := range s.chain(logical) {
+		for _, block := range tpl.Blocks {
+			if block.Name == name {
+				return storefrontBlock{
+					Template: tpl.Logical,
+					Source:   tpl.Content[block.Start:block.End],
+				}, true
+			}
+		}
+	}
+	return storefrontBlock{}, false
+}
+
+// blocks returns all blocks in the template's extends chain, closest definition first
+func (s *StorefrontTree) blocks(logical string) map[string]storefrontBlock {
+	blocks := make(map[string]storefrontBlock)
+	for _, tpl := range s.chain(logical) {
+		for _, block := range tpl.Blocks {
+			if _, ok := blocks[block.Name]; !ok {
+				blocks[block.Name] = storefrontBlock{Template: tpl.Logical, Source: tpl.Content[block.Start:block.End]}
+			}
+		}
+	}
+	return blocks
+}
+
+// blockNames returns the set of block names in the template's extends chain
+func (s *StorefrontTree) blockNames(logical string) map[string]bool {
+	names := make(map[string]bool)
+	for name := range s.blocks(logical) {
+		names[name] = true
+	}
+	return names
+}
+
+// blockBody strips the opening block tag and the closing endblock tag
+func blockBody(source string) string {
+	if start := strings.Index(source, "%}"); start >= 0 {
+		source = source[start+2:]
+	}
+	if end := strings.LastIndex(source, "{%"); end >= 0 {
+		source = source[:end]
+	}
+	return source
+}
+
+// blockSimilarity returns the share of matching non-blank lines between two block bodies
+func blockSimilarity(a, b string) float64 {
+	normalize := func(content string) []string {
+		var lines []string
+		for _, line := range strings.Split(content, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed != "" {
+				lines = append(lines, trimmed)
+			}
+		}
+		return lines
+	}
+
+	linesA, linesB := normalize(a), normalize(b)
+	if len(linesA)+len(linesB) == 0 {
+		return 1
+	}
+
+	matches := 0
+	for _, op := range unidiff.Lines(linesA, linesB) {
+		if op.Kind == ' ' {
+			matches++
+		}
+	}
+	return float64(2*matches) / float64(len(linesA)+len(linesB))
+}
+
+// withTrailingNewline terminates content with a newline for diff output
+func withTrailingNewline(content string) string {
+	if strings.HasSuffix(content, "\n") {
+		return content
+	}
+	return content + "\n"
+}
@@ -0,0 +1,142 @@
+package twig
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+)
+
+func TestCheckUpgrades(t *testing.T) {
+	root := t.TempDir()
+	oldDir := filepath.Join(root, "old")
+	newDir := filepath.Join(root, "new")
+	projectDir := filepath.Join(root, "project")
+
+	writeFiles(t, oldDir, map[string]string{
+		"Resources/views/storefront/base.html.twig": `{% block base_header %}
+    <header class="header-main">
+        {% block base_header_inner %}
+            <div class="container">
+                <div class="header-logo">Logo</div>
+                <div class="header-search">Search</div>
+                <div class="header-actions">Actions</div>
+            </div>
+        {% endblock %}
+    </header>
+{% endblock %}
+{% block base_footer %}<footer>Footer</footer>{% endblock %}
+{% block base_navigation %}<nav>Navigation</nav>{% endblock %}
+`,
+		"Resources/views/storefront/page/index.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+{% block page_content %}Content{% endblock %}
+`,
+	})
+
+	writeFiles(t, newDir, map[string]string{
+		"Resources/views/storefront/base.html.twig": `{% block base_header %}
+    <header class="header-main">
+        {% block base_header_container %}
+            <div class="container">
+                <div class="header-logo">Logo</div>
+                <div class="header-search">Search</div>
+                <div class="header-actions">Actions</div>
+            </div>
+        {% endblock %}
+    </header>
+{% endblock %}
+{% block base_footer %}<footer class="footer-main">Footer</footer>{% endblock %}
+`,
+		"Resources/views/storefront/page/index.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+{% block page_content %}Content{% endblock %}
+`,
+	})
+
+	writeFiles(t, projectDir, map[string]string{
+		"custom/plugins/PluginA/src/Resources/views/storefront/base.html.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+{% block base_header_inner %}{{ parent() }}{% endblock %}
+{% block base_footer %}Custom footer{% endblock %}
+{% block base_navigation %}Custom navigation{% endblock %}
+{% block my_own_block %}Not in storefront{% endblock %}
+`,
+		// base_footer is inherited from base.html.twig through the extends chain
+		"custom/plugins/PluginB/src/Resources/views/storefront/page/index.html.twig": `{% sw_extends '@Storefront/storefront/page/index.html.twig' %}
+{% block page_content %}Custom content{% endblock %}
+{% block base_footer %}Custom footer{% endblock %}
+`,
+	})
+
+	files, err := FindFiles(context.Background(), projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadTemplates(context.Background(), projectDir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := CheckUpgrades(projectDir, templates, NewStorefrontTree(oldDir), NewStorefrontTree(newDir))
+
+	statuses := make(map[string]BlockUpgrade)
+	for _, result := range results {
+		statuses[strings.TrimPrefix(result.Template, storefrontNamespace)+"#"+result.BlockName] = result
+	}
+
+	expected := map[string]string{
+		"storefront/base.html.twig#base_header_inner":  UpgradeRenamed,
+		"storefront/base.html.twig#base_footer":        UpgradeChanged,
+		"storefront/base.html.twig#base_navigation":    UpgradeRemoved,
+		"storefront/base.html.twig#my_own_block":       UpgradeUnknown,
+		"storefront/page/index.html.twig#page_content": UpgradeUnchanged,
+		"storefront/page/index.html.twig#base_footer":  UpgradeChanged,
+	}
+
+	if len(results) != len(expected) {
+		t.Errorf("Expected %d results, got %d: %+v", len(expected), len(results), results)
+	}
+
+	for key, status := range expected {
+		result, ok := statuses[key]
+		if !ok {
+			t.Errorf("Missing result for %s", key)
+			continue
+		}
+		if result.Status != status {
+			t.Errorf("%s: expected status %s, got %s", key, status, result.Status)
+		}
+	}
+
+	renamed := statuses["storefront/base.html.twig#base_header_inner"]
+	if renamed.NewName != "base_header_container" {
+		t.Errorf("Expected rename to base_header_container, got %q", renamed.NewName)
+	}
+
+	if removed := statuses["storefront/base.html.twig#base_navigation"]; removed.Severity != report.SeverityError {
+		t.Errorf("Expected severity error for removed block, got %s", removed.Severity)
+	}
+
+	changed := statuses["storefront/base.html.twig#base_footer"]
+	if changed.Severity != report.SeverityWarning {
+		t.Errorf("Expected severity warning for changed block, got %s", changed.Severity)
+	}
+	if !strings.Contains(changed.Diff, `+{% block base_footer %}<footer class="footer-main">Footer</footer>{% endblock %}`) {
+		t.Errorf("Expected upstream diff for base_footer, got:\n%s", changed.Diff)
+	}
+	if len(changed.OverriddenIn) != 1 || !strings.HasPrefix(changed.OverriddenIn[0].File, "custom/plugins/PluginA/") {
+		t.Errorf("Unexpected override locations: %v", changed.OverriddenIn)
+	}
+}
+
+func TestBlockSimilarity(t *testing.T) {
+	if score := blockSimilarity("a\nb\nc\nd", "a\nb\nc\nd"); score != 1 {
+		t.Errorf("Expected similarity 1 for identical bodies, got %f", score)
+	}
+	if score := blockSimilarity("a\nb\nc\nd", "a\nb\nx\ny"); score != 0.5 {
+		t.Errorf("Expected similarity 0.5, got %f", score)
+	}
+	if score := blockSimilarity("a", "b"); score != 0 {
+		t.Errorf("Expected similarity 0 for different bodies, got %f", score)
+	}
+}
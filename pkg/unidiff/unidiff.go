@@ -0,0 +1,372 @@
+// Package unidiff computes, renders and applies unified diffs of text files.
+package unidiff
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Op is a single line-level edit operation
+type Op struct {
+	Kind byte // ' ' equal, '-' delete, '+' insert
+	Line string
+}
+
+// SplitLines splits content into lines, keeping the line terminators so that
+// a missing trailing newline survives a round trip through the diff
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines computes a minimal line-level edit script between a and b using
+// the Myers algorithm
+func Lines(a, b []string) []Op {
+	// Strip common prefix and suffix to keep the search space small
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []Op
+	for _, line := range a[:prefix] {
+		ops = append(ops, Op{Kind: ' ', Line: line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, Op{Kind: ' ', Line: line})
+	}
+
+	return ops
+}
+
+// myersDiff runs the Myers shortest edit script search and backtracks the result
+func myersDiff(a, b []string) []Op {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackMyers(trace, a, b, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrackMyers walks the recorded search frontier back to the origin
+func backtrackMyers(trace [][]int, a, b []string, offset int) []Op {
+	x, y := len(a), len(b)
+	var reversed []Op
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Op{Kind: ' ', Line: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				reversed = append(reversed, Op{Kind: '+', Line: b[y]})
+			} else {
+				x--
+				reversed = append(reversed, Op{Kind: '-', Line: a[x]})
+			}
+		}
+	}
+
+	ops := make([]Op, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
+
+// Unified renders a unified diff between two contents with the given
+// number of context lines. It returns an empty string when both are equal.
+func Unified(fromName, toName, from, to string, context int) string {
+	if from == to {
+		return ""
+	}
+
+	ops := Lines(SplitLines(from), SplitLines(to))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n", fromName)
+	fmt.Fprintf(&sb, "+++ %s\n", toName)
+
+	// Group changes into hunks separated by more than 2*context equal lines
+	i := 0
+	for i < len(ops) {
+		for i < len(ops) && ops[i].Kind == ' ' {
+			i++
+		}
+		if i >= len(ops) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].Kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].Kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		writeHunk(&sb, ops, start, end)
+		i = end
+	}
+
+	return sb.String()
+}
+
+// Stat counts the changed lines of a file like git diff --stat
+type Stat struct {
+	File       string
+	Insertions int
+	Deletions  int
+}
+
+// maxStatBar is the width at which the +/- bars of WriteStat are scaled down
+const maxStatBar = 40
+
+// NewStat counts the lines inserted and deleted between two contents
+func NewStat(file, from, to string) Stat {
+	stat := Stat{File: file}
+	for _, op := range Lines(SplitLines(from), SplitLines(to)) {
+		switch op.Kind {
+		case '+':
+			stat.Insertions++
+		case '-':
+			stat.Deletions++
+		}
+	}
+	return stat
+}
+
+// WriteStat writes stats in the format of git diff --stat
+func WriteStat(w io.Writer, stats []Stat) {
+	width, countWidth, largest := 0, 0, 0
+	insertions, deletions := 0, 0
+	for _, stat := range stats {
+		changes := stat.Insertions + stat.Deletions
+		width = max(width, len(stat.File))
+		countWidth = max(countWidth, len(strconv.Itoa(changes)))
+		largest = max(largest, changes)
+		insertions += stat.Insertions
+		deletions += stat.Deletions
+	}
+
+	for _, stat := range stats {
+		changes := stat.Insertions + stat.Deletions
+		plus, minus := stat.Insertions, stat.Deletions
+		if largest > maxStatBar && changes > 0 {
+			bar := max(changes*maxStatBar/largest, 1)
+			plus = (stat.Insertions*bar + changes/2) / changes
+			minus = bar - plus
+		}
+		fmt.Fprintf(w, " %-*s | %*d %s%s\n", width, stat.File, countWidth, changes, strings.Repeat("+", plus), strings.Repeat("-", minus))
+	}
+
+	summary := fmt.Sprintf(" %d %s changed", len(stats), plural(len(stats), "file", "files"))
+	if insertions > 0 || deletions == 0 {
+		summary += fmt.Sprintf(", %d %s(+)", insertions, plural(insertions, "insertion", "insertions"))
+	}
+	if deletions > 0 {
+		summary += fmt.Sprintf(", %d %s(-)", deletions, plural(deletions, "deletion", "deletions"))
+	}
+	fmt.Fprintln(w, summary)
+}
+
+// plural returns one when n is 1 and other otherwise
+func plural(n int, one, other string) string {
+	if n == 1 {
+		return one
+	}
+	return other
+}
+
+// writeHunk writes a single hunk covering ops[start:end]
+func writeHunk(sb *strings.Builder, ops []Op, start, end int) {
+	fromLine, toLine := 1, 1
+	for _, op := range ops[:start] {
+		if op.Kind != '+' {
+			fromLine++
+		}
+		if op.Kind != '-' {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+	for _, op := range ops[start:end] {
+		if op.Kind != '+' {
+			fromCount++
+		}
+		if op.Kind != '-' {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount))
+	for _, op := range ops[start:end] {
+		sb.WriteByte(op.Kind)
+		sb.WriteString(op.Line)
+		if !strings.HasSuffix(op.Line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk header range the way GNU diff does
+func hunkRange(line, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// Apply applies a unified diff of a single file to content. Context
+// and removed lines must match exactly.
+func Apply(content, diff string) (string, error) {
+	lines := SplitLines(content)
+	var result []string
+	next := 0 // Index of the next line of content to copy
+
+	var hunk []Op
+	for _, line := range SplitLines(diff) {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			continue
+		case strings.HasPrefix(line, "@@ "):
+			var fromLine, fromCount int
+			header := strings.Fields(line)
+			if len(header) < 3 {
+				return "", fmt.Errorf("invalid hunk header: %s", strings.TrimSpace(line))
+			}
+			from := strings.TrimPrefix(header[1], "-")
+			if _, err := fmt.Sscanf(from, "%d,%d", &fromLine, &fromCount); err != nil {
+				if _, err := fmt.Sscanf(from, "%d", &fromLine); err != nil {
+					return "", fmt.Errorf("invalid hunk header: %s", strings.TrimSpace(line))
+				}
+			}
+			start := fromLine - 1
+			if fromCount == 0 && strings.Contains(from, ",") {
+				// Pure insertion after line fromLine
+				start = fromLine
+			}
+			var err error
+			if result, next, err = applyHunk(lines, result, next, hunk); err != nil {
+				return "", err
+			}
+			hunk = nil
+			if start < next || start > len(lines) {
+				return "", fmt.Errorf("hunk at line %d out of order", fromLine)
+			}
+			result = append(result, lines[next:start]...)
+			next = start
+		case strings.HasPrefix(line, "\\"):
+			// No newline at end of file, applies to the previous line
+			if len(hunk) > 0 {
+				hunk[len(hunk)-1].Line = strings.TrimSuffix(hunk[len(hunk)-1].Line, "\n")
+			}
+		case line != "" && (line[0] == ' ' || line[0] == '-' || line[0] == '+'):
+			hunk = append(hunk, Op{Kind: line[0], Line: line[1:]})
+		default:
+			return "", fmt.Errorf("invalid diff line: %s", strings.TrimSpace(line))
+		}
+	}
+
+	result, next, err := applyHunk(lines, result, next, hunk)
+	if err != nil {
+		return "", err
+	}
+	result = append(result, lines[next:]...)
+	return strings.Join(result, ""), nil
+}
+
+// applyHunk applies the operations of a hunk at lines[next:] and returns the
+// extended result and the index of the next line
+func applyHunk(lines, result []string, next int, hunk []Op) ([]string, int, error) {
+	for _, op := range hunk {
+		if op.Kind == '+' {
+			result = append(result, op.Line)
+			continue
+		}
+		if next >= len(lines) || lines[next] != op.Line {
+			return nil, 0, fmt.Errorf("patch does not apply at line %d", next+1)
+		}
+		if op.Kind == ' ' {
+			result = append(result, op.Line)
+		}
+		next++
+	}
+	return result, next, nil
+}
@@ -0,0 +1,147 @@
+package unidiff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedNoChanges(t *testing.T) {
+	if diff := Unified("a/file", "b/file", "same\n", "same\n", 3); diff != "" {
+		t.Errorf("Expected empty diff for identical content, got:\n%s", diff)
+	}
+}
+
+func TestUnifiedSingleHunk(t *testing.T) {
+	from := "one\ntwo\nthree\nfour\n"
+	to := "one\n2\nthree\nfour\n"
+
+	expected := `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+ one
+-two
++2
+ three
+ four
+`
+
+	if diff := Unified("a/file", "b/file", from, to, 3); diff != expected {
+		t.Errorf("Unexpected diff:\n%s\nExpected:\n%s", diff, expected)
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	var fromLines, toLines []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", i+1)
+		fromLines = append(fromLines, line)
+		switch i {
+		case 2:
+			toLines = append(toLines, "changed-start")
+		case 17:
+			toLines = append(toLines, "changed-end")
+		default:
+			toLines = append(toLines, line)
+		}
+	}
+
+	diff := Unified("a/f", "b/f", strings.Join(fromLines, "\n")+"\n", strings.Join(toLines, "\n")+"\n", 3)
+
+	if strings.Count(diff, "@@ -") != 2 {
+		t.Errorf("Expected 2 hunks, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "@@ -1,6 +1,6 @@") {
+		t.Errorf("Expected first hunk header '@@ -1,6 +1,6 @@', got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "@@ -15,6 +15,6 @@") {
+		t.Errorf("Expected second hunk header '@@ -15,6 +15,6 @@', got:\n%s", diff)
+	}
+}
+
+func TestUnifiedMissingTrailingNewline(t *testing.T) {
+	diff := Unified("a/f", "b/f", "old", "new", 3)
+
+	if !strings.Contains(diff, "-old\n\\ No newline at end of file\n") {
+		t.Errorf("Expected no-newline marker for removed line, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "+new\n\\ No newline at end of file\n") {
+		t.Errorf("Expected no-newline marker for added line, got:\n%s", diff)
+	}
+}
+
+func TestUnifiedInsertOnly(t *testing.T) {
+	diff := Unified("a/f", "b/f", "", "added\n", 3)
+
+	if !strings.Contains(diff, "@@ -0,0 +1 @@") {
+		t.Errorf("Expected hunk header for insertion into empty file, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "+added\n") {
+		t.Errorf("Expected added line in diff, got:\n%s", diff)
+	}
+}
+
+func TestApply(t *testing.T) {
+	var long []string
+	for i := 0; i < 20; i++ {
+		long = append(long, strings.Repeat("x", i+1)+"\n")
+	}
+	longChanged := append([]string{}, long...)
+	longChanged[2], longChanged[17] = "start\n", "end\n"
+
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{"single hunk", "one\ntwo\nthree\n", "one\n2\nthree\n"},
+		{"separate hunks", strings.Join(long, ""), strings.Join(longChanged, "")},
+		{"missing trailing newline", "one\ntwo", "one\n2"},
+		{"add trailing newline", "one\ntwo", "one\ntwo\n"},
+		{"insert into empty", "", "one\n"},
+		{"delete all", "one\ntwo\n", ""},
+		{"insert at start", "one\n", "zero\none\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, pair := range [][2]string{{tt.from, tt.to}, {tt.to, tt.from}} {
+				diff := Unified("a/file", "b/file", pair[0], pair[1], 3)
+				applied, err := Apply(pair[0], diff)
+				if err != nil {
+					t.Fatalf("applyUnifiedDiff failed: %v\n%s", err, diff)
+				}
+				if applied != pair[1] {
+					t.Errorf("Expected %q, got %q from:\n%s", pair[1], applied, diff)
+				}
+			}
+		})
+	}
+
+	diff := Unified("a/file", "b/file", "one\ntwo\n", "one\n2\n", 3)
+	if _, err := Apply("one\nchanged\n", diff); err == nil {
+		t.Error("Expected an error when the content does not match the patch")
+	}
+}
+
+func TestWriteStat(t *testing.T) {
+	stats := []Stat{
+		NewStat("templates/a.html.twig", "a\nb\nc\n", "a\nB\nc\nd\n"),
+		NewStat("b.scss", "x\n", ""),
+	}
+
+	var buf bytes.Buffer
+	WriteStat(&buf, stats)
+	expected := " templates/a.html.twig | 3 ++-\n" +
+		" b.scss                | 1 -\n" +
+		" 2 files changed, 2 insertions(+), 2 deletions(-)\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected stat:\n%s\nExpected:\n%s", buf.String(), expected)
+	}
+
+	// Large changes are scaled to the bar width
+	buf.Reset()
+	WriteStat(&buf, []Stat{{File: "big.twig", Insertions: 300, Deletions: 100}})
+	if expected := " big.twig | 400 " + strings.Repeat("+", 30) + strings.Repeat("-", 10) + "\n 1 file changed, 300 insertions(+), 100 deletions(-)\n"; buf.String() != expected {
+		t.Errorf("Unexpected scaled stat:\n%q\nExpected:\n%q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,237 @@
+// Package vendorpatch creates unified diff patches of modified vendor files with
+// paths relative to the vendor package, and verifies that patches still apply to
+// the installed packages.
+package vendorpatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Options describes the files a patch is created from
+type Options struct {
+	Source  string       // Original vendor file or directory
+	Patched string       // Modified file or directory
+	Output  string       // Patch file, or directory of patch files when Source is a directory
+	Logger  *slog.Logger // Progress messages, discarded when nil
+}
+
+// Create writes the patch of Source and Patched to Output. For directories one
+// patch is written per file of Source that has a patched version.
+func Create(ctx context.Context, opts Options) error {
+	log := opts.Logger
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+
+	// Check whether these are files or directories
+	sourceInfo, err := os.Stat(opts.Source)
+	if err != nil {
+		return err
+	}
+
+	patchedInfo, err := os.Stat(opts.Patched)
+	if err != nil {
+		return err
+	}
+
+	if sourceInfo.IsDir() && patchedInfo.IsDir() {
+		return createDirectory(ctx, opts.Source, opts.Patched, opts.Output, log)
+	} else if !sourceInfo.IsDir() && !patchedInfo.IsDir() {
+		return createFile(ctx, opts.Source, opts.Patched, opts.Output, log)
+	} else {
+		return fmt.Errorf("source and patched must both be either files or directories")
+	}
+}
+
+// createDirectory writes a patch for every file below sourcePath
+func createDirectory(ctx context.Context, sourcePath, patchedPath, outputPath string, log *slog.Logger) error {
+	// Iterate over all files in the source directory
+	return filepath.Walk(sourcePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		// Calculate relative paths
+		relPath, err := filepath.Rel(sourcePath, path)
+		if err != nil {
+			return err
+		}
+
+		patchedFile := filepath.Join(patchedPath, relPath)
+		outputFile := filepath.Join(outputPath, relPath)
+
+		// Check if the corresponding patched file exists
+		if _, err := os.Stat(patchedFile); os.IsNotExist(err) {
+			log.Warn(fmt.Sprintf("No patched version found for: %s", relPath))
+			return nil
+		}
+
+		// Create output directory for this file
+		if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+			return err
+		}
+
+		// Process the individual file
+		return createFile(ctx, path, patchedFile, outputFile, log)
+	})
+}
+
+// createFile writes the patch of a single file
+func createFile(ctx context.Context, sourcePath, patchedPath, outputPath string, log *slog.Logger) error {
+	log.Info(fmt.Sprintf("Processing: %s", filepath.Base(sourcePath)))
+
+	// Generate patch in unified diff format
+	patch, err := Diff(ctx, sourcePath, patchedPath)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(sourcePath)
+	if err != nil {
+		return fmt.Errorf("error accessing source file: %w", err)
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("source file is empty")
+	}
+	if patch == "" {
+		return fmt.Errorf("source and patched files do not have different content")
+	}
+
+	// Write patch to output file
+	outputFile, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+	defer outputFile.Close()
+
+	_, err = outputFile.WriteString(patch)
+	if err != nil {
+		return fmt.Errorf("error writing patch file: %w", err)
+	}
+
+	return nil
+}
+
+// Diff creates a unified diff patch between source and patched content with
+// paths relative to the vendor package of sourcePath. It returns an empty
+// string when both files are equal.
+func Diff(ctx context.Context, sourcePath, patchedPath string) (string, error) {
+	for _, path := range []string{sourcePath, patchedPath} {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("could not generate diff for %s and %s: %w", sourcePath, patchedPath, err)
+		}
+	}
+
+	// Use git diff with source as first argument (a/) and patched as second (b/)
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-index", "--unified=3", sourcePath, patchedPath)
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		// Exit code 1 means that the files differ
+		return "", fmt.Errorf("error running git diff: %w", err)
+	}
+
+	// Post-process the output to fix the vendor paths
+	return FixPaths(string(output), sourcePath), nil
+}
+
+// FixPaths post-processes git diff output to use the path of sourcePath below
+// its vendor package in the headers
+func FixPaths(diffOutput, sourcePath string) string {
+	lines := strings.Split(diffOutput, "\n")
+	vendorPath := FilePath(sourcePath)
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, "--- ") {
+			lines[i] = fmt.Sprintf("--- a/%s", vendorPath)
+		} else if strings.HasPrefix(line, "+++ ") {
+			lines[i] = fmt.Sprintf("+++ b/%s", vendorPath)
+		} else if strings.HasPrefix(line, "diff --git") {
+			lines[i] = fmt.Sprintf("diff --git a/%s b/%s", vendorPath, vendorPath)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FilePath extracts the path below the vendor package from a full file path for
+// use in patch headers, e.g. vendor/shopware/storefront/Resources/x.twig ->
+// Resources/x.twig
+func FilePath(fullPath string) string {
+	// Convert to forward slashes for consistent handling
+	normalizedPath := filepath.ToSlash(fullPath)
+	parts := strings.Split(normalizedPath, "/")
+
+	// Look for vendor directory and extract the path after vendor/provider/package
+	for i, part := range parts {
+		if part == "vendor" && i+3 < len(parts) {
+			// Skip vendor/provider/package and return the rest
+			return strings.Join(parts[i+3:], "/")
+		}
+	}
+
+	// Fallback: return the full normalized path
+	return normalizedPath
+}
+
+// PackagePath extracts only the provider/package part from vendor paths
+// e.g. vendor/shopware/core/Framework/Plugin/PluginManager.php -> shopware/core
+func PackagePath(sourcePath string) string {
+	// Convert to forward slashes for consistent handling
+	normalizedPath := filepath.ToSlash(sourcePath)
+
+	// Remove filename if it's a file path
+	if strings.Contains(filepath.Base(normalizedPath), ".") {
+		normalizedPath = filepath.Dir(normalizedPath)
+		normalizedPath = filepath.ToSlash(normalizedPath)
+	}
+
+	parts := strings.Split(normalizedPath, "/")
+
+	// Find vendor directory and extract provider/package part
+	for i, part := range parts {
+		if part == "vendor" && i+2 < len(parts) {
+			// Skip "vendor" and take only provider/package (first 2 parts)
+			// e.g. vendor/shopware/core/Framework/Plugin -> shopware/core
+			provider := parts[i+1]
+			pkg := parts[i+2]
+			return provider + "/" + pkg
+		}
+	}
+
+	// If no vendor found, return the full path for non-vendor files
+	return sourcePath
+}
+
+// OutputPath returns the patch file of sourcePath in outputDir, below the
+// provider/package directory of the file. A relative outputDir is resolved
+// against the current directory.
+func OutputPath(outputDir, sourcePath string) string {
+	if !filepath.IsAbs(outputDir) {
+		// Get current working directory
+		cwd, err := os.Getwd()
+		if err != nil {
+			cwd = "."
+		}
+		outputDir = filepath.Join(cwd, outputDir)
+	}
+
+	// Generate filename
+	filename := fmt.Sprintf("%s.patch", strings.TrimSuffix(filepath.Base(sourcePath), filepath.Ext(sourcePath)))
+
+	return filepath.Join(outputDir, PackagePath(sourcePath), filename)
+}
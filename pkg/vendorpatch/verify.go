@@ -0,0 +1,202 @@
+package vendorpatch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wimwenigerkind/wswcli/pkg/report"
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+// Section is the part of a patch that changes a single file
+type Section struct {
+	Path    string // Path of the file below the package directory
+	Line    int    // Line of the --- header in the patch
+	New     bool   // The patch creates the file
+	Deleted bool   // The patch deletes the file
+	Diff    string // Unified diff from the --- header on
+}
+
+// Verify checks the patches below patchDir against the packages in vendorDir.
+// Patches are expected in <provider>/<package>/ below patchDir with paths
+// relative to the package directory. It returns the patch files and the
+// problems found.
+func Verify(ctx context.Context, patchDir, vendorDir string) ([]string, []report.Finding, error) {
+	patches, err := FindPatches(patchDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding patches: %w", err)
+	}
+
+	var findings []report.Finding
+	for _, patch := range patches {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		patchFindings, err := verifyPatch(patchDir, patch, vendorDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		findings = append(findings, patchFindings...)
+	}
+	return patches, findings, nil
+}
+
+// FindPatches returns the .patch and .diff files below dir, sorted
+func FindPatches(dir string) ([]string, error) {
+	var patches []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(path); !entry.IsDir() && (ext == ".patch" || ext == ".diff") {
+			patches = append(patches, path)
+		}
+		return nil
+	})
+	sort.Strings(patches)
+	return patches, err
+}
+
+// verifyPatch checks every file section of the patch at path against the
+// package it belongs to and returns the problems found
+func verifyPatch(patchDir, path, vendorDir string) ([]report.Finding, error) {
+	file := filepath.ToSlash(path)
+	rel, err := filepath.Rel(patchDir, path)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 3 {
+		return []report.Finding{{
+			Rule:     "unknown-package",
+			Severity: report.SeverityError,
+			Message:  fmt.Sprintf("cannot tell the vendor package, expected the patch in <provider>/<package>/ below %s", patchDir),
+			File:     file,
+		}}, nil
+	}
+	packageDir := filepath.Join(vendorDir, parts[0], parts[1])
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading patch: %w", err)
+	}
+	sections := Split(string(data))
+	if len(sections) == 0 {
+		return []report.Finding{{Rule: "empty-patch", Severity: report.SeverityError, Message: "patch changes no files", File: file}}, nil
+	}
+
+	var findings []report.Finding
+	for _, section := range sections {
+		rule, severity, message := verifyPatchSection(packageDir, section)
+		if rule == "" {
+			continue
+		}
+		findings = append(findings, report.Finding{Rule: rule, Severity: severity, Message: message, File: file, Line: section.Line})
+	}
+	return findings, nil
+}
+
+// verifyPatchSection checks a single file of a patch. It returns an empty rule
+// when the patch applies.
+func verifyPatchSection(packageDir string, section Section) (rule, severity, message string) {
+	target := filepath.Join(packageDir, filepath.FromSlash(section.Path))
+	data, err := os.ReadFile(target)
+	switch {
+	case os.IsNotExist(err) && section.New:
+		return "", "", ""
+	case os.IsNotExist(err) && section.Deleted:
+		return "already-applied", report.SeverityWarning, fmt.Sprintf("%s is already deleted in %s", section.Path, packageDir)
+	case os.IsNotExist(err):
+		return "missing-file", report.SeverityError, fmt.Sprintf("%s does not exist in %s", section.Path, packageDir)
+	case err != nil:
+		return "missing-file", report.SeverityError, fmt.Sprintf("error reading %s: %v", target, err)
+	}
+
+	content := string(data)
+	if !section.New {
+		_, applyErr := unidiff.Apply(content, section.Diff)
+		if applyErr == nil {
+			return "", "", ""
+		}
+		err = applyErr
+	}
+	if _, reverseErr := unidiff.Apply(content, Reverse(section.Diff)); reverseErr == nil {
+		return "already-applied", report.SeverityWarning, fmt.Sprintf("%s is already patched", section.Path)
+	}
+	if section.New {
+		return "does-not-apply", report.SeverityError, fmt.Sprintf("%s already exists in %s", section.Path, packageDir)
+	}
+	return "does-not-apply", report.SeverityError, fmt.Sprintf("%s: %v", section.Path, err)
+}
+
+// Split splits a patch into the diffs of the files it changes, dropping the
+// git headers between them
+func Split(patch string) []Section {
+	lines := unidiff.SplitLines(patch)
+	var sections []Section
+	inSection := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			from, to := patchHeaderPath(line[4:]), patchHeaderPath(lines[i+1][4:])
+			section := Section{Path: to, Line: i + 1, New: from == "/dev/null", Deleted: to == "/dev/null", Diff: line + lines[i+1]}
+			if section.Deleted {
+				section.Path = from
+			}
+			sections = append(sections, section)
+			inSection = true
+			i++
+		case strings.HasPrefix(line, "diff "):
+			// Git headers of the next file
+			inSection = false
+		case inSection:
+			sections[len(sections)-1].Diff += line
+		}
+	}
+	return sections
+}
+
+// patchHeaderPath returns the path of a --- or +++ header without the a/ or b/
+// prefix and a trailing timestamp
+func patchHeaderPath(header string) string {
+	header = strings.TrimRight(header, "\r\n")
+	if index := strings.IndexByte(header, '\t'); index >= 0 {
+		header = header[:index]
+	}
+	if header == "/dev/null" {
+		return header
+	}
+	for _, prefix := range []string{"a/", "b/"} {
+		if strings.HasPrefix(header, prefix) {
+			return header[len(prefix):]
+		}
+	}
+	return header
+}
+
+// Reverse returns a diff that undoes diff, to detect patches that are
+// already applied
+func Reverse(diff string) string {
+	var sb strings.Builder
+	for _, line := range unidiff.SplitLines(diff) {
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			header := strings.Fields(line)
+			if len(header) >= 3 {
+				line = fmt.Sprintf("@@ -%s +%s @@\n", strings.TrimPrefix(header[2], "+"), strings.TrimPrefix(header[1], "-"))
+			}
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+		case strings.HasPrefix(line, "-"):
+			line = "+" + line[1:]
+		case strings.HasPrefix(line, "+"):
+			line = "-" + line[1:]
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
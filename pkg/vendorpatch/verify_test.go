@@ -0,0 +1,23 @@
+package vendorpatch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wimwenigerkind/wswcli/pkg/unidiff"
+)
+
+func TestReverse(t *testing.T) {
+	diff := "--- a/A.php\n+++ b/A.php\n@@ -1,3 +1,4 @@\n a\n-b\n+B\n+C\n c\n"
+	patched, err := unidiff.Apply("a\nb\nc\n", diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := unidiff.Apply(patched, Reverse(diff))
+	if err != nil || original != "a\nb\nc\n" {
+		t.Errorf("Expected the reversed patch to restore the original, got %q (%v)", original, err)
+	}
+	if sections := Split(diff + diff); len(sections) != 2 || sections[1].Line != 9 {
+		t.Errorf("Expected 2 sections, got %s", fmt.Sprint(sections))
+	}
+}